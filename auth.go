@@ -0,0 +1,103 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"time"
+
+	"strava-custom-goals/config"
+	"strava-custom-goals/internal/auth"
+	"strava-custom-goals/internal/client"
+)
+
+// runAuth implements the "auth" command: login, status and logout
+func runAuth(args []string, profile string) {
+	fs := flag.NewFlagSet("auth", flag.ExitOnError)
+	port := fs.Int("port", config.OAuthCallbackPort, "Local port for the OAuth callback listener")
+	noBrowser := fs.Bool("no-browser", false, "Print the authorization URL instead of opening a browser")
+	timeout := fs.Duration("timeout", 5*time.Minute, "How long to wait for the authorization callback")
+	fs.Usage = func() {
+		fmt.Fprintln(fs.Output(), "Usage: strava-custom-goals auth <login|status|logout> [flags]")
+		fs.PrintDefaults()
+	}
+
+	if len(args) == 0 {
+		fs.Usage()
+		os.Exit(2)
+	}
+	subcommand := args[0]
+	fs.Parse(args[1:])
+
+	cfg := config.LoadConfig(profile)
+	store := auth.NewFileTokenStore(cfg.TokenFile)
+
+	switch subcommand {
+	case "login":
+		authLogin(cfg, store, *port, *noBrowser, *timeout)
+	case "status":
+		authStatus(store)
+	case "logout":
+		if err := store.Clear(); err != nil {
+			log.Fatalf("❌ Failed to remove token: %v", err)
+		}
+		log.Printf("👋 Removed stored token %s", store.Path())
+	default:
+		fs.Usage()
+		os.Exit(2)
+	}
+}
+
+// authLogin runs the browser-based OAuth authorization-code flow
+func authLogin(cfg *config.Config, store *auth.FileTokenStore, port int, noBrowser bool, timeout time.Duration) {
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+
+	opts := auth.LoginOptions{ClientID: cfg.ClientID, Port: port}
+	opts.OpenBrowser = func(authorizeURL string) error {
+		if !noBrowser && auth.OpenBrowser(authorizeURL) == nil {
+			log.Println("🌐 Opened Strava in your browser, approve access to continue...")
+			return nil
+		}
+		fmt.Printf("\n🔗 Open this URL to authorize access:\n\n   %s\n\n", authorizeURL)
+		return nil
+	}
+
+	log.Printf("🔐 Waiting for Strava authorization on localhost:%d...", port)
+	code, err := auth.Login(ctx, opts)
+	if err != nil {
+		log.Fatalf("❌ Authorization failed: %v", err)
+	}
+
+	stravaClient := client.NewStravaClient(cfg.ClientID, cfg.ClientSecret, "")
+	stravaClient.SetTokenStore(store)
+	token, err := stravaClient.ExchangeAuthorizationCode(code)
+	if err != nil {
+		log.Fatalf("❌ Token exchange failed: %v", err)
+	}
+
+	log.Printf("✅ Logged in, token saved to %s (access token valid until %s)",
+		store.Path(), time.Unix(token.ExpiresAt, 0).Format("Jan 2 15:04"))
+}
+
+// authStatus reports the state of the stored token
+func authStatus(store *auth.FileTokenStore) {
+	token, err := store.Load()
+	if err != nil {
+		log.Fatalf("❌ Failed to read token: %v", err)
+	}
+	if token == nil {
+		fmt.Printf("🔒 Not logged in (no token at %s)\n", store.Path())
+		return
+	}
+
+	expiresAt := time.Unix(token.ExpiresAt, 0)
+	fmt.Printf("🔑 Token file: %s\n", store.Path())
+	if token.ValidAt(time.Now(), 0) {
+		fmt.Printf("   ✅ Access token valid until %s\n", expiresAt.Format("Jan 2 15:04"))
+	} else {
+		fmt.Printf("   🔄 Access token expired at %s, it will be refreshed on next use\n", expiresAt.Format("Jan 2 15:04"))
+	}
+}
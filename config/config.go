@@ -2,56 +2,166 @@
 package config
 
 import (
+	"errors"
 	"fmt"
 	"log"
+	"math"
 	"os"
+	"path/filepath"
 	"strconv"
+	"strings"
 	"time"
 
 	"github.com/joho/godotenv"
+
+	"strava-custom-goals/internal/goals"
 )
 
 // Config holds all configuration values
 type Config struct {
+	Profile                string
 	ClientID               string
 	ClientSecret           string
 	RefreshToken           string
+	TokenFile              string
+	DatabaseFile           string
 	WeeklyRunningGoalKm    float64
 	WeeklyWorkoutGoalHours float64
+	Location               *time.Location // athlete timezone; nil buckets by each activity's local start time
+	WeekStart              time.Weekday
+	HeartRate              goals.HeartRate // weighs training load; unset means estimated
+	Zones                  goals.ZoneSettings
+	GoalsFile              string
+	Goals                  []goals.Goal // from GoalsFile, or the two weekly goals when it does not exist
 }
 
 // API endpoints and configuration constants
 const (
+	DefaultGoalsFile    = "goals.yaml"
 	StravaTokenURL      = "https://www.strava.com/oauth/token"
+	StravaAuthorizeURL  = "https://www.strava.com/oauth/authorize"
+	StravaScopes        = "read,activity:read_all"
+	OAuthCallbackPath   = "/exchange_token"
+	OAuthCallbackPort   = 8089
 	StravaActivitiesURL = "https://www.strava.com/api/v3/athlete/activities"
+	StravaActivityURL   = "https://www.strava.com/api/v3/activities" // followed by /{id}
 	DefaultPerPage      = 30
+	MaxPerPage          = 200 // largest page size the activities endpoint accepts
 	RequestTimeout      = 30 * time.Second
+	MaxRetries          = 3               // retries for 429 and 5xx responses
+	RetryBaseDelay      = 1 * time.Second // first backoff ceiling, doubled per attempt
+	RetryMaxDelay       = 8 * time.Second
+	TokenExpiryMargin   = 5 * time.Minute     // refresh access tokens this long before they expire
+	SyncRecheckWindow   = 30 * 24 * time.Hour // trailing window re-fetched to catch edits and deletions
+	SyncRecheckInterval = 24 * time.Hour
+	DetailsPerSync      = 20 // activities whose laps, splits and best efforts are fetched per sync
+	StreamsPerSync      = 20 // activities whose heart rate, pace, power and GPS streams are fetched per sync
+	DashboardAddr       = "127.0.0.1:8080"
+	DashboardRefresh    = 5 * time.Minute  // how often the dashboard reloads its data from the store
+	DashboardMinSync    = 15 * time.Minute // shortest interval at which the dashboard may sync, one rate limit window
 )
 
-// LoadConfig loads configuration from environment variables and .env file
-func LoadConfig() *Config {
+// LoadConfig loads configuration for a profile from environment variables,
+// the .env file and, for named profiles, the profile's own profile.env.
+// Named profiles keep their token, database and goals to themselves, see
+// profileKeys.
+func LoadConfig(profile string) *Config {
 	// Load .env file if it exists (ignore errors for production deployments)
 	if err := godotenv.Load(); err != nil {
 		log.Println("No .env file found, using environment variables")
 	}
 
+	// Named profiles override shared settings with their own
+	var env environment
+	goalsFileDefault := DefaultGoalsFile
+	if profile != DefaultProfile {
+		if err := ValidateProfileName(profile); err != nil {
+			log.Fatal("❌ ", err)
+		}
+		if !ProfileExists(profile) {
+			log.Fatalf("❌ Profile %q does not exist, create it with: profiles create %s", profile, profile)
+		}
+		settings, err := godotenv.Read(filepath.Join(ProfileDir(profile), profileEnvFile))
+		if err != nil {
+			log.Fatalf("❌ Failed to read profile %q settings: %v", profile, err)
+		}
+		env.profile = settings
+		goalsFileDefault = filepath.Join(ProfileDir(profile), DefaultGoalsFile)
+	}
+
 	// Parse weekly goals with defaults
-	weeklyRunningGoal, _ := strconv.ParseFloat(getEnvOrDefault("WEEKLY_RUNNING_GOAL_KM", "10"), 64)
-	weeklyWorkoutGoal, _ := strconv.ParseFloat(getEnvOrDefault("WEEKLY_WORKOUT_GOAL_HOURS", "3"), 64)
+	weeklyRunningGoal, err := env.parseFloatEnv("WEEKLY_RUNNING_GOAL_KM", "10")
+	if err != nil {
+		log.Fatal("❌ Configuration validation failed: ", err)
+	}
+	weeklyWorkoutGoal, err := env.parseFloatEnv("WEEKLY_WORKOUT_GOAL_HOURS", "3")
+	if err != nil {
+		log.Fatal("❌ Configuration validation failed: ", err)
+	}
 
 	config := &Config{
-		ClientID:               getEnvOrDefault("STRAVA_CLIENT_ID", ""),
-		ClientSecret:           getEnvOrDefault("STRAVA_CLIENT_SECRET", ""),
-		RefreshToken:           getEnvOrDefault("STRAVA_REFRESH_TOKEN", ""),
+		Profile:                profile,
+		ClientID:               env.getEnvOrDefault("STRAVA_CLIENT_ID", ""),
+		ClientSecret:           env.getEnvOrDefault("STRAVA_CLIENT_SECRET", ""),
+		RefreshToken:           env.getEnvOrDefault("STRAVA_REFRESH_TOKEN", ""),
+		TokenFile:              env.getEnvOrDefault("STRAVA_TOKEN_FILE", filepath.Join(ProfileDir(profile), "token.json")),
+		DatabaseFile:           env.getEnvOrDefault("STRAVA_DB_FILE", filepath.Join(ProfileDir(profile), "activities.db")),
+		GoalsFile:              env.getEnvOrDefault("GOALS_FILE", goalsFileDefault),
 		WeeklyRunningGoalKm:    weeklyRunningGoal,
 		WeeklyWorkoutGoalHours: weeklyWorkoutGoal,
 	}
 
+	// Parse calendar settings
+	location, err := parseLocation(env.getEnvOrDefault("ATHLETE_TIMEZONE", ""))
+	if err != nil {
+		log.Fatal("❌ Configuration validation failed: ", err)
+	}
+	config.Location = location
+
+	config.WeekStart, err = parseWeekday(env.getEnvOrDefault("WEEK_START_DAY", "monday"))
+	if err != nil {
+		log.Fatal("❌ Configuration validation failed: ", err)
+	}
+
+	// Parse the heart rate profile used for training load
+	maxHeartRate, err := env.parseFloatEnv("MAX_HEARTRATE", "0")
+	if err != nil {
+		log.Fatal("❌ Configuration validation failed: ", err)
+	}
+	restingHeartRate, err := env.parseFloatEnv("RESTING_HEARTRATE", "0")
+	if err != nil {
+		log.Fatal("❌ Configuration validation failed: ", err)
+	}
+	config.HeartRate = goals.HeartRate{Max: maxHeartRate, Resting: restingHeartRate}
+
+	// Parse the zones used by zone goals, derived from MAX_HEARTRATE and FTP unless set
+	config.Zones.HeartRate, err = env.parseZonesEnv("HEARTRATE_ZONES", goals.HeartRateZonesFromMax(maxHeartRate))
+	if err != nil {
+		log.Fatal("❌ Configuration validation failed: ", err)
+	}
+	ftp, err := env.parseFloatEnv("FTP", "0")
+	if err != nil {
+		log.Fatal("❌ Configuration validation failed: ", err)
+	}
+	config.Zones.Power, err = env.parseZonesEnv("POWER_ZONES", goals.PowerZonesFromFTP(int(ftp)))
+	if err != nil {
+		log.Fatal("❌ Configuration validation failed: ", err)
+	}
+
 	// Validate required configuration
 	if err := validateConfig(config); err != nil {
 		log.Fatal("❌ Configuration validation failed: ", err)
 	}
 
+	// Load the goal set
+	config.Goals, err = loadGoals(config, env.lookup("GOALS_FILE") != "")
+	if err != nil {
+		log.Fatal("❌ Goals configuration is invalid:\n", err)
+	}
+	if err := validateGoalZones(config); err != nil {
+		log.Fatal("❌ Goals configuration is invalid: ", err)
+	}
+
 	return config
 }
 
@@ -63,21 +173,133 @@ func validateConfig(cfg *Config) error {
 	if cfg.ClientSecret == "" {
 		return fmt.Errorf("STRAVA_CLIENT_SECRET is required")
 	}
-	if cfg.RefreshToken == "" {
-		return fmt.Errorf("STRAVA_REFRESH_TOKEN is required")
-	}
 	if cfg.WeeklyRunningGoalKm < 0 {
 		return fmt.Errorf("WEEKLY_RUNNING_GOAL_KM must be non-negative")
 	}
 	if cfg.WeeklyWorkoutGoalHours < 0 {
 		return fmt.Errorf("WEEKLY_WORKOUT_GOAL_HOURS must be non-negative")
 	}
+	if hr := cfg.HeartRate; (hr.Max != 0 || hr.Resting != 0) && !hr.Configured() {
+		return fmt.Errorf("MAX_HEARTRATE and RESTING_HEARTRATE must both be set, with the maximum above the resting rate")
+	}
+	return nil
+}
+
+// loadGoals reads the goals file, falling back to the weekly running and
+// workout goals when no file exists at the default path. A missing file that
+// was set explicitly is an error.
+func loadGoals(cfg *Config, explicit bool) ([]goals.Goal, error) {
+	if _, err := os.Stat(cfg.GoalsFile); errors.Is(err, os.ErrNotExist) && !explicit {
+		weekly := goals.WeeklyGoals{
+			RunningGoalKm:    cfg.WeeklyRunningGoalKm,
+			WorkoutGoalHours: cfg.WeeklyWorkoutGoalHours,
+		}
+		return weekly.Goals(), nil
+	}
+	return goals.LoadFile(cfg.GoalsFile)
+}
+
+// validateGoalZones checks that the zones every zone goal measures are configured
+func validateGoalZones(cfg *Config) error {
+	for _, goal := range cfg.Goals {
+		if !goal.Metric.Zoned() || cfg.Zones.Of(goal.Zones.Kind).Count() > 0 {
+			continue
+		}
+		if goal.Zones.Kind == goals.ZoneKindPower {
+			return fmt.Errorf("goal %q needs power zones: set FTP or POWER_ZONES", goal.Name)
+		}
+		return fmt.Errorf("goal %q needs heart rate zones: set MAX_HEARTRATE or HEARTRATE_ZONES", goal.Name)
+	}
 	return nil
 }
 
-// getEnvOrDefault gets an environment variable or returns a default value
-func getEnvOrDefault(key, defaultValue string) string {
-	if value := os.Getenv(key); value != "" {
+// parseZonesEnv parses comma-separated zone upper bounds such as
+// "120,140,160,175", returning fallback when the variable is unset
+func (e environment) parseZonesEnv(key string, fallback goals.Zones) (goals.Zones, error) {
+	raw := strings.TrimSpace(e.getEnvOrDefault(key, ""))
+	if raw == "" {
+		return fallback, nil
+	}
+
+	var zones goals.Zones
+	for _, field := range strings.Split(raw, ",") {
+		bound, err := strconv.Atoi(strings.TrimSpace(field))
+		if err != nil {
+			return nil, fmt.Errorf("%s must be comma-separated whole numbers, got %q", key, raw)
+		}
+		zones = append(zones, bound)
+	}
+	if err := zones.Validate(); err != nil {
+		return nil, fmt.Errorf("%s: %w", key, err)
+	}
+	return zones, nil
+}
+
+// parseFloatEnv parses a numeric environment variable, rejecting malformed values
+func (e environment) parseFloatEnv(key, defaultValue string) (float64, error) {
+	raw := e.getEnvOrDefault(key, defaultValue)
+	value, err := strconv.ParseFloat(strings.TrimSpace(raw), 64)
+	if err != nil || math.IsNaN(value) || math.IsInf(value, 0) {
+		return 0, fmt.Errorf("%s must be a number, got %q", key, raw)
+	}
+	return value, nil
+}
+
+// parseLocation loads an IANA timezone such as "Europe/Berlin"; empty means unset
+func parseLocation(name string) (*time.Location, error) {
+	if name == "" {
+		return nil, nil
+	}
+	location, err := time.LoadLocation(name)
+	if err != nil {
+		return nil, fmt.Errorf("ATHLETE_TIMEZONE %q is not a valid timezone: %w", name, err)
+	}
+	return location, nil
+}
+
+// parseWeekday parses a weekday name such as "monday" or "Sun"
+func parseWeekday(name string) (time.Weekday, error) {
+	name = strings.ToLower(strings.TrimSpace(name))
+	for day := time.Sunday; day <= time.Saturday; day++ {
+		full := strings.ToLower(day.String())
+		if name == full || name == full[:3] {
+			return day, nil
+		}
+	}
+	return 0, fmt.Errorf("WEEK_START_DAY %q is not a weekday", name)
+}
+
+// profileKeys are settings that belong to one athlete. Named profiles only
+// take them from their own profile.env, never from .env or the environment,
+// so profiles cannot share a token, database or goals by accident.
+var profileKeys = map[string]bool{
+	"STRAVA_REFRESH_TOKEN": true,
+	"STRAVA_TOKEN_FILE":    true,
+	"STRAVA_DB_FILE":       true,
+	"GOALS_FILE":           true,
+}
+
+// environment looks up settings in a named profile's profile.env first, then
+// in the environment, which includes .env. The default profile has no
+// profile.env.
+type environment struct {
+	profile map[string]string
+}
+
+// lookup returns a setting, or "" when unset
+func (e environment) lookup(key string) string {
+	if value := e.profile[key]; value != "" {
+		return value
+	}
+	if e.profile != nil && profileKeys[key] {
+		return ""
+	}
+	return os.Getenv(key)
+}
+
+// getEnvOrDefault gets a setting or returns a default value
+func (e environment) getEnvOrDefault(key, defaultValue string) string {
+	if value := e.lookup(key); value != "" {
 		return value
 	}
 	return defaultValue
@@ -0,0 +1,183 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"strava-custom-goals/internal/goals"
+)
+
+func TestParseFloatEnvRejectsInvalidNumbers(t *testing.T) {
+	var env environment
+	t.Setenv("WEEKLY_RUNNING_GOAL_KM", "ten")
+	if _, err := env.parseFloatEnv("WEEKLY_RUNNING_GOAL_KM", "10"); err == nil {
+		t.Error("Expected error for non-numeric value, got nil")
+	}
+
+	for _, bad := range []string{"NaN", "Inf", "-inf"} {
+		t.Setenv("WEEKLY_RUNNING_GOAL_KM", bad)
+		if _, err := env.parseFloatEnv("WEEKLY_RUNNING_GOAL_KM", "10"); err == nil {
+			t.Errorf("%q: expected error for a non-finite value, got nil", bad)
+		}
+	}
+
+	t.Setenv("WEEKLY_RUNNING_GOAL_KM", " 12.5 ")
+	value, err := env.parseFloatEnv("WEEKLY_RUNNING_GOAL_KM", "10")
+	if err != nil || value != 12.5 {
+		t.Errorf("Expected 12.5, got %v, %v", value, err)
+	}
+}
+
+func TestValidateConfigHeartRate(t *testing.T) {
+	cfg := &Config{ClientID: "id", ClientSecret: "secret"}
+	if err := validateConfig(cfg); err != nil {
+		t.Errorf("Expected no heart rate profile to be valid, got %v", err)
+	}
+
+	cfg.HeartRate = goals.HeartRate{Max: 188}
+	if err := validateConfig(cfg); err == nil {
+		t.Error("Expected an error when RESTING_HEARTRATE is missing")
+	}
+
+	cfg.HeartRate = goals.HeartRate{Max: 188, Resting: 52}
+	if err := validateConfig(cfg); err != nil {
+		t.Errorf("Expected a full heart rate profile to be valid, got %v", err)
+	}
+}
+
+func TestParseZonesEnv(t *testing.T) {
+	var env environment
+	t.Setenv("HEARTRATE_ZONES", "")
+	fallback := goals.HeartRateZonesFromMax(190)
+	if zones, err := env.parseZonesEnv("HEARTRATE_ZONES", fallback); err != nil || len(zones) != 4 || zones[0] != 114 {
+		t.Errorf("Expected zones derived from the maximum, got %v, %v", zones, err)
+	}
+
+	t.Setenv("HEARTRATE_ZONES", "125, 145,160,172")
+	if zones, err := env.parseZonesEnv("HEARTRATE_ZONES", fallback); err != nil || len(zones) != 4 || zones[1] != 145 {
+		t.Errorf("Expected the configured zones, got %v, %v", zones, err)
+	}
+
+	for _, bad := range []string{"125,abc", "145,125", "0,120"} {
+		t.Setenv("HEARTRATE_ZONES", bad)
+		if _, err := env.parseZonesEnv("HEARTRATE_ZONES", fallback); err == nil {
+			t.Errorf("%q: expected error, got nil", bad)
+		}
+	}
+}
+
+func TestValidateGoalZones(t *testing.T) {
+	zone2 := goals.Goal{Name: "Zone 2", Metric: goals.MetricZoneTime, Zones: goals.ZoneRange{Kind: goals.ZoneKindPower, From: 2, To: 2}}
+	cfg := &Config{Goals: []goals.Goal{zone2}, Zones: goals.ZoneSettings{HeartRate: goals.HeartRateZonesFromMax(190)}}
+	if err := validateGoalZones(cfg); err == nil || !strings.Contains(err.Error(), "FTP") {
+		t.Errorf("Expected an error naming FTP, got %v", err)
+	}
+
+	cfg.Zones.Power = goals.PowerZonesFromFTP(250)
+	if err := validateGoalZones(cfg); err != nil {
+		t.Errorf("Expected configured power zones to be valid, got %v", err)
+	}
+}
+
+func TestLoadGoalsFallsBackToWeeklyGoals(t *testing.T) {
+	cfg := &Config{
+		GoalsFile:              filepath.Join(t.TempDir(), "goals.yaml"),
+		WeeklyRunningGoalKm:    15,
+		WeeklyWorkoutGoalHours: 2,
+	}
+
+	goals, err := loadGoals(cfg, false)
+	if err != nil {
+		t.Fatalf("loadGoals returned error: %v", err)
+	}
+	if len(goals) != 2 || goals[0].Target != 15 || goals[1].Target != 2 {
+		t.Errorf("Expected the two weekly goals, got %+v", goals)
+	}
+
+	data := "goals:\n  - {name: Rides, metric: count, activity_types: [Ride], period: month, target: 8}\n"
+	if err := os.WriteFile(cfg.GoalsFile, []byte(data), 0644); err != nil {
+		t.Fatal(err)
+	}
+	goals, err = loadGoals(cfg, false)
+	if err != nil {
+		t.Fatalf("loadGoals returned error: %v", err)
+	}
+	if len(goals) != 1 || goals[0].Name != "Rides" {
+		t.Errorf("Expected goals from file, got %+v", goals)
+	}
+}
+
+func TestParseWeekday(t *testing.T) {
+	testCases := map[string]time.Weekday{"monday": time.Monday, "Sun": time.Sunday, " SATURDAY ": time.Saturday}
+	for input, want := range testCases {
+		if got, err := parseWeekday(input); err != nil || got != want {
+			t.Errorf("For %q, expected %v, got %v (%v)", input, want, got, err)
+		}
+	}
+	if _, err := parseWeekday("someday"); err == nil {
+		t.Error("Expected error for invalid weekday, got nil")
+	}
+}
+
+func TestLoadConfigKeepsNamedProfilesApart(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("STRAVA_GOALS_HOME", home)
+
+	// A shared .env with the default athlete's token, database and goals
+	dir := t.TempDir()
+	shared := "STRAVA_CLIENT_ID=shared-id\nSTRAVA_CLIENT_SECRET=shared-secret\n" +
+		"STRAVA_REFRESH_TOKEN=default-token\nSTRAVA_TOKEN_FILE=" + filepath.Join(dir, "token.json") + "\n" +
+		"STRAVA_DB_FILE=" + filepath.Join(dir, "activities.db") + "\nGOALS_FILE=" + filepath.Join(dir, "goals.yaml") + "\n"
+	if err := os.WriteFile(filepath.Join(dir, ".env"), []byte(shared), 0600); err != nil {
+		t.Fatal(err)
+	}
+	goalsFile := "goals:\n  - {name: Rides, metric: count, period: week, target: 3}\n"
+	if err := os.WriteFile(filepath.Join(dir, "goals.yaml"), []byte(goalsFile), 0600); err != nil {
+		t.Fatal(err)
+	}
+	for _, key := range []string{"STRAVA_CLIENT_ID", "STRAVA_CLIENT_SECRET", "STRAVA_REFRESH_TOKEN",
+		"STRAVA_TOKEN_FILE", "STRAVA_DB_FILE", "GOALS_FILE"} {
+		t.Setenv(key, "")
+		os.Unsetenv(key) // so .env is loaded; t.Setenv restores it afterwards
+	}
+	wd, _ := os.Getwd()
+	if err := os.Chdir(dir); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { os.Chdir(wd) })
+
+	for _, profile := range []string{"alice", "bob"} {
+		if _, err := CreateProfile(profile); err != nil {
+			t.Fatalf("CreateProfile returned error: %v", err)
+		}
+	}
+	// Bob brings his own token
+	envFile := filepath.Join(ProfileDir("bob"), profileEnvFile)
+	if err := os.WriteFile(envFile, []byte("STRAVA_REFRESH_TOKEN=bob-token\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	defaults := LoadConfig(DefaultProfile)
+	if defaults.RefreshToken != "default-token" || defaults.DatabaseFile != filepath.Join(dir, "activities.db") {
+		t.Errorf("Expected the default profile to use .env, got %+v", defaults)
+	}
+
+	alice, bob := LoadConfig("alice"), LoadConfig("bob")
+	for _, cfg := range []*Config{alice, bob} {
+		profileDir := ProfileDir(cfg.Profile)
+		if cfg.TokenFile != filepath.Join(profileDir, "token.json") ||
+			cfg.DatabaseFile != filepath.Join(profileDir, "activities.db") ||
+			cfg.GoalsFile != filepath.Join(profileDir, DefaultGoalsFile) {
+			t.Errorf("%s: expected token, database and goals in %s, got %+v", cfg.Profile, profileDir, cfg)
+		}
+		if cfg.ClientID != "shared-id" {
+			t.Errorf("%s: expected the shared client ID, got %q", cfg.Profile, cfg.ClientID)
+		}
+	}
+	if alice.RefreshToken != "" || bob.RefreshToken != "bob-token" {
+		t.Errorf("Expected only bob's own refresh token, got %q and %q", alice.RefreshToken, bob.RefreshToken)
+	}
+}
@@ -0,0 +1,137 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// DefaultProfile is the profile used when none is selected. It keeps the
+// original single-athlete layout: credentials in .env, data directly in the
+// data directory and goals.yaml in the working directory.
+const DefaultProfile = "default"
+
+// profileEnvFile holds a named profile's credentials and settings
+const profileEnvFile = "profile.env"
+
+var profileNamePattern = regexp.MustCompile(`^[a-z0-9][a-z0-9_-]*$`)
+
+// DataDir returns the application data directory, ~/.strava-goals unless
+// STRAVA_GOALS_HOME is set
+func DataDir() string {
+	if dir := os.Getenv("STRAVA_GOALS_HOME"); dir != "" {
+		return dir
+	}
+	homeDir, _ := os.UserHomeDir()
+	return filepath.Join(homeDir, ".strava-goals")
+}
+
+// ProfileDir returns the directory holding a profile's token, database and goals
+func ProfileDir(profile string) string {
+	if profile == DefaultProfile {
+		return DataDir()
+	}
+	return filepath.Join(DataDir(), "profiles", profile)
+}
+
+// ValidateProfileName checks that a profile name is safe to use as a directory name
+func ValidateProfileName(name string) error {
+	if !profileNamePattern.MatchString(name) {
+		return fmt.Errorf("invalid profile name %q: use lowercase letters, digits, '-' and '_'", name)
+	}
+	return nil
+}
+
+// ProfileExists reports whether a profile has been created
+func ProfileExists(profile string) bool {
+	if profile == DefaultProfile {
+		return true
+	}
+	_, err := os.Stat(filepath.Join(ProfileDir(profile), profileEnvFile))
+	return err == nil
+}
+
+// ListProfiles returns the default profile followed by all named profiles
+func ListProfiles() ([]string, error) {
+	profiles := []string{DefaultProfile}
+
+	entries, err := os.ReadDir(filepath.Join(DataDir(), "profiles"))
+	if errors.Is(err, os.ErrNotExist) {
+		return profiles, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("read profiles directory: %w", err)
+	}
+
+	var named []string
+	for _, entry := range entries {
+		if entry.IsDir() && ProfileExists(entry.Name()) {
+			named = append(named, entry.Name())
+		}
+	}
+	sort.Strings(named)
+	return append(profiles, named...), nil
+}
+
+// CurrentProfile returns the active profile: STRAVA_PROFILE if set, otherwise
+// the one selected with SetCurrentProfile, otherwise the default profile
+func CurrentProfile() string {
+	if profile := os.Getenv("STRAVA_PROFILE"); profile != "" {
+		return profile
+	}
+	data, err := os.ReadFile(filepath.Join(DataDir(), "current-profile"))
+	if err != nil {
+		return DefaultProfile
+	}
+	if profile := strings.TrimSpace(string(data)); profile != "" {
+		return profile
+	}
+	return DefaultProfile
+}
+
+// SetCurrentProfile makes profile the active one for future runs
+func SetCurrentProfile(profile string) error {
+	if !ProfileExists(profile) {
+		return fmt.Errorf("profile %q does not exist", profile)
+	}
+	if err := os.MkdirAll(DataDir(), 0700); err != nil {
+		return fmt.Errorf("create data directory: %w", err)
+	}
+	return os.WriteFile(filepath.Join(DataDir(), "current-profile"), []byte(profile+"\n"), 0600)
+}
+
+// CreateProfile creates a named profile with a template profile.env and
+// returns the path of that file
+func CreateProfile(profile string) (string, error) {
+	if err := ValidateProfileName(profile); err != nil {
+		return "", err
+	}
+	if profile == DefaultProfile || ProfileExists(profile) {
+		return "", fmt.Errorf("profile %q already exists", profile)
+	}
+
+	dir := ProfileDir(profile)
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		return "", fmt.Errorf("create profile directory: %w", err)
+	}
+
+	envFile := filepath.Join(dir, profileEnvFile)
+	template := fmt.Sprintf(`# Settings for the %q profile. Values here override .env and the environment.
+# The token, database and goals.yaml live in this directory; STRAVA_REFRESH_TOKEN,
+# STRAVA_TOKEN_FILE, STRAVA_DB_FILE and GOALS_FILE are only read from this file.
+STRAVA_CLIENT_ID=
+STRAVA_CLIENT_SECRET=
+
+# Goals are read from goals.yaml in this directory; these are the fallback weekly goals
+WEEKLY_RUNNING_GOAL_KM=10
+WEEKLY_WORKOUT_GOAL_HOURS=3
+`, profile)
+	if err := os.WriteFile(envFile, []byte(template), 0600); err != nil {
+		return "", fmt.Errorf("write profile settings: %w", err)
+	}
+	return envFile, nil
+}
@@ -0,0 +1,62 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestProfilesLifecycle(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("STRAVA_GOALS_HOME", home)
+	t.Setenv("STRAVA_PROFILE", "")
+
+	if profile := CurrentProfile(); profile != DefaultProfile {
+		t.Errorf("Expected default profile, got %q", profile)
+	}
+
+	envFile, err := CreateProfile("alice")
+	if err != nil {
+		t.Fatalf("CreateProfile returned error: %v", err)
+	}
+	if info, err := os.Stat(envFile); err != nil || info.Mode().Perm() != 0600 {
+		t.Errorf("Expected profile.env with 0600 permissions, got %v, %v", info, err)
+	}
+	if ProfileDir("alice") != filepath.Join(home, "profiles", "alice") {
+		t.Errorf("Unexpected profile directory %s", ProfileDir("alice"))
+	}
+
+	if _, err := CreateProfile("alice"); err == nil {
+		t.Error("Expected error creating an existing profile")
+	}
+	if _, err := CreateProfile("../escape"); err == nil {
+		t.Error("Expected error for unsafe profile name")
+	}
+	if _, err := CreateProfile("bob"); err != nil {
+		t.Fatalf("CreateProfile returned error: %v", err)
+	}
+
+	profiles, err := ListProfiles()
+	if err != nil {
+		t.Fatalf("ListProfiles returned error: %v", err)
+	}
+	if len(profiles) != 3 || profiles[0] != DefaultProfile || profiles[1] != "alice" || profiles[2] != "bob" {
+		t.Errorf("Expected [default alice bob], got %v", profiles)
+	}
+
+	if err := SetCurrentProfile("carol"); err == nil {
+		t.Error("Expected error switching to a missing profile")
+	}
+	if err := SetCurrentProfile("bob"); err != nil {
+		t.Fatalf("SetCurrentProfile returned error: %v", err)
+	}
+	if profile := CurrentProfile(); profile != "bob" {
+		t.Errorf("Expected bob to be current, got %q", profile)
+	}
+
+	// The environment overrides the stored selection
+	t.Setenv("STRAVA_PROFILE", "alice")
+	if profile := CurrentProfile(); profile != "alice" {
+		t.Errorf("Expected STRAVA_PROFILE to win, got %q", profile)
+	}
+}
@@ -0,0 +1,225 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"strings"
+	"time"
+
+	"strava-custom-goals/config"
+	"strava-custom-goals/internal/goals"
+	"strava-custom-goals/internal/models"
+	"strava-custom-goals/internal/report"
+	"strava-custom-goals/internal/store"
+)
+
+// runExport implements the "export" command: activities and per-period goal
+// results for a date range as CSV or Markdown tables
+func runExport(args []string, profile string) {
+	fs := flag.NewFlagSet("export", flag.ExitOnError)
+	format := fs.String("format", "markdown", "Output format: csv or markdown")
+	from := fs.String("from", "", "First day to export (YYYY-MM-DD)")
+	to := fs.String("to", "", "Last day to export, inclusive (YYYY-MM-DD, default today)")
+	month := fs.String("month", "", "Export a whole month (YYYY-MM) instead of --from/--to")
+	types := fs.String("types", "", "Comma-separated activity types to include, e.g. Run,Ride (default all)")
+	out := fs.String("out", "", "Write to this file instead of stdout")
+	fs.Usage = func() {
+		fmt.Fprintln(fs.Output(), "Usage: strava-custom-goals export [activities|goals|all] [flags]\n\n"+
+			"Exports stored activities; run sync first for the latest data. CSV holds a\n"+
+			"single table, so it defaults to activities; Markdown defaults to all.")
+		fs.PrintDefaults()
+	}
+
+	table := ""
+	if len(args) > 0 && !strings.HasPrefix(args[0], "-") {
+		table, args = args[0], args[1:]
+	}
+	fs.Parse(args)
+
+	if *format == "md" {
+		*format = "markdown"
+	}
+	if *format != "csv" && *format != "markdown" {
+		log.Fatalf("❌ Unknown --format %q (expected csv or markdown)", *format)
+	}
+	if table == "" {
+		table = "all"
+		if *format == "csv" {
+			table = "activities"
+		}
+	}
+	switch table {
+	case "activities", "goals":
+	case "all":
+		if *format == "csv" {
+			log.Fatalf("❌ CSV holds a single table: export activities or export goals")
+		}
+	default:
+		fs.Usage()
+		os.Exit(2)
+	}
+
+	cfg := config.LoadConfig(profile)
+	location := cfg.Location
+	if location == nil {
+		location = time.Local
+	}
+
+	start, end, err := exportRange(*from, *to, *month, time.Now().In(location))
+	if err != nil {
+		log.Fatalf("❌ Invalid date range: %v", err)
+	}
+
+	db := openStore(cfg)
+	defer db.Close()
+
+	var typeFilter []string
+	for _, t := range strings.Split(*types, ",") {
+		if t = strings.TrimSpace(t); t != "" {
+			typeFilter = append(typeFilter, t)
+		}
+	}
+
+	engine := goals.NewEngine(filterGoals(cfg.Goals, typeFilter))
+	engine.Calendar = goals.Calendar{Location: cfg.Location, WeekStart: cfg.WeekStart}
+	engine.HeartRate = cfg.HeartRate
+	engine.Zones = cfg.Zones
+	engine.Streams = db
+
+	var w io.Writer = os.Stdout
+	if *out != "" {
+		file, err := os.Create(*out)
+		if err != nil {
+			log.Fatalf("❌ Failed to create %s: %v", *out, err)
+		}
+		defer file.Close()
+		w = file
+	}
+
+	if *format == "markdown" {
+		fmt.Fprintf(w, "# Strava report: %s – %s\n\n", start.Format("Jan 2, 2006"), end.AddDate(0, 0, -1).Format("Jan 2, 2006"))
+	}
+
+	if table == "activities" || table == "all" {
+		activities, err := exportActivities(db, engine.Calendar, start, end, typeFilter)
+		if err != nil {
+			log.Fatalf("❌ Failed to read local activities: %v", err)
+		}
+		if err := writeTable(w, *format, "Activities", report.ActivityColumns, report.ActivityRows(activities)); err != nil {
+			log.Fatalf("❌ Failed to write activities: %v", err)
+		}
+		log.Printf("📤 Exported %d activities", len(activities))
+	}
+
+	if table == "goals" || table == "all" {
+		// Periods overlapping the range edges are evaluated in full
+		history, err := db.ActivitiesSince(engine.Since(start))
+		if err != nil {
+			log.Fatalf("❌ Failed to read local activities: %v", err)
+		}
+		results := engine.History(history, start, end, time.Now())
+		if err := writeTable(w, *format, "Goals", report.GoalColumns, report.GoalRows(results)); err != nil {
+			log.Fatalf("❌ Failed to write goal results: %v", err)
+		}
+		log.Printf("📤 Exported %d goal periods", len(results))
+	}
+}
+
+// exportRange resolves the export flags to a [start, end) range of whole days
+// in now's location. Without flags it covers the current month to date.
+func exportRange(from, to, month string, now time.Time) (time.Time, time.Time, error) {
+	location := now.Location()
+	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, location)
+
+	if month != "" {
+		if from != "" || to != "" {
+			return time.Time{}, time.Time{}, fmt.Errorf("--month cannot be combined with --from or --to")
+		}
+		start, err := time.ParseInLocation("2006-01", month, location)
+		if err != nil {
+			return time.Time{}, time.Time{}, fmt.Errorf("--month %q is not YYYY-MM", month)
+		}
+		return start, start.AddDate(0, 1, 0), nil
+	}
+
+	start := time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, location)
+	if from != "" {
+		day, err := time.ParseInLocation("2006-01-02", from, location)
+		if err != nil {
+			return time.Time{}, time.Time{}, fmt.Errorf("--from %q is not YYYY-MM-DD", from)
+		}
+		start = day
+	}
+
+	end := today.AddDate(0, 0, 1)
+	if to != "" {
+		day, err := time.ParseInLocation("2006-01-02", to, location)
+		if err != nil {
+			return time.Time{}, time.Time{}, fmt.Errorf("--to %q is not YYYY-MM-DD", to)
+		}
+		end = day.AddDate(0, 0, 1)
+	}
+
+	if !start.Before(end) {
+		return time.Time{}, time.Time{}, fmt.Errorf("range is empty: %s is after %s", start.Format("2006-01-02"), end.AddDate(0, 0, -1).Format("2006-01-02"))
+	}
+	return start, end, nil
+}
+
+// exportActivities loads the activities whose wall-clock start falls within
+// [start, end), oldest first
+func exportActivities(db *store.Store, calendar goals.Calendar, start, end time.Time, types []string) ([]models.Activity, error) {
+	// Widen the query by a day on each side; bucketing is by wall-clock time
+	stored, err := db.Activities(store.Query{After: start.AddDate(0, 0, -1), Before: end.AddDate(0, 0, 1), Types: types})
+	if err != nil {
+		return nil, err
+	}
+
+	rangeStart, rangeEnd := calendar.Now(start), calendar.Now(end)
+	var activities []models.Activity
+	for i := len(stored) - 1; i >= 0; i-- {
+		activityTime, err := calendar.ActivityTime(stored[i])
+		if err != nil || activityTime.Before(rangeStart) || !activityTime.Before(rangeEnd) {
+			continue
+		}
+		activities = append(activities, stored[i])
+	}
+	return activities, nil
+}
+
+// filterGoals keeps the goals that count at least one of the given types
+func filterGoals(all []goals.Goal, types []string) []goals.Goal {
+	if len(types) == 0 {
+		return all
+	}
+	var filtered []goals.Goal
+	for _, goal := range all {
+		for _, t := range types {
+			if goal.Matches(models.Activity{Type: t}) {
+				filtered = append(filtered, goal)
+				break
+			}
+		}
+	}
+	return filtered
+}
+
+// writeTable writes one table in the requested format
+func writeTable(w io.Writer, format, title string, header []string, rows [][]string) error {
+	if format == "csv" {
+		return report.WriteCSV(w, header, rows)
+	}
+	fmt.Fprintf(w, "## %s\n\n", title)
+	if len(rows) == 0 {
+		_, err := fmt.Fprintf(w, "_No %s in this range._\n\n", strings.ToLower(title))
+		return err
+	}
+	if err := report.WriteMarkdownTable(w, header, rows); err != nil {
+		return err
+	}
+	_, err := fmt.Fprintln(w)
+	return err
+}
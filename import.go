@@ -0,0 +1,126 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"strava-custom-goals/config"
+	"strava-custom-goals/internal/importer"
+	"strava-custom-goals/internal/models"
+	"strava-custom-goals/internal/store"
+)
+
+// runImport implements the "import" command: store activities recorded
+// outside Strava from GPX, TCX and FIT files, or a whole history from a
+// Strava bulk-export zip
+func runImport(args []string, profile string) {
+	fs := flag.NewFlagSet("import", flag.ExitOnError)
+	activityType := fs.String("type", "", "Activity type for every imported file, e.g. Run (default read from each file)")
+	dryRun := fs.Bool("dry-run", false, "Parse files and check for duplicates without storing anything")
+	fs.Usage = func() {
+		fmt.Fprintln(fs.Output(), "Usage: strava-custom-goals import [flags] FILE|DIR|EXPORT.zip...\n\n"+
+			"Imports .gpx, .tcx and .fit files; directories are searched recursively.\n"+
+			"Files matching a stored activity's start time and duration are skipped.\n"+
+			"A .zip from Strava's \"Download your data\" seeds the whole history.")
+		fs.PrintDefaults()
+	}
+	fs.Parse(args)
+	if fs.NArg() == 0 {
+		fs.Usage()
+		os.Exit(2)
+	}
+
+	var archives, paths []string
+	for _, arg := range fs.Args() {
+		if strings.EqualFold(filepath.Ext(arg), ".zip") {
+			archives = append(archives, arg)
+		} else {
+			paths = append(paths, arg)
+		}
+	}
+	var files []string
+	if len(paths) > 0 {
+		var err error
+		if files, err = importer.Files(paths); err != nil {
+			log.Fatalf("❌ %v", err)
+		}
+		if len(files) == 0 {
+			log.Fatalf("❌ No .gpx, .tcx or .fit files found")
+		}
+	}
+
+	cfg := config.LoadConfig(profile)
+	db := openStore(cfg)
+	defer db.Close()
+
+	imported := 0
+	for _, archive := range archives {
+		imported += importArchive(db, archive, cfg, *dryRun)
+	}
+	if len(files) > 0 {
+		imported += importFiles(db, files, cfg, *activityType, *dryRun)
+	}
+	if imported > 0 && !*dryRun {
+		updateRecords(db)
+	}
+}
+
+// importArchive imports a Strava bulk export and returns the number of
+// activities added
+func importArchive(db *store.Store, archive string, cfg *config.Config, dryRun bool) int {
+	log.Printf("📦 Reading Strava export %s...", archive)
+	result, err := importer.ImportArchive(db, archive, cfg.Location, dryRun)
+	if err != nil {
+		log.Fatalf("❌ Failed to import %s: %v", archive, err)
+	}
+	for _, err := range result.FileErrors {
+		log.Printf("⚠️ %v", err)
+	}
+
+	verb := "Imported"
+	if dryRun {
+		verb = "Would import"
+	}
+	log.Printf("📥 %s %d of %d activities (%d already stored), streams from %d files",
+		verb, result.Added, result.Activities, result.Existing, result.Streams)
+	if result.Replaced > 0 {
+		log.Printf("🔁 %d of them replace activities imported from files", result.Replaced)
+	}
+	if !result.Watermark.IsZero() {
+		log.Printf("🕒 Newest exported activity: %s; sync continues from there", result.Watermark.Local().Format("Jan 2, 2006 15:04"))
+	}
+	return result.Added
+}
+
+// importFiles imports recorded activity files and returns the number stored
+func importFiles(db *store.Store, files []string, cfg *config.Config, activityType string, dryRun bool) int {
+	im := &importer.Importer{Store: db, Location: cfg.Location, Type: activityType, DryRun: dryRun}
+	imported, duplicates, failed := 0, 0, 0
+	for _, file := range files {
+		activity, duplicate, err := im.ImportFile(file)
+		switch {
+		case err != nil:
+			log.Printf("⚠️ %v", err)
+			failed++
+		case duplicate != nil:
+			log.Printf("⏭️ %s: already stored as %q (%s)", file, duplicate.Name, duplicate.StartDateLocal)
+			duplicates++
+		default:
+			activity.EnhanceWithCalculatedFields()
+			log.Printf("✅ %s: %s %q, %.2f km in %s", file, activity.Type, activity.Name,
+				activity.DistanceKm, models.FormatDuration(activity.MovingTime))
+			imported++
+		}
+	}
+
+	verb := "Imported"
+	if dryRun {
+		verb = "Would import"
+	}
+	log.Printf("📥 %s %d of %d files (%d duplicates, %d failed)", verb, imported, len(files), duplicates, failed)
+	return imported
+}
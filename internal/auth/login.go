@@ -0,0 +1,149 @@
+package auth
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"os/exec"
+	"runtime"
+	"strings"
+
+	"strava-custom-goals/config"
+)
+
+// LoginOptions configures the authorization-code flow
+type LoginOptions struct {
+	ClientID string
+	Port     int // local callback port; 0 picks a free port
+
+	// OpenBrowser is called with the Strava authorize URL. Defaults to the
+	// platform's URL opener.
+	OpenBrowser func(authorizeURL string) error
+}
+
+// AuthorizeURL builds the Strava consent page URL for the given redirect and state
+func AuthorizeURL(clientID, redirectURI, state string) string {
+	params := url.Values{}
+	params.Set("client_id", clientID)
+	params.Set("redirect_uri", redirectURI)
+	params.Set("response_type", "code")
+	params.Set("approval_prompt", "auto")
+	params.Set("scope", config.StravaScopes)
+	params.Set("state", state)
+	return config.StravaAuthorizeURL + "?" + params.Encode()
+}
+
+// Login runs a localhost callback listener, sends the user to Strava's consent
+// page and returns the authorization code once Strava redirects back
+func Login(ctx context.Context, opts LoginOptions) (string, error) {
+	if opts.ClientID == "" {
+		return "", fmt.Errorf("client ID is required")
+	}
+	if opts.OpenBrowser == nil {
+		opts.OpenBrowser = OpenBrowser
+	}
+
+	listener, err := net.Listen("tcp", fmt.Sprintf("127.0.0.1:%d", opts.Port))
+	if err != nil {
+		return "", fmt.Errorf("start callback listener: %w", err)
+	}
+	port := listener.Addr().(*net.TCPAddr).Port
+	redirectURI := fmt.Sprintf("http://localhost:%d%s", port, config.OAuthCallbackPath)
+
+	state, err := randomState()
+	if err != nil {
+		listener.Close()
+		return "", err
+	}
+
+	type result struct {
+		code string
+		err  error
+	}
+	results := make(chan result, 1)
+
+	mux := http.NewServeMux()
+	mux.HandleFunc(config.OAuthCallbackPath, func(w http.ResponseWriter, r *http.Request) {
+		code, err := parseCallback(r.URL.Query(), state)
+		if err != nil {
+			http.Error(w, "❌ Authorization failed: "+err.Error(), http.StatusBadRequest)
+		} else {
+			fmt.Fprintln(w, "✅ Authorization complete. You can close this window and return to the terminal.")
+		}
+		select {
+		case results <- result{code: code, err: err}:
+		default: // a result was already delivered
+		}
+	})
+
+	server := &http.Server{Handler: mux}
+	go server.Serve(listener)
+	defer server.Close()
+
+	if err := opts.OpenBrowser(AuthorizeURL(opts.ClientID, redirectURI, state)); err != nil {
+		return "", fmt.Errorf("open browser: %w", err)
+	}
+
+	select {
+	case res := <-results:
+		return res.code, res.err
+	case <-ctx.Done():
+		return "", ctx.Err()
+	}
+}
+
+// parseCallback validates the redirect query and extracts the authorization code
+func parseCallback(query url.Values, expectedState string) (string, error) {
+	if errParam := query.Get("error"); errParam != "" {
+		return "", fmt.Errorf("strava returned %q", errParam)
+	}
+	if query.Get("state") != expectedState {
+		return "", errors.New("state mismatch, please retry the login")
+	}
+	if !hasScope(query.Get("scope"), "activity:read_all") {
+		return "", errors.New("the activity:read_all permission was not granted")
+	}
+	code := query.Get("code")
+	if code == "" {
+		return "", errors.New("missing authorization code")
+	}
+	return code, nil
+}
+
+// hasScope reports whether a comma-separated scope list contains scope
+func hasScope(scopes, scope string) bool {
+	for _, s := range strings.Split(scopes, ",") {
+		if strings.TrimSpace(s) == scope {
+			return true
+		}
+	}
+	return false
+}
+
+// randomState returns an unguessable value to protect the callback against CSRF
+func randomState() (string, error) {
+	buf := make([]byte, 16)
+	if _, err := rand.Read(buf); err != nil {
+		return "", fmt.Errorf("generate state: %w", err)
+	}
+	return hex.EncodeToString(buf), nil
+}
+
+// OpenBrowser opens a URL with the platform's default handler
+func OpenBrowser(target string) error {
+	var cmd *exec.Cmd
+	switch runtime.GOOS {
+	case "darwin":
+		cmd = exec.Command("open", target)
+	case "windows":
+		cmd = exec.Command("rundll32", "url.dll,FileProtocolHandler", target)
+	default:
+		cmd = exec.Command("xdg-open", target)
+	}
+	return cmd.Start()
+}
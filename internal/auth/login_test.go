@@ -0,0 +1,70 @@
+package auth
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+)
+
+// callbackBrowser simulates the user approving access by following the redirect
+func callbackBrowser(t *testing.T, extra url.Values) func(string) error {
+	return func(authorizeURL string) error {
+		parsed, err := url.Parse(authorizeURL)
+		if err != nil {
+			return err
+		}
+		q := parsed.Query()
+		if !strings.Contains(q.Get("scope"), "activity:read_all") {
+			t.Errorf("Expected activity:read_all scope, got %q", q.Get("scope"))
+		}
+
+		callback := url.Values{}
+		callback.Set("state", q.Get("state"))
+		callback.Set("code", "auth-code")
+		callback.Set("scope", "read,activity:read_all")
+		for key, values := range extra {
+			callback[key] = values
+		}
+
+		go func() {
+			resp, err := http.Get(q.Get("redirect_uri") + "?" + callback.Encode())
+			if err == nil {
+				resp.Body.Close()
+			}
+		}()
+		return nil
+	}
+}
+
+func TestLoginReturnsAuthorizationCode(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	code, err := Login(ctx, LoginOptions{ClientID: "123", OpenBrowser: callbackBrowser(t, nil)})
+	if err != nil {
+		t.Fatalf("Login returned error: %v", err)
+	}
+	if code != "auth-code" {
+		t.Errorf("Expected auth-code, got %s", code)
+	}
+}
+
+func TestLoginRejectsBadCallbacks(t *testing.T) {
+	testCases := map[string]url.Values{
+		"state mismatch": {"state": {"forged"}},
+		"denied":         {"error": {"access_denied"}},
+		"missing scope":  {"scope": {"read"}},
+	}
+
+	for name, extra := range testCases {
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		_, err := Login(ctx, LoginOptions{ClientID: "123", OpenBrowser: callbackBrowser(t, extra)})
+		cancel()
+		if err == nil {
+			t.Errorf("%s: expected error, got nil", name)
+		}
+	}
+}
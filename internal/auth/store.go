@@ -0,0 +1,88 @@
+// Package auth handles persistence of Strava OAuth tokens and the interactive
+// authorization flow used to obtain them.
+package auth
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"strava-custom-goals/internal/models"
+)
+
+// FileTokenStore keeps the latest OAuth token on disk, readable only by the owner
+type FileTokenStore struct {
+	path string
+}
+
+// NewFileTokenStore creates a token store backed by the file at path
+func NewFileTokenStore(path string) *FileTokenStore {
+	return &FileTokenStore{path: path}
+}
+
+// Path returns the location of the token file
+func (s *FileTokenStore) Path() string {
+	return s.path
+}
+
+// Load reads the stored token. It returns nil without error when nothing has been stored yet.
+func (s *FileTokenStore) Load() (*models.TokenResponse, error) {
+	data, err := os.ReadFile(s.path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("read token file: %w", err)
+	}
+
+	var token models.TokenResponse
+	if err := json.Unmarshal(data, &token); err != nil {
+		return nil, fmt.Errorf("decode token file %s: %w", s.path, err)
+	}
+	return &token, nil
+}
+
+// Save writes the token atomically with 0600 permissions
+func (s *FileTokenStore) Save(token *models.TokenResponse) error {
+	if err := os.MkdirAll(filepath.Dir(s.path), 0700); err != nil {
+		return fmt.Errorf("create token directory: %w", err)
+	}
+
+	data, err := json.MarshalIndent(token, "", "  ")
+	if err != nil {
+		return fmt.Errorf("marshal token: %w", err)
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(s.path), ".token-*.json")
+	if err != nil {
+		return fmt.Errorf("create temp token file: %w", err)
+	}
+	defer os.Remove(tmp.Name()) // no-op once renamed
+
+	if err := tmp.Chmod(0600); err != nil {
+		tmp.Close()
+		return fmt.Errorf("chmod token file: %w", err)
+	}
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return fmt.Errorf("write token file: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("close token file: %w", err)
+	}
+
+	if err := os.Rename(tmp.Name(), s.path); err != nil {
+		return fmt.Errorf("replace token file: %w", err)
+	}
+	return nil
+}
+
+// Clear removes the stored token
+func (s *FileTokenStore) Clear() error {
+	if err := os.Remove(s.path); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return fmt.Errorf("remove token file: %w", err)
+	}
+	return nil
+}
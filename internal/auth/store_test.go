@@ -0,0 +1,52 @@
+package auth
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"strava-custom-goals/internal/models"
+)
+
+func TestFileTokenStoreRoundTrip(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "nested", "token.json")
+	store := NewFileTokenStore(path)
+
+	token, err := store.Load()
+	if err != nil || token != nil {
+		t.Fatalf("Expected empty store, got %+v, %v", token, err)
+	}
+
+	saved := &models.TokenResponse{
+		AccessToken:  "access",
+		RefreshToken: "rotated",
+		ExpiresAt:    1760000000,
+		TokenType:    "Bearer",
+	}
+	if err := store.Save(saved); err != nil {
+		t.Fatalf("Save returned error: %v", err)
+	}
+
+	info, err := os.Stat(path)
+	if err != nil {
+		t.Fatalf("Stat token file: %v", err)
+	}
+	if perm := info.Mode().Perm(); perm != 0600 {
+		t.Errorf("Expected permissions 0600, got %o", perm)
+	}
+
+	loaded, err := store.Load()
+	if err != nil {
+		t.Fatalf("Load returned error: %v", err)
+	}
+	if *loaded != *saved {
+		t.Errorf("Expected %+v, got %+v", saved, loaded)
+	}
+
+	if err := store.Clear(); err != nil {
+		t.Fatalf("Clear returned error: %v", err)
+	}
+	if token, _ := store.Load(); token != nil {
+		t.Errorf("Expected token to be cleared, got %+v", token)
+	}
+}
@@ -0,0 +1,247 @@
+package client
+
+import (
+	"fmt"
+	"math/rand"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"strava-custom-goals/config"
+)
+
+// RateLimitUsage is the most recent quota snapshot reported by Strava.
+// Short is the 15-minute window, Long the daily window.
+type RateLimitUsage struct {
+	ShortLimit int
+	ShortUsage int
+	LongLimit  int
+	LongUsage  int
+	UpdatedAt  time.Time // zero until a response carrying rate limit headers is seen
+}
+
+// Known reports whether any rate limit headers have been observed yet
+func (u RateLimitUsage) Known() bool {
+	return !u.UpdatedAt.IsZero()
+}
+
+// String formats usage for CLI reporting
+func (u RateLimitUsage) String() string {
+	if !u.Known() {
+		return "unknown"
+	}
+	return fmt.Sprintf("15-min %d/%d, daily %d/%d", u.ShortUsage, u.ShortLimit, u.LongUsage, u.LongLimit)
+}
+
+// RateLimitError is returned when a request is refused, locally or by Strava,
+// because a quota window is exhausted
+type RateLimitError struct {
+	Window  string // "15-minute" or "daily"
+	ResetAt time.Time
+}
+
+func (e *RateLimitError) Error() string {
+	return fmt.Sprintf("strava %s rate limit exhausted, resets at %s", e.Window, e.ResetAt.Local().Format("15:04 MST"))
+}
+
+// RateLimitTransport is an http.RoundTripper that tracks Strava's quota headers,
+// refuses requests that would exceed a quota and retries 429/5xx responses with
+// jittered exponential backoff
+type RateLimitTransport struct {
+	Base       http.RoundTripper
+	MaxRetries int
+	BaseDelay  time.Duration
+	MaxDelay   time.Duration
+
+	mu    sync.Mutex
+	usage RateLimitUsage
+
+	// Hooks for tests
+	now   func() time.Time
+	sleep func(*http.Request, time.Duration) error
+}
+
+// NewRateLimitTransport wraps base (or http.DefaultTransport when nil) with
+// the configured retry policy
+func NewRateLimitTransport(base http.RoundTripper) *RateLimitTransport {
+	if base == nil {
+		base = http.DefaultTransport
+	}
+	return &RateLimitTransport{
+		Base:       base,
+		MaxRetries: config.MaxRetries,
+		BaseDelay:  config.RetryBaseDelay,
+		MaxDelay:   config.RetryMaxDelay,
+		now:        time.Now,
+		sleep:      sleepContext,
+	}
+}
+
+// Usage returns the latest quota snapshot
+func (t *RateLimitTransport) Usage() RateLimitUsage {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return t.usage
+}
+
+// RoundTrip implements http.RoundTripper
+func (t *RateLimitTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	for attempt := 0; ; attempt++ {
+		if err := t.checkQuota(); err != nil {
+			return nil, err
+		}
+
+		// RoundTrippers must not modify the caller's request, so retries
+		// send a clone with a fresh body
+		attemptReq := req
+		if attempt > 0 {
+			attemptReq = req.Clone(req.Context())
+			if req.Body != nil && req.Body != http.NoBody {
+				if req.GetBody == nil {
+					return nil, fmt.Errorf("cannot retry request with non-rewindable body")
+				}
+				body, err := req.GetBody()
+				if err != nil {
+					return nil, fmt.Errorf("rewind request body: %w", err)
+				}
+				attemptReq.Body = body
+			}
+		}
+
+		resp, err := t.Base.RoundTrip(attemptReq)
+		if err != nil {
+			return nil, err
+		}
+		t.recordUsage(resp.Header)
+
+		if !isRetryable(resp.StatusCode) || attempt >= t.MaxRetries {
+			return resp, nil
+		}
+
+		// A 429 with an exhausted quota will not clear within a backoff window
+		if resp.StatusCode == http.StatusTooManyRequests {
+			if err := t.checkQuota(); err != nil {
+				resp.Body.Close()
+				return nil, err
+			}
+		}
+
+		delay := t.backoff(attempt, resp.Header.Get("Retry-After"))
+		resp.Body.Close()
+		if err := t.sleep(req, delay); err != nil {
+			return nil, err
+		}
+	}
+}
+
+// checkQuota refuses to start a request while either quota window is exhausted
+func (t *RateLimitTransport) checkQuota() error {
+	t.mu.Lock()
+	usage := t.usage
+	t.mu.Unlock()
+
+	if !usage.Known() {
+		return nil
+	}
+
+	now := t.now()
+	if usage.LongLimit > 0 && usage.LongUsage >= usage.LongLimit {
+		if reset := nextDailyReset(usage.UpdatedAt); now.Before(reset) {
+			return &RateLimitError{Window: "daily", ResetAt: reset}
+		}
+	}
+	if usage.ShortLimit > 0 && usage.ShortUsage >= usage.ShortLimit {
+		if reset := nextShortReset(usage.UpdatedAt); now.Before(reset) {
+			return &RateLimitError{Window: "15-minute", ResetAt: reset}
+		}
+	}
+	return nil
+}
+
+// recordUsage updates the quota snapshot from X-RateLimit-Limit/X-RateLimit-Usage
+func (t *RateLimitTransport) recordUsage(header http.Header) {
+	shortLimit, longLimit, ok := parseRateLimitPair(header.Get("X-RateLimit-Limit"))
+	if !ok {
+		return
+	}
+	shortUsage, longUsage, ok := parseRateLimitPair(header.Get("X-RateLimit-Usage"))
+	if !ok {
+		return
+	}
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.usage = RateLimitUsage{
+		ShortLimit: shortLimit,
+		ShortUsage: shortUsage,
+		LongLimit:  longLimit,
+		LongUsage:  longUsage,
+		UpdatedAt:  t.now(),
+	}
+}
+
+// backoff returns the delay before the next attempt, honouring Retry-After
+// when present and otherwise using full-jitter exponential backoff
+func (t *RateLimitTransport) backoff(attempt int, retryAfter string) time.Duration {
+	if seconds, err := strconv.Atoi(retryAfter); err == nil && seconds >= 0 {
+		delay := time.Duration(seconds) * time.Second
+		if delay > t.MaxDelay {
+			delay = t.MaxDelay
+		}
+		return delay
+	}
+
+	ceiling := t.BaseDelay << attempt
+	if ceiling <= 0 || ceiling > t.MaxDelay {
+		ceiling = t.MaxDelay
+	}
+	return time.Duration(rand.Int63n(int64(ceiling) + 1))
+}
+
+// isRetryable reports whether a status code is worth retrying
+func isRetryable(status int) bool {
+	return status == http.StatusTooManyRequests || status >= 500
+}
+
+// parseRateLimitPair parses a "15min,daily" header value
+func parseRateLimitPair(value string) (int, int, bool) {
+	parts := strings.Split(value, ",")
+	if len(parts) != 2 {
+		return 0, 0, false
+	}
+	short, err := strconv.Atoi(strings.TrimSpace(parts[0]))
+	if err != nil {
+		return 0, 0, false
+	}
+	long, err := strconv.Atoi(strings.TrimSpace(parts[1]))
+	if err != nil {
+		return 0, 0, false
+	}
+	return short, long, true
+}
+
+// nextShortReset returns the next quarter-hour boundary after t; Strava's
+// 15-minute windows reset on the clock at :00, :15, :30 and :45
+func nextShortReset(t time.Time) time.Time {
+	return t.UTC().Truncate(15 * time.Minute).Add(15 * time.Minute)
+}
+
+// nextDailyReset returns the next UTC midnight after t
+func nextDailyReset(t time.Time) time.Time {
+	u := t.UTC()
+	return time.Date(u.Year(), u.Month(), u.Day()+1, 0, 0, 0, 0, time.UTC)
+}
+
+// sleepContext waits for d or until the request's context is cancelled
+func sleepContext(req *http.Request, d time.Duration) error {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+		return nil
+	case <-req.Context().Done():
+		return req.Context().Err()
+	}
+}
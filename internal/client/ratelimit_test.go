@@ -0,0 +1,186 @@
+package client
+
+import (
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+// newTestTransport returns a transport that never actually sleeps
+func newTestTransport(now time.Time) (*RateLimitTransport, *[]time.Duration) {
+	var delays []time.Duration
+	transport := NewRateLimitTransport(nil)
+	transport.now = func() time.Time { return now }
+	transport.sleep = func(_ *http.Request, d time.Duration) error {
+		delays = append(delays, d)
+		return nil
+	}
+	return transport, &delays
+}
+
+func TestRateLimitTransportRetriesServerErrors(t *testing.T) {
+	calls := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		if calls < 3 {
+			w.WriteHeader(http.StatusBadGateway)
+			return
+		}
+		w.Header().Set("X-RateLimit-Limit", "100,1000")
+		w.Header().Set("X-RateLimit-Usage", "12,340")
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	transport, delays := newTestTransport(time.Now())
+	httpClient := &http.Client{Transport: transport}
+
+	resp, err := httpClient.Get(server.URL)
+	if err != nil {
+		t.Fatalf("Expected success after retries, got %v", err)
+	}
+	resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		t.Errorf("Expected status 200, got %d", resp.StatusCode)
+	}
+	if calls != 3 {
+		t.Errorf("Expected 3 calls, got %d", calls)
+	}
+	if len(*delays) != 2 {
+		t.Errorf("Expected 2 backoff sleeps, got %d", len(*delays))
+	}
+	for _, d := range *delays {
+		if d > transport.MaxDelay {
+			t.Errorf("Backoff %v exceeds max delay %v", d, transport.MaxDelay)
+		}
+	}
+
+	usage := transport.Usage()
+	if usage.ShortUsage != 12 || usage.ShortLimit != 100 || usage.LongUsage != 340 || usage.LongLimit != 1000 {
+		t.Errorf("Unexpected usage parsed: %+v", usage)
+	}
+}
+
+func TestRateLimitTransportGivesUpAfterMaxRetries(t *testing.T) {
+	calls := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer server.Close()
+
+	transport, _ := newTestTransport(time.Now())
+	resp, err := (&http.Client{Transport: transport}).Get(server.URL)
+	if err != nil {
+		t.Fatalf("Expected final response, got error %v", err)
+	}
+	resp.Body.Close()
+
+	if resp.StatusCode != http.StatusServiceUnavailable {
+		t.Errorf("Expected final status 503, got %d", resp.StatusCode)
+	}
+	if calls != transport.MaxRetries+1 {
+		t.Errorf("Expected %d calls, got %d", transport.MaxRetries+1, calls)
+	}
+}
+
+func TestRateLimitTransportRefusesExhaustedQuota(t *testing.T) {
+	calls := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		w.Header().Set("X-RateLimit-Limit", "100,1000")
+		w.Header().Set("X-RateLimit-Usage", "100,400")
+		w.WriteHeader(http.StatusTooManyRequests)
+	}))
+	defer server.Close()
+
+	now := time.Date(2025, 10, 6, 9, 7, 0, 0, time.UTC)
+	transport, delays := newTestTransport(now)
+	httpClient := &http.Client{Transport: transport}
+
+	_, err := httpClient.Get(server.URL)
+	var rateErr *RateLimitError
+	if !errors.As(err, &rateErr) {
+		t.Fatalf("Expected RateLimitError, got %v", err)
+	}
+	if rateErr.Window != "15-minute" {
+		t.Errorf("Expected 15-minute window, got %s", rateErr.Window)
+	}
+	if want := time.Date(2025, 10, 6, 9, 15, 0, 0, time.UTC); !rateErr.ResetAt.Equal(want) {
+		t.Errorf("Expected reset at %v, got %v", want, rateErr.ResetAt)
+	}
+	if len(*delays) != 0 {
+		t.Errorf("Expected no backoff once quota is exhausted, got %v", *delays)
+	}
+
+	// The next request must not reach the server at all
+	if _, err := httpClient.Get(server.URL); !errors.As(err, &rateErr) {
+		t.Fatalf("Expected local refusal, got %v", err)
+	}
+	if calls != 1 {
+		t.Errorf("Expected 1 server call, got %d", calls)
+	}
+
+	// Once the window has rolled over requests are allowed again
+	transport.now = func() time.Time { return now.Add(10 * time.Minute) }
+	if err := transport.checkQuota(); err != nil {
+		t.Errorf("Expected quota to reset, got %v", err)
+	}
+}
+
+func TestParseRateLimitPair(t *testing.T) {
+	testCases := []struct {
+		value       string
+		short, long int
+		ok          bool
+	}{
+		{"100,1000", 100, 1000, true},
+		{"7, 42", 7, 42, true},
+		{"", 0, 0, false},
+		{"100", 0, 0, false},
+		{"a,b", 0, 0, false},
+	}
+
+	for _, tc := range testCases {
+		short, long, ok := parseRateLimitPair(tc.value)
+		if short != tc.short || long != tc.long || ok != tc.ok {
+			t.Errorf("For %q, expected (%d, %d, %v), got (%d, %d, %v)", tc.value, tc.short, tc.long, tc.ok, short, long, ok)
+		}
+	}
+}
+
+func TestRateLimitTransportRetriesWithoutModifyingRequest(t *testing.T) {
+	var bodies []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		bodies = append(bodies, string(body))
+		if len(bodies) < 2 {
+			w.WriteHeader(http.StatusBadGateway)
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	transport, _ := newTestTransport(time.Now())
+	req, _ := http.NewRequest(http.MethodPost, server.URL, strings.NewReader("grant_type=refresh_token"))
+	original := req.Body
+
+	resp, err := transport.RoundTrip(req)
+	if err != nil {
+		t.Fatalf("Expected success after a retry, got %v", err)
+	}
+	resp.Body.Close()
+
+	if len(bodies) != 2 || bodies[0] != bodies[1] || bodies[1] != "grant_type=refresh_token" {
+		t.Errorf("Expected the body to be sent in full on both attempts, got %q", bodies)
+	}
+	if req.Body != original {
+		t.Error("Expected the caller's request body to be left unchanged")
+	}
+}
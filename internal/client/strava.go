@@ -7,6 +7,10 @@ import (
 	"fmt"
 	"io"
 	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
 
 	"strava-custom-goals/config"
 	"strava-custom-goals/internal/models"
@@ -18,60 +22,189 @@ type StravaClient struct {
 	ClientSecret string
 	RefreshToken string
 	httpClient   *http.Client
+	rateLimiter  *RateLimitTransport
+	tokenStore   TokenStore
+	now          func() time.Time
+
+	// Endpoint overrides, primarily for tests against httptest servers
+	tokenURL      string
+	activitiesURL string
+	activityURL   string
+}
+
+// TokenStore persists OAuth tokens between runs so rotated refresh tokens are not lost
+type TokenStore interface {
+	// Load returns the stored token, or nil if none has been saved yet
+	Load() (*models.TokenResponse, error)
+	Save(token *models.TokenResponse) error
+}
+
+// ActivityQuery bounds an activity listing. Zero times leave that side of the window open.
+type ActivityQuery struct {
+	After   time.Time // only activities that started after this instant
+	Before  time.Time // only activities that started before this instant
+	PerPage int       // page size; defaults to config.MaxPerPage
 }
 
 // NewStravaClient creates a new Strava API client
 func NewStravaClient(clientID, clientSecret, refreshToken string) *StravaClient {
+	rateLimiter := NewRateLimitTransport(nil)
 	return &StravaClient{
-		ClientID:     clientID,
-		ClientSecret: clientSecret,
-		RefreshToken: refreshToken,
-		httpClient:   &http.Client{Timeout: config.RequestTimeout},
+		ClientID:      clientID,
+		ClientSecret:  clientSecret,
+		RefreshToken:  refreshToken,
+		httpClient:    &http.Client{Timeout: config.RequestTimeout, Transport: rateLimiter},
+		rateLimiter:   rateLimiter,
+		now:           time.Now,
+		tokenURL:      config.StravaTokenURL,
+		activitiesURL: config.StravaActivitiesURL,
+		activityURL:   config.StravaActivityURL,
 	}
 }
 
-// GetAccessToken exchanges refresh token for access token via OAuth
+// SetTokenStore enables token persistence: stored access tokens are reused
+// until they expire and rotated refresh tokens are saved after each refresh
+func (c *StravaClient) SetTokenStore(store TokenStore) {
+	c.tokenStore = store
+}
+
+// RateLimitUsage returns the quota usage reported by the most recent API response
+func (c *StravaClient) RateLimitUsage() RateLimitUsage {
+	return c.rateLimiter.Usage()
+}
+
+// GetAccessToken returns a usable access token, reusing the stored one until it
+// expires and otherwise exchanging the refresh token via OAuth
 func (c *StravaClient) GetAccessToken() (string, error) {
+	refreshToken := c.RefreshToken
+
+	if c.tokenStore != nil {
+		stored, err := c.tokenStore.Load()
+		if err != nil {
+			return "", fmt.Errorf("load stored token: %w", err)
+		}
+		if stored.ValidAt(c.now(), config.TokenExpiryMargin) {
+			return stored.AccessToken, nil
+		}
+		// Strava may have rotated the refresh token; the stored one supersedes .env
+		if stored != nil && stored.RefreshToken != "" {
+			refreshToken = stored.RefreshToken
+		}
+	}
+
+	if refreshToken == "" {
+		return "", fmt.Errorf("no refresh token available, run \"auth login\" first")
+	}
+
+	tokenResp, err := c.requestToken(map[string]string{
+		"refresh_token": refreshToken,
+		"grant_type":    "refresh_token",
+	})
+	if err != nil {
+		return "", err
+	}
+
+	if c.tokenStore != nil {
+		if err := c.tokenStore.Save(tokenResp); err != nil {
+			return "", fmt.Errorf("save refreshed token: %w", err)
+		}
+	}
+
+	return tokenResp.AccessToken, nil
+}
+
+// ExchangeAuthorizationCode trades an OAuth authorization code for tokens and
+// saves them to the token store, if one is set
+func (c *StravaClient) ExchangeAuthorizationCode(code string) (*models.TokenResponse, error) {
+	tokenResp, err := c.requestToken(map[string]string{
+		"code":       code,
+		"grant_type": "authorization_code",
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if c.tokenStore != nil {
+		if err := c.tokenStore.Save(tokenResp); err != nil {
+			return nil, fmt.Errorf("save token: %w", err)
+		}
+	}
+
+	return tokenResp, nil
+}
+
+// requestToken posts a grant to the OAuth token endpoint
+func (c *StravaClient) requestToken(grant map[string]string) (*models.TokenResponse, error) {
 	requestBody := map[string]string{
 		"client_id":     c.ClientID,
 		"client_secret": c.ClientSecret,
-		"refresh_token": c.RefreshToken,
-		"grant_type":    "refresh_token",
+	}
+	for key, value := range grant {
+		requestBody[key] = value
 	}
 
 	jsonBody, err := json.Marshal(requestBody)
 	if err != nil {
-		return "", fmt.Errorf("marshal request: %w", err)
+		return nil, fmt.Errorf("marshal request: %w", err)
 	}
 
-	resp, err := c.httpClient.Post(config.StravaTokenURL, "application/json", bytes.NewBuffer(jsonBody))
+	resp, err := c.httpClient.Post(c.tokenURL, "application/json", bytes.NewBuffer(jsonBody))
 	if err != nil {
-		return "", fmt.Errorf("token request: %w", err)
+		return nil, fmt.Errorf("token request: %w", err)
 	}
 	defer resp.Body.Close()
 
 	if resp.StatusCode != http.StatusOK {
 		body, _ := io.ReadAll(resp.Body)
-		return "", fmt.Errorf("token API error %d: %s", resp.StatusCode, string(body))
+		return nil, fmt.Errorf("token API error %d: %s", resp.StatusCode, string(body))
 	}
 
 	var tokenResp models.TokenResponse
 	if err := json.NewDecoder(resp.Body).Decode(&tokenResp); err != nil {
-		return "", fmt.Errorf("decode token response: %w", err)
+		return nil, fmt.Errorf("decode token response: %w", err)
 	}
 
 	if tokenResp.AccessToken == "" {
-		return "", fmt.Errorf("empty access token received")
+		return nil, fmt.Errorf("empty access token received")
 	}
 
-	return tokenResp.AccessToken, nil
+	return &tokenResp, nil
+}
+
+// GetActivities fetches every activity matching the query, walking pages until
+// Strava returns an empty page
+func (c *StravaClient) GetActivities(accessToken string, query ActivityQuery) ([]models.Activity, error) {
+	perPage := query.PerPage
+	if perPage <= 0 {
+		perPage = config.MaxPerPage
+	}
+
+	var activities []models.Activity
+	for page := 1; ; page++ {
+		batch, err := c.getActivitiesPage(accessToken, query, page, perPage)
+		if err != nil {
+			return nil, fmt.Errorf("page %d: %w", page, err)
+		}
+		if len(batch) == 0 {
+			return activities, nil
+		}
+		activities = append(activities, batch...)
+	}
 }
 
-// GetActivities fetches recent activities using the provided access token
-func (c *StravaClient) GetActivities(accessToken string) ([]models.Activity, error) {
-	url := fmt.Sprintf("%s?per_page=%d&page=1", config.StravaActivitiesURL, config.DefaultPerPage)
+// getActivitiesPage fetches a single page of the activities listing
+func (c *StravaClient) getActivitiesPage(accessToken string, query ActivityQuery, page, perPage int) ([]models.Activity, error) {
+	params := url.Values{}
+	params.Set("per_page", strconv.Itoa(perPage))
+	params.Set("page", strconv.Itoa(page))
+	if !query.After.IsZero() {
+		params.Set("after", strconv.FormatInt(query.After.Unix(), 10))
+	}
+	if !query.Before.IsZero() {
+		params.Set("before", strconv.FormatInt(query.Before.Unix(), 10))
+	}
 
-	req, err := http.NewRequest("GET", url, nil)
+	req, err := http.NewRequest("GET", c.activitiesURL+"?"+params.Encode(), nil)
 	if err != nil {
 		return nil, fmt.Errorf("create request: %w", err)
 	}
@@ -102,3 +235,91 @@ func (c *StravaClient) GetActivities(accessToken string) ([]models.Activity, err
 
 	return activities, nil
 }
+
+// GetActivity fetches a single activity with its laps, splits, best efforts
+// and the other fields the listing leaves out. Activities that are gone or
+// hidden return no details, the same way streams do.
+func (c *StravaClient) GetActivity(accessToken string, id int64) (*models.Activity, error) {
+	req, err := http.NewRequest("GET", c.activityURL+"/"+strconv.FormatInt(id, 10), nil)
+	if err != nil {
+		return nil, fmt.Errorf("create request: %w", err)
+	}
+	req.Header.Set("Authorization", "Bearer "+accessToken)
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("execute request: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusNotFound {
+		return &models.Activity{ID: id}, nil // Deleted or made private since listed
+	}
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(resp.Body)
+		return nil, fmt.Errorf("activity API error %d: %s", resp.StatusCode, string(body))
+	}
+
+	var activity models.Activity
+	if err := json.NewDecoder(resp.Body).Decode(&activity); err != nil {
+		return nil, fmt.Errorf("decode activity: %w", err)
+	}
+	return &activity, nil
+}
+
+// GetActivityStreams fetches an activity's sampled data for models.StreamKeys.
+// Activities without recorded data, such as manual entries, return empty streams.
+func (c *StravaClient) GetActivityStreams(accessToken string, id int64) (*models.Streams, error) {
+	params := url.Values{}
+	params.Set("keys", strings.Join(models.StreamKeys, ","))
+	params.Set("key_by_type", "true")
+
+	req, err := http.NewRequest("GET", c.activityURL+"/"+strconv.FormatInt(id, 10)+"/streams?"+params.Encode(), nil)
+	if err != nil {
+		return nil, fmt.Errorf("create request: %w", err)
+	}
+	req.Header.Set("Authorization", "Bearer "+accessToken)
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("execute request: %w", err)
+	}
+	defer resp.Body.Close()
+
+	streams := &models.Streams{ActivityID: id}
+	if resp.StatusCode == http.StatusNotFound {
+		return streams, nil // Nothing was recorded
+	}
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(resp.Body)
+		return nil, fmt.Errorf("streams API error %d: %s", resp.StatusCode, string(body))
+	}
+
+	// Each stream arrives as {"data": [...], "series_type": ..., ...}
+	var keyed map[string]struct {
+		Data json.RawMessage `json:"data"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&keyed); err != nil {
+		return nil, fmt.Errorf("decode streams: %w", err)
+	}
+	targets := map[string]interface{}{
+		"time":            &streams.Time,
+		"distance":        &streams.Distance,
+		"heartrate":       &streams.Heartrate,
+		"velocity_smooth": &streams.Velocity,
+		"cadence":         &streams.Cadence,
+		"watts":           &streams.Watts,
+		"altitude":        &streams.Altitude,
+		"latlng":          &streams.LatLng,
+	}
+	for key, stream := range keyed {
+		target, ok := targets[key]
+		if !ok || len(stream.Data) == 0 {
+			continue
+		}
+		if err := json.Unmarshal(stream.Data, target); err != nil {
+			return nil, fmt.Errorf("decode %s stream: %w", key, err)
+		}
+	}
+	return streams, nil
+}
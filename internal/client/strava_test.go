@@ -0,0 +1,302 @@
+package client
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"strava-custom-goals/internal/models"
+)
+
+// newActivitiesServer serves the given activities through a paginated stand-in of
+// the Strava activities endpoint, honouring after/before/page/per_page
+func newActivitiesServer(t *testing.T, all []models.Activity, requests *[]string) *httptest.Server {
+	t.Helper()
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		*requests = append(*requests, r.URL.RawQuery)
+
+		if r.Header.Get("Authorization") != "Bearer test-token" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+
+		q := r.URL.Query()
+		page, _ := strconv.Atoi(q.Get("page"))
+		perPage, _ := strconv.Atoi(q.Get("per_page"))
+		after, _ := strconv.ParseInt(q.Get("after"), 10, 64)
+		before, _ := strconv.ParseInt(q.Get("before"), 10, 64)
+
+		var matching []models.Activity
+		for _, a := range all {
+			start, _ := time.Parse(time.RFC3339, a.StartDate)
+			if after != 0 && start.Unix() <= after {
+				continue
+			}
+			if before != 0 && start.Unix() >= before {
+				continue
+			}
+			matching = append(matching, a)
+		}
+
+		from := (page - 1) * perPage
+		to := from + perPage
+		if from > len(matching) {
+			from = len(matching)
+		}
+		if to > len(matching) {
+			to = len(matching)
+		}
+		json.NewEncoder(w).Encode(matching[from:to])
+	}))
+}
+
+func TestGetActivitiesWalksAllPages(t *testing.T) {
+	base := time.Date(2025, 10, 6, 7, 0, 0, 0, time.UTC)
+	var all []models.Activity
+	for i := 0; i < 7; i++ {
+		all = append(all, models.Activity{
+			ID:        int64(i + 1),
+			Type:      "Run",
+			StartDate: base.Add(time.Duration(i) * 6 * time.Hour).Format(time.RFC3339),
+		})
+	}
+
+	var requests []string
+	server := newActivitiesServer(t, all, &requests)
+	defer server.Close()
+
+	c := NewStravaClient("id", "secret", "refresh")
+	c.activitiesURL = server.URL
+
+	activities, err := c.GetActivities("test-token", ActivityQuery{PerPage: 3})
+	if err != nil {
+		t.Fatalf("GetActivities returned error: %v", err)
+	}
+
+	if len(activities) != 7 {
+		t.Errorf("Expected 7 activities, got %d", len(activities))
+	}
+
+	// Three pages of data plus the terminating empty page
+	if len(requests) != 4 {
+		t.Errorf("Expected 4 requests, got %d: %v", len(requests), requests)
+	}
+}
+
+func TestGetActivitiesAppliesTimeWindow(t *testing.T) {
+	base := time.Date(2025, 10, 6, 7, 0, 0, 0, time.UTC)
+	var all []models.Activity
+	for i := 0; i < 10; i++ {
+		all = append(all, models.Activity{
+			ID:        int64(i + 1),
+			StartDate: base.AddDate(0, 0, i).Format(time.RFC3339),
+		})
+	}
+
+	var requests []string
+	server := newActivitiesServer(t, all, &requests)
+	defer server.Close()
+
+	c := NewStravaClient("id", "secret", "refresh")
+	c.activitiesURL = server.URL
+
+	query := ActivityQuery{
+		After:  base.AddDate(0, 0, 2),
+		Before: base.AddDate(0, 0, 6),
+	}
+	activities, err := c.GetActivities("test-token", query)
+	if err != nil {
+		t.Fatalf("GetActivities returned error: %v", err)
+	}
+
+	// Days 3, 4 and 5 fall strictly inside the window
+	if len(activities) != 3 {
+		t.Fatalf("Expected 3 activities, got %d", len(activities))
+	}
+	if activities[0].ID != 4 || activities[2].ID != 6 {
+		t.Errorf("Unexpected activities returned: %+v", activities)
+	}
+}
+
+func TestGetActivitiesReturnsAPIError(t *testing.T) {
+	var requests []string
+	server := newActivitiesServer(t, nil, &requests)
+	defer server.Close()
+
+	c := NewStravaClient("id", "secret", "refresh")
+	c.activitiesURL = server.URL
+
+	if _, err := c.GetActivities("wrong-token", ActivityQuery{}); err == nil {
+		t.Error("Expected error for unauthorized request, got nil")
+	}
+}
+
+func TestGetActivityDecodesDetails(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/42" || r.Header.Get("Authorization") != "Bearer token" {
+			http.Error(w, "not found", http.StatusNotFound)
+			return
+		}
+		w.Write([]byte(`{"id": 42, "name": "Long Run", "type": "Run", "distance": 2012.5,
+			"calories": 612.5, "device_name": "Garmin Forerunner 255", "gear": {"id": "g1", "name": "Pegasus"},
+			"splits_metric": [{"split": 1, "distance": 1000.2, "moving_time": 290}, {"split": 2, "distance": 1012.3, "moving_time": 300}],
+			"laps": [{"lap_index": 1, "distance": 2012.5, "moving_time": 590}],
+			"best_efforts": [{"name": "1k", "distance": 1000, "elapsed_time": 288, "pr_rank": 2}]}`))
+	}))
+	defer server.Close()
+
+	c := NewStravaClient("id", "secret", "refresh")
+	c.activityURL = server.URL
+
+	activity, err := c.GetActivity("token", 42)
+	if err != nil {
+		t.Fatalf("GetActivity failed: %v", err)
+	}
+	if activity.Calories != 612.5 || activity.DeviceName != "Garmin Forerunner 255" || activity.Gear == nil || activity.Gear.Name != "Pegasus" {
+		t.Errorf("Unexpected activity details %+v", activity)
+	}
+	if len(activity.Splits) != 2 || len(activity.Laps) != 1 || len(activity.BestEfforts) != 1 || activity.BestEfforts[0].PRRank != 2 {
+		t.Errorf("Expected 2 splits, 1 lap and 1 best effort, got %+v", activity)
+	}
+
+	// A missing activity has no details rather than failing
+	missing, err := c.GetActivity("token", 7)
+	if err != nil || missing.ID != 7 || missing.HasDetails() || len(missing.Splits) != 0 {
+		t.Errorf("Expected empty details for a missing activity, got %+v, %v", missing, err)
+	}
+
+	failing := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "unauthorized", http.StatusUnauthorized)
+	}))
+	defer failing.Close()
+	c.activityURL = failing.URL
+	if _, err := c.GetActivity("wrong-token", 42); err == nil {
+		t.Error("Expected error for an unauthorized request, got nil")
+	}
+}
+
+func TestGetActivityStreamsDecodesKeyedSeries(t *testing.T) {
+	var query string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/42/streams" {
+			http.Error(w, `{"message": "Resource Not Found"}`, http.StatusNotFound)
+			return
+		}
+		query = r.URL.RawQuery
+		w.Write([]byte(`{
+			"time": {"data": [0, 1, 3], "series_type": "distance", "original_size": 3, "resolution": "high"},
+			"distance": {"data": [0, 2.8, 8.9], "series_type": "distance"},
+			"heartrate": {"data": [118, 121, 126], "series_type": "distance"},
+			"latlng": {"data": [[52.37, 4.89], [52.37, 4.891], [52.371, 4.892]], "series_type": "distance"},
+			"temp": {"data": [21, 21, 22], "series_type": "distance"}}`))
+	}))
+	defer server.Close()
+
+	c := NewStravaClient("id", "secret", "refresh")
+	c.activityURL = server.URL
+
+	streams, err := c.GetActivityStreams("token", 42)
+	if err != nil {
+		t.Fatalf("GetActivityStreams failed: %v", err)
+	}
+	if !strings.Contains(query, "key_by_type=true") || !strings.Contains(query, "velocity_smooth") {
+		t.Errorf("Expected all stream keys keyed by type to be requested, got %q", query)
+	}
+	if streams.ActivityID != 42 || streams.Len() != 3 || streams.Distance[2] != 8.9 || streams.Heartrate[1] != 121 || streams.LatLng[2][0] != 52.371 {
+		t.Errorf("Unexpected streams %+v", streams)
+	}
+	if streams.Watts != nil {
+		t.Errorf("Expected no watts stream, got %v", streams.Watts)
+	}
+
+	// Manual activities have nothing recorded
+	empty, err := c.GetActivityStreams("token", 7)
+	if err != nil || empty.Len() != 0 {
+		t.Errorf("Expected empty streams for an activity without data, got %+v, %v", empty, err)
+	}
+}
+
+// memoryTokenStore is an in-memory TokenStore for tests
+type memoryTokenStore struct {
+	token *models.TokenResponse
+	saves int
+}
+
+func (s *memoryTokenStore) Load() (*models.TokenResponse, error) { return s.token, nil }
+
+func (s *memoryTokenStore) Save(token *models.TokenResponse) error {
+	s.token = token
+	s.saves++
+	return nil
+}
+
+func TestGetAccessTokenReusesUnexpiredToken(t *testing.T) {
+	now := time.Date(2025, 10, 6, 9, 0, 0, 0, time.UTC)
+	store := &memoryTokenStore{token: &models.TokenResponse{
+		AccessToken:  "cached-access",
+		RefreshToken: "cached-refresh",
+		ExpiresAt:    now.Add(time.Hour).Unix(),
+	}}
+
+	c := NewStravaClient("id", "secret", "env-refresh")
+	c.tokenURL = "http://127.0.0.1:1" // must not be contacted
+	c.now = func() time.Time { return now }
+	c.SetTokenStore(store)
+
+	token, err := c.GetAccessToken()
+	if err != nil {
+		t.Fatalf("GetAccessToken returned error: %v", err)
+	}
+	if token != "cached-access" {
+		t.Errorf("Expected cached access token, got %s", token)
+	}
+	if store.saves != 0 {
+		t.Errorf("Expected no saves, got %d", store.saves)
+	}
+}
+
+func TestGetAccessTokenPersistsRotatedRefreshToken(t *testing.T) {
+	now := time.Date(2025, 10, 6, 9, 0, 0, 0, time.UTC)
+
+	var usedRefreshToken string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var body map[string]string
+		json.NewDecoder(r.Body).Decode(&body)
+		usedRefreshToken = body["refresh_token"]
+		json.NewEncoder(w).Encode(models.TokenResponse{
+			AccessToken:  "new-access",
+			RefreshToken: "rotated-refresh",
+			ExpiresAt:    now.Add(6 * time.Hour).Unix(),
+		})
+	}))
+	defer server.Close()
+
+	store := &memoryTokenStore{token: &models.TokenResponse{
+		AccessToken:  "stale-access",
+		RefreshToken: "stored-refresh",
+		ExpiresAt:    now.Add(time.Minute).Unix(), // inside the expiry margin
+	}}
+
+	c := NewStravaClient("id", "secret", "env-refresh")
+	c.tokenURL = server.URL
+	c.now = func() time.Time { return now }
+	c.SetTokenStore(store)
+
+	token, err := c.GetAccessToken()
+	if err != nil {
+		t.Fatalf("GetAccessToken returned error: %v", err)
+	}
+	if token != "new-access" {
+		t.Errorf("Expected new access token, got %s", token)
+	}
+	if usedRefreshToken != "stored-refresh" {
+		t.Errorf("Expected stored refresh token to be used, got %s", usedRefreshToken)
+	}
+	if store.token.RefreshToken != "rotated-refresh" || store.saves != 1 {
+		t.Errorf("Expected rotated token to be saved once, got %+v after %d saves", store.token, store.saves)
+	}
+}
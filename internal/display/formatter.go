@@ -1,202 +1,396 @@
-// Package display provides formatting and display functionality for Strava activities.
+// Package display renders reports for Strava activities and goals in the
+// supported output formats.
 package display
 
 import (
 	"fmt"
+	"io"
+	"math"
+	"strings"
+	"time"
+	"unicode"
 
 	"strava-custom-goals/internal/goals"
 	"strava-custom-goals/internal/models"
+	"strava-custom-goals/internal/report"
 )
 
-// DisplayActivities shows activity information in a formatted way
-func DisplayActivities(activities []models.Activity) {
-	fmt.Println("\n🏃‍♂️ === RECENT ACTIVITIES ===")
+// TextRenderer writes the terminal report. With ASCII set it drops emoji and
+// draws progress bars with plain characters, for logs and dumb terminals.
+type TextRenderer struct {
+	ASCII   bool
+	Summary bool
+}
+
+// Render implements Renderer
+func (t *TextRenderer) Render(w io.Writer, r *report.Report) error {
+	p := &printer{w: w}
+
+	t.renderGoals(p, r)
+	if r.TrainingLoad != nil {
+		t.renderTrainingLoad(p, r.TrainingLoad)
+	}
+	if len(r.Activities) > 0 {
+		t.renderActivities(p, r.Activities)
+	}
+	if t.Summary {
+		t.renderSummary(p, r.Summary)
+	}
+	return p.err
+}
+
+// renderGoals shows progress toward each configured goal
+func (t *TextRenderer) renderGoals(p *printer, r *report.Report) {
+	p.printf("\n%s=== GOALS PROGRESS ===\n", t.icon("🎯 "))
+
+	for i, goal := range r.Goals {
+		if i > 0 {
+			p.printf("\n")
+		}
+
+		metric := goals.Metric(goal.Metric)
+		status, bar := getProgressDisplay(goal.Percent, t.ASCII)
+
+		p.printf("   %s%s Target: %s / %s (%.1f%%)\n", t.icon(metricEmoji(metric)+" "), goal.Name,
+			FormatMetricValue(metric, goal.Actual), FormatTarget(metric, goal.Target, goal.Max), goal.Percent)
+		p.printf("      %s %s\n", bar, status)
+		if goal.Zones != nil {
+			t.renderZones(p, goal.Zones)
+		}
+
+		switch {
+		case goal.Over:
+			p.printf("      %sOver the top of your range by %s\n", t.icon("⚠️ "),
+				FormatMetricValue(metric, goal.Actual-goal.Max))
+		case goal.Achieved && goal.Max > 0:
+			p.printf("      %sGoal achieved! %s left before the top of your range\n", t.icon("🎉 "),
+				FormatMetricValue(metric, goal.Max-goal.Actual))
+		case !goal.Achieved:
+			p.printf("      %sStill need: %s to complete your %s goal\n", t.icon("💭 "),
+				FormatMetricValue(metric, goal.Remaining), periodAdjective(goal.Period))
+		default:
+			p.printf("      %sGoal achieved! You've exceeded by %s\n", t.icon("🎉 "),
+				FormatMetricValue(metric, goal.Actual-goal.Target))
+		}
+		if projection := goal.Projection; projection != nil && !goal.Achieved {
+			p.printf("      %sProjected: %s by period end, %s\n", t.icon(projectionEmoji(projection.Status)+" "),
+				FormatMetricValue(metric, projection.Projected), ProjectionLabel(projection.Status))
+			if projection.RequiredPerDay > 0 {
+				p.printf("      %sNeed %s/day for the remaining %d days\n", t.icon("📅 "),
+					FormatMetricValue(metric, projection.RequiredPerDay), projection.DaysRemaining)
+			}
+		}
+		p.printf("      %sFrom %d activities\n", t.icon("📈 "), goal.Activities)
+	}
+
+	// Motivational message
+	message := r.Message
+	if t.ASCII {
+		message = stripIcon(message)
+	}
+	p.printf("\n   %s%s\n", t.icon("💬 "), message)
+
+	// Streaks
+	for _, streak := range r.Streaks {
+		message := streak.Message
+		if t.ASCII {
+			message = stripIcon(message)
+		}
+		p.printf("   %s\n", message)
+	}
+}
+
+// renderZones draws a bar per zone under a zone goal's progress bar, marking
+// the zones the goal counts
+func (t *TextRenderer) renderZones(p *printer, zones *report.ZoneDistribution) {
+	p.printf("      %sTime in zones, %s counted:\n", t.icon(zoneEmoji(zones.Type)+" "), zones.Range)
+	marker := "▶"
+	if t.ASCII {
+		marker = ">"
+	}
+	for _, zone := range zones.Zones {
+		_, bar := getProgressDisplay(zone.Percent, t.ASCII)
+		counted := " "
+		if zone.InGoal {
+			counted = marker
+		}
+		p.printf("      %s Z%d %s %3.0f%% %s\n", counted, zone.Zone, bar, zone.Percent, FormatMinutes(zone.Minutes))
+	}
+}
+
+// renderTrainingLoad shows fitness, fatigue and form
+func (t *TextRenderer) renderTrainingLoad(p *printer, load *report.TrainingLoadInfo) {
+	p.printf("\n%s=== TRAINING LOAD ===\n", t.icon("🔋 "))
+	p.printf("   %sFitness (CTL): %.1f\n", t.icon("📈 "), load.Fitness)
+	p.printf("   %sFatigue (ATL): %.1f\n", t.icon("😮‍💨 "), load.Fatigue)
+	p.printf("   %sForm (TSB): %+.1f, %s\n", t.icon(formEmoji(load.FormZone)+" "), load.Form, FormLabel(load.FormZone))
+	p.printf("   %sLast 7 days: %s\n", t.icon("📅 "), FormatMetricValue(goals.MetricLoad, load.WeekLoad))
+	if load.Estimated > 0 {
+		p.printf("   %sActivities without heart rate, load estimated: %d\n", t.icon("ℹ️ "), load.Estimated)
+	}
+}
+
+// renderActivities shows activity information in a formatted way
+func (t *TextRenderer) renderActivities(p *printer, activities []report.Activity) {
+	p.printf("\n%s=== RECENT ACTIVITIES ===\n", t.icon("🏃‍♂️ "))
 
 	for i, activity := range activities {
-		fmt.Printf("\n📈 Activity %d\n", i+1)
-		fmt.Printf("   🏷️  Name: %s\n", activity.Name)
-		fmt.Printf("   🎯 Type: %s\n", activity.Type)
-		fmt.Printf("   📏 Distance: %.2f km\n", activity.DistanceKm)
-		fmt.Printf("   ⏱️  Moving Time: %s\n", models.FormatDuration(activity.MovingTime))
-
-		if activity.TotalElevGain > 0 {
-			fmt.Printf("   ⛰️  Elevation Gain: %.0f m\n", activity.TotalElevGain)
+		p.printf("\n%sActivity %d\n", t.icon("📈 "), i+1)
+		p.printf("   %sName: %s\n", t.icon("🏷️  "), activity.Name)
+		p.printf("   %sType: %s\n", t.icon("🎯 "), activity.Type)
+		p.printf("   %sDistance: %.2f km\n", t.icon("📏 "), activity.DistanceKm)
+		p.printf("   %sMoving Time: %s\n", t.icon("⏱️  "), models.FormatDuration(activity.MovingTime))
+
+		if activity.TotalElevationGain > 0 {
+			p.printf("   %sElevation Gain: %.0f m\n", t.icon("⛰️  "), activity.TotalElevationGain)
 		}
 
 		if activity.Type == "Run" && activity.PaceMinPerKm != "" {
-			fmt.Printf("   🏃 Average Pace: %s min/km\n", activity.PaceMinPerKm)
+			p.printf("   %sAverage Pace: %s min/km\n", t.icon("🏃 "), activity.PaceMinPerKm)
 		}
 
 		if activity.HasHeartrate && activity.AverageHeartrate > 0 {
-			fmt.Printf("   ❤️  Avg Heart Rate: %.0f bpm\n", activity.AverageHeartrate)
+			p.printf("   %sAvg Heart Rate: %.0f bpm\n", t.icon("❤️  "), activity.AverageHeartrate)
 		}
 
 		if activity.Kudos > 0 {
-			fmt.Printf("   👍 Kudos: %d\n", activity.Kudos)
+			p.printf("   %sKudos: %d\n", t.icon("👍 "), activity.Kudos)
 		}
 
-		fmt.Printf("   📅 Date: %s\n", models.FormatDate(activity.StartDateLocal))
+		p.printf("   %sDate: %s\n", t.icon("📅 "), models.FormatDate(activity.StartDateLocal))
 	}
 }
 
-// DisplaySummary shows a summary of activities
-func DisplaySummary(activities []models.Activity) {
-	if len(activities) == 0 {
-		fmt.Println("📊 No activities to analyze")
+// renderSummary shows a summary of activities
+func (t *TextRenderer) renderSummary(p *printer, summary report.Summary) {
+	if summary.TotalActivities == 0 {
+		p.printf("%sNo activities to analyze\n", t.icon("📊 "))
 		return
 	}
 
-	totalDistance := 0.0
-	totalTime := 0
-	runCount := 0
-	rideCount := 0
+	p.printf("\n%s=== ACTIVITY SUMMARY ===\n", t.icon("📊 "))
+	p.printf("   %sTotal Activities: %d\n", t.icon("📈 "), summary.TotalActivities)
+	p.printf("   %sRuns: %d\n", t.icon("🏃 "), summary.Runs)
+	p.printf("   %sRides: %d\n", t.icon("🚴 "), summary.Rides)
+	p.printf("   %sTotal Distance: %.2f km\n", t.icon("📏 "), summary.TotalDistanceKm)
+	p.printf("   %sTotal Time: %s\n", t.icon("⏱️  "), models.FormatDuration(summary.TotalMovingTime))
 
-	for _, activity := range activities {
-		totalDistance += activity.DistanceKm
-		totalTime += activity.MovingTime
+	if summary.TotalDistanceKm > 0 {
+		p.printf("   %sAverage Distance: %.2f km\n", t.icon("📊 "), summary.AverageDistanceKm)
+	}
+}
 
-		switch activity.Type {
-		case "Run":
-			runCount++
-		case "Ride":
-			rideCount++
-		}
+// icon returns an emoji prefix, or nothing in ASCII mode
+func (t *TextRenderer) icon(emoji string) string {
+	if t.ASCII {
+		return ""
 	}
+	return emoji
+}
 
-	fmt.Println("\n📊 === ACTIVITY SUMMARY ===")
-	fmt.Printf("   📈 Total Activities: %d\n", len(activities))
-	fmt.Printf("   🏃 Runs: %d\n", runCount)
-	fmt.Printf("   🚴 Rides: %d\n", rideCount)
-	fmt.Printf("   📏 Total Distance: %.2f km\n", totalDistance)
-	fmt.Printf("   ⏱️  Total Time: %s\n", models.FormatDuration(totalTime))
-
-	if totalDistance > 0 {
-		avgDistance := totalDistance / float64(len(activities))
-		fmt.Printf("   📊 Average Distance: %.2f km\n", avgDistance)
-	}
-}
-
-// DisplayWeeklyGoalsProgress shows progress toward weekly fitness goals
-func DisplayWeeklyGoalsProgress(progress *goals.WeeklyProgress) {
-	fmt.Println("\n🎯 === WEEKLY GOALS PROGRESS ===")
-
-	// Running progress
-	runningPercent := progress.GetRunningProgressPercentage()
-<<<<<<< Updated upstream
-	runningStatus, runningBar := getProgressDisplay(runningPercent)
-	
-	fmt.Printf("   🏃‍♂️ Running Target: %.1f km / %.1f km (%.1f%%)\n", 
-		progress.RunningDistance, progress.Goals.RunningGoalKm, runningPercent)
-	fmt.Printf("      %s %s\n", runningBar, runningStatus)
-	
-=======
-	runningStatus := "🔴"
-	if progress.IsRunningGoalAchieved() {
-		runningStatus = "✅"
-	} else if runningPercent >= 75 {
-		runningStatus = "🟡"
-	} else if runningPercent >= 50 {
-		runningStatus = "🟠"
-	}
-
-	fmt.Printf("   🏃‍♂️ Running Goal: %.1f/%.1f km %s (%.1f%%)\n",
-		progress.RunningDistance, progress.Goals.RunningGoalKm, runningStatus, runningPercent)
-
->>>>>>> Stashed changes
-	if !progress.IsRunningGoalAchieved() {
-		fmt.Printf("      💭 Still need: %.1f km to complete your weekly goal\n", progress.GetRunningRemainingDistance())
-	} else {
-		fmt.Printf("      🎉 Goal achieved! You've exceeded by %.1f km\n", progress.RunningDistance-progress.Goals.RunningGoalKm)
-	}
-
-	// Workout progress
-	workoutPercent := progress.GetWorkoutProgressPercentage()
-<<<<<<< Updated upstream
-	workoutStatus, workoutBar := getProgressDisplay(workoutPercent)
-	
-	fmt.Printf("\n   💪 Workout Target: %.1f hours / %.1f hours (%.1f%%)\n", 
-		progress.WorkoutHours, progress.Goals.WorkoutGoalHours, workoutPercent)
-	fmt.Printf("      %s %s\n", workoutBar, workoutStatus)
-	
-=======
-	workoutStatus := "🔴"
-	if progress.IsWorkoutGoalAchieved() {
-		workoutStatus = "✅"
-	} else if workoutPercent >= 75 {
-		workoutStatus = "🟡"
-	} else if workoutPercent >= 50 {
-		workoutStatus = "🟠"
-	}
-
-	fmt.Printf("   💪 Workout Goal: %.1f/%.1f hours %s (%.1f%%)\n",
-		progress.WorkoutHours, progress.Goals.WorkoutGoalHours, workoutStatus, workoutPercent)
-
->>>>>>> Stashed changes
-	if !progress.IsWorkoutGoalAchieved() {
-		workoutRemaining := progress.GetWorkoutRemainingHours()
-		hours := int(workoutRemaining)
-		minutes := int((workoutRemaining - float64(hours)) * 60)
+// printer writes formatted output, remembering the first error
+type printer struct {
+	w   io.Writer
+	err error
+}
+
+// printf writes unless an earlier write failed
+func (p *printer) printf(format string, args ...interface{}) {
+	if p.err != nil {
+		return
+	}
+	_, p.err = fmt.Fprintf(p.w, format, args...)
+}
+
+// stripIcon removes a leading emoji from a message
+func stripIcon(message string) string {
+	return strings.TrimLeftFunc(message, func(r rune) bool {
+		return r > unicode.MaxASCII || unicode.IsSpace(r)
+	})
+}
+
+// periodAdjective describes a report period for messages such as "your weekly goal"
+func periodAdjective(period report.PeriodInfo) string {
+	if goals.PeriodKind(period.Kind) != goals.PeriodCustom {
+		return goals.Every(goals.PeriodKind(period.Kind)).Adjective()
+	}
+	start, errStart := time.Parse("2006-01-02", period.Start)
+	last, errEnd := time.Parse("2006-01-02", period.End)
+	if errStart != nil || errEnd != nil {
+		return period.Start + " – " + period.End
+	}
+	return goals.Range(start, last.AddDate(0, 0, 1)).Adjective()
+}
+
+// FormatMetricValue renders a metric value with its unit
+func FormatMetricValue(metric goals.Metric, value float64) string {
+	switch metric {
+	case goals.MetricMovingTime:
+		hours := int(value)
+		minutes := int((value - float64(hours)) * 60)
 		if hours > 0 {
-			fmt.Printf("      💭 Still need: %dh %dm to complete your weekly goal\n", hours, minutes)
-		} else {
-			fmt.Printf("      💭 Still need: %dm to complete your weekly goal\n", minutes)
+			return fmt.Sprintf("%dh %dm", hours, minutes)
 		}
-	} else {
-		excess := progress.WorkoutHours - progress.Goals.WorkoutGoalHours
-		fmt.Printf("      🎉 Goal achieved! You've exceeded by %.1f hours\n", excess)
-	}
-<<<<<<< Updated upstream
-	
-	// Weekly activity summary
-	fmt.Printf("\n   📊 This Week Summary:\n")
-	fmt.Printf("      🏃 Runs: %d activities\n", progress.RunCount)
-	fmt.Printf("      💪 Workouts: %d activities\n", progress.WorkoutCount)
-	fmt.Printf("      📈 Total: %d activities\n", progress.TotalActivities)
-	
-=======
-
-	// Activity counts for this week
-	fmt.Printf("   📊 This Week: %d runs, %d workouts, %d total activities\n",
-		progress.RunCount, progress.WorkoutCount, progress.TotalActivities)
-
->>>>>>> Stashed changes
-	// Motivational message
-	fmt.Printf("\n   💬 %s\n", progress.GetMotivationalMessage())
+		return fmt.Sprintf("%dm", minutes)
+	case goals.MetricDistance:
+		return fmt.Sprintf("%.1f %s", value, metric.Unit())
+	case goals.MetricZoneShare:
+		return fmt.Sprintf("%.0f%%", value)
+	default:
+		return fmt.Sprintf("%.0f %s", value, metric.Unit())
+	}
 }
 
-// getProgressDisplay returns a progress bar and status emoji based on percentage
-func getProgressDisplay(percent float64) (string, string) {
+// FormatTarget renders a goal's target, or its range when it has a max,
+// e.g. "400-500 TRIMP"
+func FormatTarget(metric goals.Metric, target, max float64) string {
+	if max == 0 {
+		return FormatMetricValue(metric, target)
+	}
+	low := strings.TrimSuffix(FormatMetricValue(metric, target), " "+metric.Unit())
+	if metric == goals.MetricZoneShare {
+		low = strings.TrimSuffix(low, "%")
+	}
+	return low + "-" + FormatMetricValue(metric, max)
+}
+
+// FormatMinutes renders a duration in minutes, e.g. "1h 20m"
+func FormatMinutes(minutes float64) string {
+	rounded := int(math.Round(minutes))
+	if rounded >= 60 {
+		return fmt.Sprintf("%dh %dm", rounded/60, rounded%60)
+	}
+	return fmt.Sprintf("%dm", rounded)
+}
+
+// ProjectionLabel describes a projection status for people
+func ProjectionLabel(status string) string {
+	switch goals.ProjectionStatus(status) {
+	case goals.StatusAchieved:
+		return "achieved"
+	case goals.StatusAhead:
+		return "ahead of target"
+	case goals.StatusOnTrack:
+		return "on track"
+	case goals.StatusBehind:
+		return "behind target"
+	case goals.StatusOver:
+		return "over the range"
+	default:
+		return status
+	}
+}
+
+// projectionEmoji returns an emoji for a projection status
+func projectionEmoji(status string) string {
+	switch goals.ProjectionStatus(status) {
+	case goals.StatusAhead:
+		return "🚀"
+	case goals.StatusOnTrack:
+		return "👍"
+	case goals.StatusBehind, goals.StatusOver:
+		return "⚠️"
+	default:
+		return "🔮"
+	}
+}
+
+// FormLabel describes a form zone for people
+func FormLabel(zone string) string {
+	switch goals.FormZone(zone) {
+	case goals.ZoneTransition:
+		return "very fresh, fitness is fading"
+	case goals.ZoneFresh:
+		return "fresh, ready to race"
+	case goals.ZoneNeutral:
+		return "neutral"
+	case goals.ZoneOptimal:
+		return "optimal training"
+	case goals.ZoneOverreaching:
+		return "overreaching, consider easing off"
+	default:
+		return zone
+	}
+}
+
+// formEmoji returns an emoji for a form zone
+func formEmoji(zone string) string {
+	switch goals.FormZone(zone) {
+	case goals.ZoneTransition, goals.ZoneFresh:
+		return "🟢"
+	case goals.ZoneOptimal:
+		return "🟡"
+	case goals.ZoneOverreaching:
+		return "🔴"
+	default:
+		return "⚪"
+	}
+}
+
+// metricEmoji returns an emoji representing a goal metric
+func metricEmoji(metric goals.Metric) string {
+	switch metric {
+	case goals.MetricDistance:
+		return "🏃‍♂️"
+	case goals.MetricMovingTime:
+		return "💪"
+	case goals.MetricElevation:
+		return "⛰️"
+	case goals.MetricCount:
+		return "📊"
+	case goals.MetricKudos:
+		return "👍"
+	case goals.MetricLoad:
+		return "🔋"
+	case goals.MetricZoneTime, goals.MetricZoneShare:
+		return "❤️"
+	default:
+		return "🎯"
+	}
+}
+
+// zoneEmoji returns an emoji for a zone type
+func zoneEmoji(kind string) string {
+	if goals.ZoneKind(kind) == goals.ZoneKindPower {
+		return "⚡"
+	}
+	return "❤️"
+}
+
+// getProgressDisplay returns a status label and progress bar based on percentage
+func getProgressDisplay(percent float64, ascii bool) (string, string) {
 	var status string
-	var bar string
-	
-	// Determine status emoji
+	var icon string
+
+	// Determine status
 	if percent >= 100 {
-		status = "✅ COMPLETED"
+		icon, status = "✅", "COMPLETED"
 	} else if percent >= 75 {
-		status = "🟡 ALMOST THERE"
+		icon, status = "🟡", "ALMOST THERE"
 	} else if percent >= 50 {
-		status = "🟠 HALFWAY"
+		icon, status = "🟠", "HALFWAY"
 	} else if percent >= 25 {
-		status = "🔵 GETTING STARTED"
+		icon, status = "🔵", "GETTING STARTED"
+	} else {
+		icon, status = "🔴", "JUST STARTED"
+	}
+
+	filledChar, emptyChar := "█", "░"
+	if ascii {
+		filledChar, emptyChar = "#", "-"
 	} else {
-		status = "🔴 JUST STARTED"
+		status = icon + " " + status
 	}
-	
+
 	// Create progress bar (20 characters wide)
 	filled := int(percent / 5) // Each character represents 5%
 	if filled > 20 {
 		filled = 20
 	}
-	
-	bar = "["
-	for i := 0; i < 20; i++ {
-		if i < filled {
-			bar += "█"
-		} else {
-			bar += "░"
-		}
+	if filled < 0 {
+		filled = 0
 	}
-	bar += "]"
-	
+
+	bar := "[" + strings.Repeat(filledChar, filled) + strings.Repeat(emptyChar, 20-filled) + "]"
 	return status, bar
 }
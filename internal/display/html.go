@@ -0,0 +1,118 @@
+package display
+
+import (
+	"fmt"
+	"html/template"
+	"io"
+	"math"
+
+	"strava-custom-goals/internal/goals"
+	"strava-custom-goals/internal/models"
+	"strava-custom-goals/internal/report"
+)
+
+// HTMLRenderer writes the report as a self-contained HTML page
+type HTMLRenderer struct {
+	Summary bool
+}
+
+// Render implements Renderer
+func (h *HTMLRenderer) Render(w io.Writer, r *report.Report) error {
+	return htmlTemplate.Execute(w, struct {
+		*report.Report
+		ShowSummary bool
+	}{r, h.Summary})
+}
+
+// htmlFuncs are the helpers available to the HTML template
+var htmlFuncs = template.FuncMap{
+	"metric": func(name string, value float64) string {
+		return FormatMetricValue(goals.Metric(name), value)
+	},
+	"status": func(percent float64) string {
+		status, _ := getProgressDisplay(percent, true)
+		return status
+	},
+	"width": func(percent float64) string {
+		return fmt.Sprintf("%.0f%%", math.Max(0, math.Min(percent, 100)))
+	},
+	"target": func(goal report.GoalProgress) string {
+		return FormatTarget(goals.Metric(goal.Metric), goal.Target, goal.Max)
+	},
+	"minutes":    FormatMinutes,
+	"projection": ProjectionLabel,
+	"form":       FormLabel,
+	"duration":   models.FormatDuration,
+	"date":       models.FormatDate,
+}
+
+var htmlTemplate = template.Must(template.New("report").Funcs(htmlFuncs).Parse(`<!DOCTYPE html>
+<html lang="en">
+<head>
+<meta charset="utf-8">
+<title>Strava Goals Report</title>
+<style>
+body { font-family: system-ui, sans-serif; max-width: 60rem; margin: 2rem auto; padding: 0 1rem; color: #222; }
+h1 { color: #fc4c02; }
+.goal { margin-bottom: 1.25rem; }
+.bar { background: #eee; border-radius: 4px; height: 1rem; overflow: hidden; }
+.fill { background: #fc4c02; height: 100%; }
+.achieved .fill { background: #2e9e44; }
+.zones { margin-top: 0.5rem; font-size: 0.85rem; }
+.zone { display: grid; grid-template-columns: 2rem 1fr 6.5rem; gap: 0.5rem; align-items: center; }
+.zone .bar { height: 0.5rem; margin-top: 0; }
+.zone .fill { background: #bbb; }
+.zone.counted .fill { background: #fc4c02; }
+table { border-collapse: collapse; width: 100%; }
+th, td { text-align: left; padding: 0.3rem 0.5rem; border-bottom: 1px solid #ddd; }
+.muted { color: #777; }
+.streaks { list-style: none; padding: 0; }
+.at-risk { color: #c0392b; }
+</style>
+</head>
+<body>
+<h1>Strava Goals Report</h1>
+<p class="muted">As of {{.AsOf.Format "Jan 2, 2006 15:04"}}</p>
+
+<h2>Goals</h2>
+{{range $goal := .Goals}}<div class="goal{{if .Achieved}} achieved{{end}}">
+<strong>{{.Name}}</strong> <span class="muted">{{.Period.Start}} – {{.Period.End}}</span><br>
+{{metric .Metric .Actual}} / {{target $goal}} ({{printf "%.1f" .Percent}}%) · {{status .Percent}}
+<div class="bar"><div class="fill" style="width: {{width .Percent}}"></div></div>
+{{with .Zones}}<div class="zones"><span class="muted">Time in zones, {{.Range}} counted</span>
+{{range .Zones}}<div class="zone{{if .InGoal}} counted{{end}}"><span>Z{{.Zone}}</span><div class="bar"><div class="fill" style="width: {{width .Percent}}"></div></div><span class="muted">{{printf "%.0f" .Percent}}% · {{minutes .Minutes}}</span></div>
+{{end}}</div>
+{{end}}{{with .Projection}}{{if not $goal.Achieved}}<p class="muted">Projected {{metric $goal.Metric .Projected}} by period end, {{projection .Status}}{{if .RequiredPerDay}} · need {{metric $goal.Metric .RequiredPerDay}}/day for {{.DaysRemaining}} days{{end}}</p>
+{{end}}{{end}}</div>
+{{else}}<p class="muted">No goals configured.</p>
+{{end}}<p>{{.Message}}</p>
+{{if .Streaks}}<ul class="streaks">
+{{range .Streaks}}<li{{if .AtRisk}} class="at-risk"{{end}}>{{.Message}}</li>
+{{end}}</ul>
+{{end}}{{with .TrainingLoad}}
+<h2>Training Load</h2>
+<ul>
+<li>Fitness (CTL): {{printf "%.1f" .Fitness}}</li>
+<li>Fatigue (ATL): {{printf "%.1f" .Fatigue}}</li>
+<li>Form (TSB): {{printf "%+.1f" .Form}}, {{form .FormZone}}</li>
+<li>Last 7 days: {{metric "load" .WeekLoad}}</li>
+</ul>
+{{if .Estimated}}<p class="muted">Activities without heart rate, load estimated: {{.Estimated}}</p>
+{{end}}{{end}}{{if .Activities}}
+<h2>Activities</h2>
+<table>
+<tr><th>Date</th><th>Name</th><th>Type</th><th>Distance</th><th>Moving Time</th><th>Pace</th><th>Elevation</th></tr>
+{{range .Activities}}<tr><td>{{date .StartDateLocal}}</td><td>{{.Name}}</td><td>{{.Type}}</td><td>{{printf "%.2f" .DistanceKm}} km</td><td>{{duration .MovingTime}}</td><td>{{.PaceMinPerKm}}</td><td>{{printf "%.0f" .TotalElevationGain}} m</td></tr>
+{{end}}</table>
+{{end}}{{if .ShowSummary}}
+<h2>Summary</h2>
+<ul>
+<li>Total activities: {{.Summary.TotalActivities}}</li>
+<li>Runs: {{.Summary.Runs}}</li>
+<li>Rides: {{.Summary.Rides}}</li>
+<li>Total distance: {{printf "%.2f" .Summary.TotalDistanceKm}} km</li>
+<li>Total time: {{duration .Summary.TotalMovingTime}}</li>
+</ul>
+{{end}}</body>
+</html>
+`))
@@ -0,0 +1,126 @@
+package display
+
+import (
+	"fmt"
+	"io"
+
+	"strava-custom-goals/internal/goals"
+	"strava-custom-goals/internal/models"
+	"strava-custom-goals/internal/report"
+)
+
+// MarkdownRenderer writes the report as a Markdown document
+type MarkdownRenderer struct {
+	Summary bool
+}
+
+// Render implements Renderer
+func (m *MarkdownRenderer) Render(w io.Writer, r *report.Report) error {
+	p := &printer{w: w}
+
+	p.printf("# Strava Goals Report\n\n")
+	p.printf("_As of %s_\n\n", r.AsOf.Format("Jan 2, 2006 15:04"))
+
+	p.printf("## Goals\n\n")
+	if len(r.Goals) == 0 {
+		p.printf("_No goals configured._\n\n")
+	} else {
+		rows := make([][]string, 0, len(r.Goals))
+		for _, goal := range r.Goals {
+			metric := goals.Metric(goal.Metric)
+			status, _ := getProgressDisplay(goal.Percent, false)
+			forecast := ""
+			if projection := goal.Projection; projection != nil {
+				forecast = fmt.Sprintf("%s (%s)", FormatMetricValue(metric, projection.Projected), ProjectionLabel(projection.Status))
+			}
+			rows = append(rows, []string{
+				goal.Name,
+				fmt.Sprintf("%s – %s", goal.Period.Start, goal.Period.End),
+				FormatMetricValue(metric, goal.Actual),
+				FormatTarget(metric, goal.Target, goal.Max),
+				fmt.Sprintf("%.1f%%", goal.Percent),
+				status,
+				forecast,
+			})
+		}
+		m.table(p, []string{"Goal", "Period", "Actual", "Target", "Progress", "Status", "Forecast"}, rows)
+		for _, goal := range r.Goals {
+			if goal.Zones != nil {
+				m.zones(p, goal.Name, goal.Zones)
+			}
+		}
+	}
+	p.printf("> %s\n\n", r.Message)
+	for _, streak := range r.Streaks {
+		p.printf("- %s\n", streak.Message)
+	}
+	if len(r.Streaks) > 0 {
+		p.printf("\n")
+	}
+
+	if load := r.TrainingLoad; load != nil {
+		p.printf("## Training Load\n\n")
+		p.printf("- Fitness (CTL): %.1f\n", load.Fitness)
+		p.printf("- Fatigue (ATL): %.1f\n", load.Fatigue)
+		p.printf("- Form (TSB): %+.1f, %s\n", load.Form, FormLabel(load.FormZone))
+		p.printf("- Last 7 days: %s\n", FormatMetricValue(goals.MetricLoad, load.WeekLoad))
+		if load.Estimated > 0 {
+			p.printf("\n_Activities without heart rate, load estimated: %d_\n", load.Estimated)
+		}
+		p.printf("\n")
+	}
+
+	if len(r.Activities) > 0 {
+		p.printf("## Activities\n\n")
+		rows := make([][]string, 0, len(r.Activities))
+		for _, activity := range r.Activities {
+			rows = append(rows, []string{
+				models.FormatDate(activity.StartDateLocal),
+				activity.Name,
+				activity.Type,
+				fmt.Sprintf("%.2f km", activity.DistanceKm),
+				models.FormatDuration(activity.MovingTime),
+				activity.PaceMinPerKm,
+				fmt.Sprintf("%.0f m", activity.TotalElevationGain),
+			})
+		}
+		m.table(p, []string{"Date", "Name", "Type", "Distance", "Moving Time", "Pace", "Elevation"}, rows)
+	}
+
+	if m.Summary {
+		s := r.Summary
+		p.printf("## Summary\n\n")
+		p.printf("- Total activities: %d\n", s.TotalActivities)
+		p.printf("- Runs: %d\n", s.Runs)
+		p.printf("- Rides: %d\n", s.Rides)
+		p.printf("- Total distance: %.2f km\n", s.TotalDistanceKm)
+		p.printf("- Total time: %s\n", models.FormatDuration(s.TotalMovingTime))
+		if s.TotalDistanceKm > 0 {
+			p.printf("- Average distance: %.2f km\n", s.AverageDistanceKm)
+		}
+	}
+	return p.err
+}
+
+// zones lists a zone goal's time per zone, with the zones it counts in bold
+func (m *MarkdownRenderer) zones(p *printer, name string, zones *report.ZoneDistribution) {
+	p.printf("**%s** time in zones, %s counted:\n\n", name, zones.Range)
+	for _, zone := range zones.Zones {
+		line := fmt.Sprintf("Z%d: %s (%.0f%%)", zone.Zone, FormatMinutes(zone.Minutes), zone.Percent)
+		if zone.InGoal {
+			line = "**" + line + "**"
+		}
+		p.printf("- %s\n", line)
+	}
+	p.printf("\n")
+}
+
+// table writes a Markdown table followed by a blank line
+func (m *MarkdownRenderer) table(p *printer, header []string, rows [][]string) {
+	if p.err != nil {
+		return
+	}
+	if p.err = report.WriteMarkdownTable(p.w, header, rows); p.err == nil {
+		p.printf("\n")
+	}
+}
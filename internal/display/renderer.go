@@ -0,0 +1,49 @@
+package display
+
+import (
+	"fmt"
+	"io"
+	"strings"
+
+	"strava-custom-goals/internal/report"
+)
+
+// Renderer writes a report in one output format
+type Renderer interface {
+	Render(w io.Writer, r *report.Report) error
+}
+
+// Options selects the optional sections of a report. Activities are
+// controlled by the report itself; JSON always includes every section.
+type Options struct {
+	Summary bool // include the activity summary
+}
+
+// Formats lists the supported output formats
+var Formats = []string{"text", "plain", "json", "markdown", "html"}
+
+// NewRenderer returns the renderer for a format name
+func NewRenderer(format string, opts Options) (Renderer, error) {
+	switch format {
+	case "text":
+		return &TextRenderer{Summary: opts.Summary}, nil
+	case "plain":
+		return &TextRenderer{Summary: opts.Summary, ASCII: true}, nil
+	case "json":
+		return JSONRenderer{}, nil
+	case "markdown", "md":
+		return &MarkdownRenderer{Summary: opts.Summary}, nil
+	case "html":
+		return &HTMLRenderer{Summary: opts.Summary}, nil
+	default:
+		return nil, fmt.Errorf("unknown output format %q (expected %s)", format, strings.Join(Formats, ", "))
+	}
+}
+
+// JSONRenderer writes the versioned JSON schema
+type JSONRenderer struct{}
+
+// Render implements Renderer
+func (JSONRenderer) Render(w io.Writer, r *report.Report) error {
+	return report.WriteJSON(w, r)
+}
@@ -0,0 +1,142 @@
+package display
+
+import (
+	"bytes"
+	"flag"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"strava-custom-goals/internal/goals"
+	"strava-custom-goals/internal/models"
+	"strava-custom-goals/internal/report"
+)
+
+var update = flag.Bool("update", false, "rewrite golden files in testdata")
+
+// testReport builds a report with fixed timestamps so output is reproducible
+func testReport() *report.Report {
+	week := goals.Every(goals.PeriodWeek)
+	weekStart := time.Date(2025, 10, 6, 0, 0, 0, 0, time.UTC)
+	weekEnd := weekStart.AddDate(0, 0, 7)
+	results := []goals.Result{
+		{Goal: goals.Goal{Name: "Running", Metric: goals.MetricDistance, ActivityTypes: []string{"Run"}, Period: week, Target: 20},
+			Actual: 13, Activities: 2, PeriodStart: weekStart, PeriodEnd: weekEnd},
+		{Goal: goals.Goal{Name: "Workout", Metric: goals.MetricMovingTime, Period: week, Target: 1},
+			Actual: 1.5, Activities: 3, PeriodStart: weekStart, PeriodEnd: weekEnd},
+		{Goal: goals.Goal{Name: "Load", Metric: goals.MetricLoad, Period: week, Target: 400, Max: 500},
+			Actual: 520, Activities: 3, PeriodStart: weekStart, PeriodEnd: weekEnd},
+		{Goal: goals.Goal{Name: "Easy aerobic", Metric: goals.MetricZoneTime, Zones: goals.ZoneRange{Kind: goals.ZoneKindHeartRate, From: 2, To: 2}, Period: week, Target: 150},
+			Actual: 80, Activities: 2, PeriodStart: weekStart, PeriodEnd: weekEnd, Zones: goals.ZoneTime{600, 4800, 0, 600, 0}},
+		{Goal: goals.Goal{Name: "Not too hard", Metric: goals.MetricZoneShare, Zones: goals.ZoneRange{Kind: goals.ZoneKindPower, From: 5}, Period: week, Max: 20},
+			Actual: 25, Activities: 1, PeriodStart: weekStart, PeriodEnd: weekEnd, Zones: goals.ZoneTime{300, 900, 600, 300, 400, 200, 0}},
+	}
+	activities := []models.Activity{
+		{ID: 3, Name: "Tempo | Intervals", Type: "Run", Distance: 8000, MovingTime: 2400, TotalElevGain: 45,
+			HasHeartrate: true, AverageHeartrate: 158, Kudos: 4,
+			StartDate: "2025-10-08T17:00:00Z", StartDateLocal: "2025-10-08T19:00:00Z"},
+		{ID: 2, Name: "Commute <home>", Type: "Ride", Distance: 12000, MovingTime: 1800,
+			StartDate: "2025-10-07T06:30:00Z", StartDateLocal: "2025-10-07T08:30:00Z"},
+		{ID: 1, Name: "Easy Run", Type: "Run", Distance: 5000, MovingTime: 1650,
+			StartDate: "2025-10-06T05:00:00Z", StartDateLocal: "2025-10-06T07:00:00Z"},
+	}
+
+	projections := []goals.Projection{
+		{Elapsed: 0.4, DaysRemaining: 5, PaceTotal: 32.5, PatternTotal: 18, HasPattern: true, Projected: 18, RequiredPerDay: 1.4, Status: goals.StatusBehind},
+		{Elapsed: 0.4, DaysRemaining: 5, PaceTotal: 3.75, Projected: 3.75, Status: goals.StatusAchieved},
+		{Elapsed: 0.4, DaysRemaining: 5, PaceTotal: 1300, Projected: 1300, Status: goals.StatusOver},
+		{Elapsed: 0.4, DaysRemaining: 5, PaceTotal: 200, PatternTotal: 170, HasPattern: true, Projected: 170, RequiredPerDay: 14, Status: goals.StatusOnTrack},
+		{Elapsed: 0.4, DaysRemaining: 5, PaceTotal: 25, Projected: 25, Status: goals.StatusOver},
+	}
+	load := []goals.LoadDay{
+		{Date: time.Date(2025, 10, 7, 0, 0, 0, 0, time.UTC), Load: 60, Activities: 1, Estimated: 1, Fitness: 38.2, Fatigue: 47.5, Form: -6.1},
+		{Date: time.Date(2025, 10, 8, 0, 0, 0, 0, time.UTC), Load: 103, Activities: 1, Fitness: 39.8, Fatigue: 55.4, Form: -9.3},
+	}
+	streaks := []goals.Streak{
+		{Name: "Running", Period: goals.PeriodWeek, Current: 3, Longest: 5, AtRisk: true,
+			LongestStart: time.Date(2025, 6, 2, 0, 0, 0, 0, time.UTC), LongestEnd: time.Date(2025, 7, 7, 0, 0, 0, 0, time.UTC)},
+		{Name: goals.ActiveDaysStreakName, Period: goals.PeriodDay, Current: 3, Longest: 3,
+			LongestStart: time.Date(2025, 10, 6, 0, 0, 0, 0, time.UTC), LongestEnd: time.Date(2025, 10, 9, 0, 0, 0, 0, time.UTC)},
+	}
+
+	r := report.Build(results, activities, report.Options{Profile: "default", AsOf: time.Date(2025, 10, 8, 21, 0, 0, 0, time.UTC), Streaks: streaks, Projections: projections, TrainingLoad: load})
+	r.GeneratedAt = time.Date(2025, 10, 8, 21, 0, 5, 0, time.UTC)
+	return r
+}
+
+func TestRenderersMatchGoldenFiles(t *testing.T) {
+	for _, format := range Formats {
+		t.Run(format, func(t *testing.T) {
+			renderer, err := NewRenderer(format, Options{Summary: true})
+			if err != nil {
+				t.Fatalf("NewRenderer(%q) failed: %v", format, err)
+			}
+
+			var buf bytes.Buffer
+			if err := renderer.Render(&buf, testReport()); err != nil {
+				t.Fatalf("Render failed: %v", err)
+			}
+
+			golden := filepath.Join("testdata", "report."+format+".golden")
+			if *update {
+				if err := os.WriteFile(golden, buf.Bytes(), 0644); err != nil {
+					t.Fatalf("Failed to update golden file: %v", err)
+				}
+			}
+
+			expected, err := os.ReadFile(golden)
+			if err != nil {
+				t.Fatalf("Failed to read golden file (run go test -update): %v", err)
+			}
+			if !bytes.Equal(buf.Bytes(), expected) {
+				t.Errorf("Output differs from %s (run go test -update to accept):\n%s", golden, buf.String())
+			}
+		})
+	}
+}
+
+func TestPlainRendererIsASCII(t *testing.T) {
+	var buf bytes.Buffer
+	renderer := &TextRenderer{ASCII: true, Summary: true}
+	if err := renderer.Render(&buf, testReport()); err != nil {
+		t.Fatalf("Render failed: %v", err)
+	}
+
+	for i, r := range buf.String() {
+		if r > 127 {
+			t.Fatalf("Expected ASCII output, found %q at byte %d", r, i)
+		}
+	}
+}
+
+func TestRendererOmitsOptionalSections(t *testing.T) {
+	r := testReport()
+	r.Activities = []report.Activity{}
+
+	var buf bytes.Buffer
+	if err := (&TextRenderer{}).Render(&buf, r); err != nil {
+		t.Fatalf("Render failed: %v", err)
+	}
+	if bytes.Contains(buf.Bytes(), []byte("RECENT ACTIVITIES")) || bytes.Contains(buf.Bytes(), []byte("ACTIVITY SUMMARY")) {
+		t.Errorf("Expected only the goals section, got:\n%s", buf.String())
+	}
+}
+
+func TestNewRendererRejectsUnknownFormat(t *testing.T) {
+	if _, err := NewRenderer("yaml", Options{}); err == nil {
+		t.Error("Expected an error for an unknown format")
+	}
+}
+
+func TestGetProgressDisplay(t *testing.T) {
+	status, bar := getProgressDisplay(50, true)
+	if status != "HALFWAY" || bar != "[##########----------]" {
+		t.Errorf("Expected HALFWAY [##########----------], got %s %s", status, bar)
+	}
+
+	status, bar = getProgressDisplay(150, false)
+	if status != "✅ COMPLETED" || bar != "[████████████████████]" {
+		t.Errorf("Expected a full completed bar, got %s %s", status, bar)
+	}
+}
@@ -0,0 +1,64 @@
+package goals
+
+import (
+	"time"
+
+	"strava-custom-goals/internal/models"
+)
+
+// Calendar decides which period an activity falls into.
+//
+// Periods are computed on wall-clock ("floating") time: both activities and
+// the evaluation instant are reduced to their local date and clock reading,
+// represented in UTC. This keeps day boundaries exact across DST changes and
+// lets a Sunday-evening run abroad count toward the week it was run in.
+type Calendar struct {
+	// Location is the athlete's home timezone. When nil, each activity is
+	// bucketed by its own start_date_local, i.e. the wall clock where it happened.
+	Location *time.Location
+	// WeekStart is the first day of weekly periods
+	WeekStart time.Weekday
+}
+
+// DefaultCalendar buckets by start_date_local with Monday-start weeks
+func DefaultCalendar() Calendar {
+	return Calendar{WeekStart: time.Monday}
+}
+
+// Now converts an instant to the calendar's wall-clock time
+func (c Calendar) Now(now time.Time) time.Time {
+	if c.Location != nil {
+		now = now.In(c.Location)
+	}
+	return floating(now)
+}
+
+// ActivityTime returns the wall-clock start time used for bucketing an activity
+func (c Calendar) ActivityTime(a models.Activity) (time.Time, error) {
+	if c.Location == nil && a.StartDateLocal != "" {
+		// Strava reports local wall-clock time with a (meaningless) Z suffix
+		local, err := time.Parse(time.RFC3339, a.StartDateLocal)
+		if err == nil {
+			return floating(local), nil
+		}
+	}
+
+	start, err := a.StartTime()
+	if err != nil {
+		return time.Time{}, err
+	}
+	if c.Location != nil {
+		return floating(start.In(c.Location)), nil
+	}
+	return floating(start.In(time.Local)), nil
+}
+
+// Window returns the wall-clock [start, end) window of the period containing now
+func (c Calendar) Window(p Period, now time.Time) (time.Time, time.Time) {
+	return p.Window(c.Now(now), c.WeekStart)
+}
+
+// floating keeps t's wall-clock reading but discards its zone
+func floating(t time.Time) time.Time {
+	return time.Date(t.Year(), t.Month(), t.Day(), t.Hour(), t.Minute(), t.Second(), t.Nanosecond(), time.UTC)
+}
@@ -0,0 +1,111 @@
+package goals
+
+import (
+	"testing"
+	"time"
+
+	"strava-custom-goals/internal/models"
+)
+
+func loadLocation(t *testing.T, name string) *time.Location {
+	t.Helper()
+	loc, err := time.LoadLocation(name)
+	if err != nil {
+		t.Skipf("timezone data for %s unavailable: %v", name, err)
+	}
+	return loc
+}
+
+// weeklyRunKm evaluates a single weekly distance goal
+func weeklyRunKm(calendar Calendar, activities []models.Activity, now time.Time) Result {
+	engine := NewEngine([]Goal{{Name: "Run", Metric: MetricDistance, Period: Every(PeriodWeek), Target: 10}})
+	engine.Calendar = calendar
+	return engine.Evaluate(activities, now)[0]
+}
+
+func TestSundayEveningRunAbroadUsesLocalDate(t *testing.T) {
+	// Sunday 19:00 in Los Angeles is already Monday 02:00 UTC
+	sundayRun := models.Activity{
+		Type:           "Run",
+		Distance:       5000,
+		StartDate:      "2025-10-06T02:00:00Z",
+		StartDateLocal: "2025-10-05T19:00:00Z",
+	}
+	// Evaluated on Sunday evening, still in Los Angeles
+	la := loadLocation(t, "America/Los_Angeles")
+	now := time.Date(2025, 10, 5, 21, 0, 0, 0, la)
+
+	result := weeklyRunKm(DefaultCalendar(), []models.Activity{sundayRun}, now)
+	if result.Actual != 5 {
+		t.Errorf("Expected the Sunday run in the current week, got %.1f km", result.Actual)
+	}
+	if want := time.Date(2025, 9, 29, 0, 0, 0, 0, time.UTC); !result.PeriodStart.Equal(want) {
+		t.Errorf("Expected week starting Monday Sep 29, got %v", result.PeriodStart)
+	}
+}
+
+func TestAthleteTimezoneAcrossDSTTransition(t *testing.T) {
+	berlin := loadLocation(t, "Europe/Berlin")
+	calendar := Calendar{Location: berlin, WeekStart: time.Monday}
+
+	// Clocks go back on Sunday Oct 26, 2025; that week is 169 hours long
+	activities := []models.Activity{
+		{Type: "Run", Distance: 3000, StartDate: "2025-10-19T22:30:00Z"}, // Mon 00:30 CEST, first minutes of the week
+		{Type: "Run", Distance: 4000, StartDate: "2025-10-26T22:30:00Z"}, // Sun 23:30 CET, last minutes of the week
+		{Type: "Run", Distance: 9000, StartDate: "2025-10-26T23:30:00Z"}, // Mon 00:30 CET, next week
+		{Type: "Run", Distance: 8000, StartDate: "2025-10-19T21:30:00Z"}, // Sun 23:30 CEST, previous week
+	}
+	now := time.Date(2025, 10, 26, 23, 45, 0, 0, berlin)
+
+	result := weeklyRunKm(calendar, activities, now)
+	if result.Actual != 7 || result.Activities != 2 {
+		t.Errorf("Expected 7 km over 2 runs, got %.1f km over %d", result.Actual, result.Activities)
+	}
+
+	day := NewEngine([]Goal{{Name: "Day", Metric: MetricDistance, Period: Every(PeriodDay), Target: 1}})
+	day.Calendar = calendar
+	if got := day.Evaluate(activities, now)[0].Actual; got != 4 {
+		t.Errorf("Expected 4 km on the 25-hour DST day, got %.1f", got)
+	}
+}
+
+func TestAthleteTimezoneOverridesTravelLocalTime(t *testing.T) {
+	// Recorded in Tokyo on Monday 07:00 local, which is Sunday 23:00 in London
+	tokyoRun := models.Activity{
+		Type:           "Run",
+		Distance:       6000,
+		StartDate:      "2025-10-05T22:00:00Z",
+		StartDateLocal: "2025-10-06T07:00:00Z",
+	}
+	now := time.Date(2025, 10, 8, 12, 0, 0, 0, time.UTC)
+
+	if got := weeklyRunKm(DefaultCalendar(), []models.Activity{tokyoRun}, now).Actual; got != 6 {
+		t.Errorf("Expected local-date bucketing to count the run this week, got %.1f", got)
+	}
+
+	london := Calendar{Location: loadLocation(t, "Europe/London"), WeekStart: time.Monday}
+	if got := weeklyRunKm(london, []models.Activity{tokyoRun}, now).Actual; got != 0 {
+		t.Errorf("Expected home-timezone bucketing to place the run in last week, got %.1f", got)
+	}
+}
+
+func TestConfigurableWeekStart(t *testing.T) {
+	activities := []models.Activity{
+		{Type: "Run", Distance: 5000, StartDate: "2025-10-05T06:00:00Z", StartDateLocal: "2025-10-05T08:00:00Z"}, // Sunday
+		{Type: "Run", Distance: 7000, StartDate: "2025-10-04T06:00:00Z", StartDateLocal: "2025-10-04T08:00:00Z"}, // Saturday
+	}
+	now := time.Date(2025, 10, 7, 12, 0, 0, 0, time.UTC) // Tuesday
+
+	sundayWeeks := Calendar{WeekStart: time.Sunday}
+	result := weeklyRunKm(sundayWeeks, activities, now)
+	if result.Actual != 5 {
+		t.Errorf("Expected only Sunday's run in a Sunday-start week, got %.1f", result.Actual)
+	}
+	if result.PeriodStart.Weekday() != time.Sunday {
+		t.Errorf("Expected week to start on Sunday, got %v", result.PeriodStart.Weekday())
+	}
+
+	if got := weeklyRunKm(DefaultCalendar(), activities, now).Actual; got != 0 {
+		t.Errorf("Expected neither weekend run in a Monday-start week, got %.1f", got)
+	}
+}
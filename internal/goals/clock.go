@@ -0,0 +1,34 @@
+package goals
+
+import (
+	"time"
+
+	"strava-custom-goals/internal/models"
+)
+
+// Clock supplies the instant goals are evaluated at
+type Clock interface {
+	Now() time.Time
+}
+
+// SystemClock reads the real time
+type SystemClock struct{}
+
+// Now implements Clock
+func (SystemClock) Now() time.Time {
+	return time.Now()
+}
+
+// FixedClock always returns the same instant; use it to evaluate goals as of a past date
+type FixedClock time.Time
+
+// Now implements Clock
+func (c FixedClock) Now() time.Time {
+	return time.Time(c)
+}
+
+// ActivitySource provides stored activities, e.g. the local activity database
+type ActivitySource interface {
+	// ActivitiesSince returns activities that started after t
+	ActivitiesSince(t time.Time) ([]models.Activity, error)
+}
@@ -0,0 +1,55 @@
+package goals
+
+import (
+	"testing"
+	"time"
+
+	"strava-custom-goals/internal/models"
+)
+
+// sliceSource is an in-memory ActivitySource that records the requested lower bound
+type sliceSource struct {
+	activities []models.Activity
+	since      time.Time
+}
+
+func (s *sliceSource) ActivitiesSince(t time.Time) ([]models.Activity, error) {
+	s.since = t
+	var result []models.Activity
+	for _, a := range s.activities {
+		if start, err := a.StartTime(); err == nil && start.After(t) {
+			result = append(result, a)
+		}
+	}
+	return result, nil
+}
+
+func TestEvaluateFromReplaysPastDate(t *testing.T) {
+	source := &sliceSource{activities: []models.Activity{
+		{Type: "Run", Distance: 5000, StartDate: "2025-10-06T07:00:00Z"},  // Monday
+		{Type: "Run", Distance: 8000, StartDate: "2025-10-08T07:00:00Z"},  // Wednesday
+		{Type: "Run", Distance: 12000, StartDate: "2025-10-11T07:00:00Z"}, // Saturday
+	}}
+
+	engine := NewEngine([]Goal{{Name: "Run", Metric: MetricDistance, Period: Every(PeriodMonth), Target: 100}})
+	engine.Calendar = Calendar{Location: time.UTC, WeekStart: time.Monday}
+
+	// As of the end of Wednesday, Saturday's run has not happened yet
+	engine.Clock = FixedClock(time.Date(2025, 10, 8, 23, 59, 59, 0, time.UTC))
+	results, err := engine.EvaluateFrom(source)
+	if err != nil {
+		t.Fatalf("EvaluateFrom returned error: %v", err)
+	}
+	if results[0].Actual != 13 || results[0].Activities != 2 {
+		t.Errorf("Expected 13 km over 2 runs as of Wednesday, got %.1f km over %d", results[0].Actual, results[0].Activities)
+	}
+	if !source.since.Before(time.Date(2025, 10, 1, 0, 0, 0, 0, time.UTC)) {
+		t.Errorf("Expected source to be queried from before the month start, got %v", source.since)
+	}
+
+	engine.Clock = FixedClock(time.Date(2025, 10, 12, 12, 0, 0, 0, time.UTC))
+	results, _ = engine.EvaluateFrom(source)
+	if results[0].Actual != 25 {
+		t.Errorf("Expected 25 km as of Sunday, got %.1f", results[0].Actual)
+	}
+}
@@ -0,0 +1,351 @@
+package goals
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+// FileError is a problem found at a specific line of a goals file
+type FileError struct {
+	File string
+	Line int
+	Msg  string
+}
+
+func (e FileError) Error() string {
+	return fmt.Sprintf("%s:%d: %s", e.File, e.Line, e.Msg)
+}
+
+// FileErrors collects every problem found in a goals file
+type FileErrors []FileError
+
+func (errs FileErrors) Error() string {
+	lines := make([]string, len(errs))
+	for i, err := range errs {
+		lines[i] = err.Error()
+	}
+	return strings.Join(lines, "\n")
+}
+
+// LoadFile reads and strictly validates a goals file
+func LoadFile(path string) ([]Goal, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("read goals file: %w", err)
+	}
+	return Parse(path, data)
+}
+
+// Parse decodes a YAML goals document. name is used in error messages.
+//
+//	goals:
+//	  - name: Weekly running
+//	    metric: distance            # distance, moving_time, elevation, count, kudos, load
+//	    activity_types: [Run]       # optional, all activities when omitted
+//	    split_pace: "5:00"          # optional, only activities with every km split faster
+//	    period: week                # day, week, month, quarter, year
+//	    target: 30
+//	    notes: Base building
+//	  - name: Marathon block
+//	    metric: distance
+//	    period: {start: 2025-07-14, end: 2025-10-05}  # inclusive dates
+//	    target: 600
+//	  - name: Weekly load
+//	    metric: load
+//	    period: week
+//	    target: 400
+//	    max: 500                    # optional upper end of a target range
+//	  - name: Easy aerobic
+//	    metric: zone_time           # minutes in zones; zone_share for percent of zoned time
+//	    zones: 2                    # a zone, a range like 2-3, or 5+ for zone 5 and above
+//	    zone_type: heartrate        # heartrate (default) or power
+//	    period: week
+//	    target: 150
+func Parse(name string, data []byte) ([]Goal, error) {
+	p := &fileParser{file: name}
+
+	var doc yaml.Node
+	decoder := yaml.NewDecoder(bytes.NewReader(data))
+	err := decoder.Decode(&doc)
+	if errors.Is(err, io.EOF) || (err == nil && len(doc.Content) == 0) {
+		return nil, FileErrors{{File: name, Line: 1, Msg: "file is empty"}}
+	}
+	if err != nil {
+		// yaml errors already carry "line N:" prefixes
+		return nil, fmt.Errorf("%s: %w", name, err)
+	}
+
+	root := doc.Content[0]
+	fields := p.mapping(root, "top level", map[string]bool{"goals": true})
+	list, ok := fields["goals"]
+	if !ok {
+		p.errorf(root, "missing required key \"goals\"")
+		return nil, p.errs
+	}
+	if list.Kind != yaml.SequenceNode {
+		p.errorf(list, "\"goals\" must be a list")
+		return nil, p.errs
+	}
+
+	seen := map[string]int{}
+	var goals []Goal
+	for _, item := range list.Content {
+		goal, ok := p.goal(item)
+		if !ok {
+			continue
+		}
+		if line, dup := seen[goal.Name]; dup {
+			p.errorf(item, "duplicate goal name %q (first defined on line %d)", goal.Name, line)
+			continue
+		}
+		seen[goal.Name] = item.Line
+		goals = append(goals, goal)
+	}
+
+	if len(p.errs) > 0 {
+		return nil, p.errs
+	}
+	return goals, nil
+}
+
+// fileParser walks the YAML tree, collecting line-numbered errors
+type fileParser struct {
+	file string
+	errs FileErrors
+}
+
+func (p *fileParser) errorf(node *yaml.Node, format string, args ...interface{}) {
+	p.errs = append(p.errs, FileError{File: p.file, Line: node.Line, Msg: fmt.Sprintf(format, args...)})
+}
+
+// mapping returns a mapping node's values by key, rejecting unknown and repeated keys
+func (p *fileParser) mapping(node *yaml.Node, what string, allowed map[string]bool) map[string]*yaml.Node {
+	fields := map[string]*yaml.Node{}
+	if node.Kind != yaml.MappingNode {
+		p.errorf(node, "%s must be a mapping", what)
+		return fields
+	}
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		key, value := node.Content[i], node.Content[i+1]
+		switch {
+		case !allowed[key.Value]:
+			p.errorf(key, "unknown key %q in %s", key.Value, what)
+		case fields[key.Value] != nil:
+			p.errorf(key, "key %q repeated in %s", key.Value, what)
+		default:
+			fields[key.Value] = value
+		}
+	}
+	return fields
+}
+
+var goalKeys = map[string]bool{
+	"name": true, "metric": true, "activity_types": true, "split_pace": true, "zones": true, "zone_type": true,
+	"period": true, "target": true, "max": true, "notes": true,
+}
+
+// goal decodes and validates a single goal entry
+func (p *fileParser) goal(node *yaml.Node) (Goal, bool) {
+	before := len(p.errs)
+	fields := p.mapping(node, "goal", goalKeys)
+
+	var goal Goal
+	for _, key := range []string{"name", "metric", "period", "target"} {
+		if fields[key] == nil && node.Kind == yaml.MappingNode {
+			p.errorf(node, "goal is missing required key %q", key)
+		}
+	}
+
+	if n := fields["name"]; n != nil {
+		goal.Name = p.scalar(n, "name")
+		if strings.TrimSpace(goal.Name) == "" {
+			p.errorf(n, "goal name must not be empty")
+		}
+	}
+	if n := fields["metric"]; n != nil {
+		goal.Metric = Metric(p.scalar(n, "metric"))
+		if !goal.Metric.Valid() {
+			p.errorf(n, "unknown metric %q (expected one of %s)", goal.Metric, joinMetrics())
+		}
+	}
+	if n := fields["activity_types"]; n != nil {
+		goal.ActivityTypes = p.stringList(n, "activity_types")
+	}
+	if n := fields["split_pace"]; n != nil {
+		goal.SplitPace = p.pace(n, "split_pace")
+	}
+	goal.Zones = p.zones(node, fields, goal.Metric)
+	if n := fields["period"]; n != nil {
+		goal.Period = p.period(n)
+	}
+	if n := fields["target"]; n != nil {
+		goal.Target = p.number(n, "target")
+		if goal.Target < 0 {
+			p.errorf(n, "target must be non-negative, got %v", goal.Target)
+		}
+	}
+	if n := fields["max"]; n != nil {
+		// Zero means no limit, as for goals built in code
+		goal.Max = p.number(n, "max")
+		if goal.Max != 0 && goal.Max < goal.Target {
+			p.errorf(n, "max must not be below the target %v, got %v", goal.Target, goal.Max)
+		}
+	}
+	if n := fields["notes"]; n != nil {
+		goal.Notes = p.scalar(n, "notes")
+	}
+
+	return goal, len(p.errs) == before
+}
+
+// period decodes either a calendar period name or a {start, end} date range
+func (p *fileParser) period(node *yaml.Node) Period {
+	if node.Kind == yaml.ScalarNode {
+		period := Every(PeriodKind(node.Value))
+		if err := period.Validate(); err != nil || period.Kind == PeriodCustom {
+			p.errorf(node, "unknown period %q (expected day, week, month, quarter, year or a start/end range)", node.Value)
+		}
+		return period
+	}
+
+	fields := p.mapping(node, "period", map[string]bool{"start": true, "end": true})
+	start, end := fields["start"], fields["end"]
+	if start == nil || end == nil {
+		p.errorf(node, "custom period needs both start and end dates")
+		return Period{}
+	}
+
+	startDate, ok1 := p.date(start, "start")
+	endDate, ok2 := p.date(end, "end")
+	if !ok1 || !ok2 {
+		return Period{}
+	}
+	if endDate.Before(startDate) {
+		p.errorf(end, "period end %s is before start %s", end.Value, start.Value)
+		return Period{}
+	}
+	// End dates are inclusive in the file
+	return Range(startDate, endDate.AddDate(0, 0, 1))
+}
+
+// zones decodes the zones and zone_type keys, which zone metrics require and
+// other metrics do not accept
+func (p *fileParser) zones(node *yaml.Node, fields map[string]*yaml.Node, metric Metric) ZoneRange {
+	kind := ZoneKindHeartRate
+	if n := fields["zone_type"]; n != nil {
+		kind = ZoneKind(p.scalar(n, "zone_type"))
+		if !kind.Valid() {
+			p.errorf(n, "unknown zone_type %q (expected heartrate or power)", kind)
+		}
+	}
+
+	n := fields["zones"]
+	switch {
+	case n == nil && metric.Zoned():
+		p.errorf(node, "metric %s needs zones, e.g. zones: 2", metric)
+		return ZoneRange{}
+	case n == nil:
+		if fields["zone_type"] != nil {
+			p.errorf(fields["zone_type"], "zone_type needs zones")
+		}
+		return ZoneRange{}
+	case metric.Valid() && !metric.Zoned():
+		p.errorf(n, "zones only apply to the zone_time and zone_share metrics")
+		return ZoneRange{}
+	}
+
+	value := p.scalar(n, "zones")
+	if n.Kind != yaml.ScalarNode || !kind.Valid() {
+		return ZoneRange{}
+	}
+	zones, err := ParseZoneRange(kind, value)
+	if err != nil {
+		p.errorf(n, "%v", err)
+	}
+	return zones
+}
+
+func (p *fileParser) scalar(node *yaml.Node, what string) string {
+	if node.Kind != yaml.ScalarNode {
+		p.errorf(node, "%s must be a single value", what)
+		return ""
+	}
+	return node.Value
+}
+
+func (p *fileParser) number(node *yaml.Node, what string) float64 {
+	value := p.scalar(node, what)
+	if node.Kind != yaml.ScalarNode {
+		return 0
+	}
+	n, err := strconv.ParseFloat(value, 64)
+	if err != nil || math.IsNaN(n) || math.IsInf(n, 0) {
+		p.errorf(node, "%s must be a number, got %q", what, value)
+		return 0
+	}
+	return n
+}
+
+// pace parses a pace per kilometre such as "4:45" into seconds
+func (p *fileParser) pace(node *yaml.Node, what string) int {
+	value := p.scalar(node, what)
+	if node.Kind != yaml.ScalarNode {
+		return 0
+	}
+	m, s, ok := strings.Cut(value, ":")
+	minutes, errMinutes := strconv.Atoi(m)
+	seconds, errSeconds := strconv.Atoi(s)
+	if !ok || len(s) != 2 || errMinutes != nil || errSeconds != nil ||
+		minutes < 0 || seconds < 0 || seconds >= 60 || minutes*60+seconds == 0 {
+		p.errorf(node, "%s must be a pace per km like 4:45, got %q", what, value)
+		return 0
+	}
+	return minutes*60 + seconds
+}
+
+func (p *fileParser) date(node *yaml.Node, what string) (time.Time, bool) {
+	value := p.scalar(node, what)
+	if node.Kind != yaml.ScalarNode {
+		return time.Time{}, false
+	}
+	// Dates are wall-clock calendar days, see Calendar
+	t, err := time.Parse("2006-01-02", value)
+	if err != nil {
+		p.errorf(node, "%s must be a date like 2025-07-14, got %q", what, value)
+		return time.Time{}, false
+	}
+	return t, true
+}
+
+func (p *fileParser) stringList(node *yaml.Node, what string) []string {
+	if node.Kind == yaml.ScalarNode {
+		return []string{node.Value}
+	}
+	if node.Kind != yaml.SequenceNode {
+		p.errorf(node, "%s must be a list", what)
+		return nil
+	}
+	values := make([]string, 0, len(node.Content))
+	for _, item := range node.Content {
+		values = append(values, p.scalar(item, what))
+	}
+	return values
+}
+
+// joinMetrics lists the supported metric names for error messages
+func joinMetrics() string {
+	names := make([]string, len(Metrics))
+	for i, m := range Metrics {
+		names[i] = string(m)
+	}
+	return strings.Join(names, ", ")
+}
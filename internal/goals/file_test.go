@@ -0,0 +1,197 @@
+package goals
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestParseGoalsFile(t *testing.T) {
+	data := `
+goals:
+  - name: Weekly running
+    metric: distance
+    activity_types: [Run, TrailRun]
+    period: week
+    target: 30
+    notes: Base building
+  - name: Gym
+    metric: moving_time
+    activity_types: WeightTraining
+    period: month
+    target: 12.5
+  - name: Marathon block
+    metric: distance
+    period: {start: 2025-07-14, end: 2025-10-05}
+    target: 600
+  - name: Weekly load
+    metric: load
+    period: week
+    target: 400
+    max: 500
+  - name: Fast runs
+    metric: count
+    activity_types: [Run]
+    split_pace: "5:00"
+    period: month
+    target: 3
+  - name: Easy aerobic
+    metric: zone_time
+    zones: 2
+    period: week
+    target: 150
+  - name: Not too hard
+    metric: zone_share
+    zones: 5+
+    zone_type: power
+    period: week
+    target: 0
+    max: 20
+`
+	goals, err := Parse("goals.yaml", []byte(data))
+	if err != nil {
+		t.Fatalf("Parse returned error: %v", err)
+	}
+	if len(goals) != 7 {
+		t.Fatalf("Expected 7 goals, got %d", len(goals))
+	}
+
+	running := goals[0]
+	if running.Metric != MetricDistance || running.Target != 30 || running.Period.Kind != PeriodWeek ||
+		len(running.ActivityTypes) != 2 || running.Notes != "Base building" {
+		t.Errorf("Unexpected running goal %+v", running)
+	}
+	if gym := goals[1]; gym.Target != 12.5 || len(gym.ActivityTypes) != 1 || gym.ActivityTypes[0] != "WeightTraining" {
+		t.Errorf("Unexpected gym goal %+v", gym)
+	}
+
+	block := goals[2].Period
+	if block.Kind != PeriodCustom ||
+		!block.Start.Equal(time.Date(2025, 7, 14, 0, 0, 0, 0, time.UTC)) ||
+		!block.End.Equal(time.Date(2025, 10, 6, 0, 0, 0, 0, time.UTC)) {
+		t.Errorf("Expected inclusive block ending Oct 5, got %+v", block)
+	}
+
+	if load := goals[3]; load.Metric != MetricLoad || load.Target != 400 || load.Max != 500 {
+		t.Errorf("Unexpected load goal %+v", load)
+	}
+	if fast := goals[4]; fast.SplitPace != 300 {
+		t.Errorf("Expected a 5:00 split pace as 300 s, got %d", fast.SplitPace)
+	}
+	if aerobic := goals[5]; aerobic.Zones != (ZoneRange{Kind: ZoneKindHeartRate, From: 2, To: 2}) {
+		t.Errorf("Expected heart rate zone 2, got %+v", aerobic.Zones)
+	}
+	if hard := goals[6]; hard.Zones != (ZoneRange{Kind: ZoneKindPower, From: 5}) || hard.Max != 20 {
+		t.Errorf("Expected power zones 5 and up with a max of 20, got %+v", hard)
+	}
+
+	for _, g := range goals {
+		if err := g.Validate(); err != nil {
+			t.Errorf("Parsed goal failed validation: %v", err)
+		}
+	}
+}
+
+func TestParseGoalsFileReportsLineNumbers(t *testing.T) {
+	data := `goals:
+  - name: Running
+    metric: pace
+    period: week
+    target: ten
+  - name: Running
+    metric: count
+    period: fortnight
+    target: 3
+    colour: blue
+  - metric: distance
+    period: {start: 2025-10-05, end: 2025-07-14}
+    target: -5
+`
+	_, err := Parse("goals.yaml", []byte(data))
+
+	var fileErrs FileErrors
+	if !errors.As(err, &fileErrs) {
+		t.Fatalf("Expected FileErrors, got %v", err)
+	}
+
+	expected := []string{
+		`goals.yaml:3: unknown metric "pace"`,
+		`goals.yaml:5: target must be a number, got "ten"`,
+		`goals.yaml:10: unknown key "colour" in goal`,
+		`goals.yaml:8: unknown period "fortnight"`,
+		`goals.yaml:11: goal is missing required key "name"`,
+		`goals.yaml:12: period end 2025-07-14 is before start 2025-10-05`,
+		`goals.yaml:13: target must be non-negative`,
+	}
+	message := err.Error()
+	for _, want := range expected {
+		if !strings.Contains(message, want) {
+			t.Errorf("Expected error containing %q, got:\n%s", want, message)
+		}
+	}
+}
+
+func TestParseGoalsFileRejectsMalformedYAML(t *testing.T) {
+	testCases := map[string]string{
+		"empty":        "",
+		"no goals key": "targets: []\n",
+		"not a list":   "goals: 3\n",
+		"bad syntax":   "goals:\n  - name: [unclosed\n",
+		"duplicate":    "goals:\n  - {name: A, metric: count, period: day, target: 1}\n  - {name: A, metric: count, period: day, target: 2}\n",
+		"no zones":     "goals:\n  - {name: A, metric: zone_time, period: week, target: 150}\n",
+		"stray zones":  "goals:\n  - {name: A, metric: distance, zones: 2, period: week, target: 30}\n",
+		"bad zones":    "goals:\n  - {name: A, metric: zone_time, zones: 3-2, period: week, target: 30}\n",
+		"bad type":     "goals:\n  - {name: A, metric: zone_time, zones: 2, zone_type: pace, period: week, target: 30}\n",
+	}
+
+	for name, data := range testCases {
+		if _, err := Parse("goals.yaml", []byte(data)); err == nil {
+			t.Errorf("%s: expected error, got nil", name)
+		}
+	}
+}
+
+func TestParseGoalsFileRejectsEmptyFilesAndNonFiniteNumbers(t *testing.T) {
+	for _, data := range []string{"", "\n# no goals yet\n"} {
+		_, err := Parse("goals.yaml", []byte(data))
+		if err == nil || err.Error() != "goals.yaml:1: file is empty" {
+			t.Errorf("%q: expected an empty file error, got %v", data, err)
+		}
+	}
+
+	data := `goals:
+  - name: Distance
+    metric: distance
+    period: week
+    target: NaN
+  - name: Climbing
+    metric: elevation
+    period: week
+    target: 100
+    max: Inf
+`
+	_, err := Parse("goals.yaml", []byte(data))
+	for _, want := range []string{
+		`goals.yaml:5: target must be a number, got "NaN"`,
+		`goals.yaml:10: max must be a number, got "Inf"`,
+	} {
+		if err == nil || !strings.Contains(err.Error(), want) {
+			t.Errorf("Expected error containing %q, got %v", want, err)
+		}
+	}
+}
+
+func TestParseGoalsFileTreatsZeroMaxAsNoLimit(t *testing.T) {
+	data := "goals:\n  - {name: Rides, metric: count, period: week, target: 3, max: 0}\n" +
+		"  - {name: Runs, metric: count, period: week, target: 3, max: -1}\n"
+	_, err := Parse("goals.yaml", []byte(data))
+	if err == nil || strings.Contains(err.Error(), "goals.yaml:2") || !strings.Contains(err.Error(), "goals.yaml:3: max must not be below the target") {
+		t.Errorf("Expected only the negative max to be rejected, got %v", err)
+	}
+
+	parsed, err := Parse("goals.yaml", []byte("goals:\n  - {name: Rides, metric: count, period: week, target: 3, max: 0}\n"))
+	if err != nil || len(parsed) != 1 || parsed[0].Max != 0 || !parsed[0].Reached(10) {
+		t.Errorf("Expected max: 0 to mean no limit, got %+v, %v", parsed, err)
+	}
+}
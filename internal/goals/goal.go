@@ -0,0 +1,404 @@
+package goals
+
+import (
+	"fmt"
+	"math"
+	"time"
+
+	"strava-custom-goals/internal/models"
+)
+
+// Metric identifies what a goal measures
+type Metric string
+
+// Supported goal metrics
+const (
+	MetricDistance   Metric = "distance"    // kilometres
+	MetricMovingTime Metric = "moving_time" // hours
+	MetricElevation  Metric = "elevation"   // metres climbed
+	MetricCount      Metric = "count"       // number of activities
+	MetricKudos      Metric = "kudos"       // kudos received
+	MetricLoad       Metric = "load"        // training load (TRIMP)
+	MetricZoneTime   Metric = "zone_time"   // minutes in the goal's zones
+	MetricZoneShare  Metric = "zone_share"  // percent of zoned time in the goal's zones
+)
+
+// Metrics lists every supported metric
+var Metrics = []Metric{MetricDistance, MetricMovingTime, MetricElevation, MetricCount, MetricKudos, MetricLoad,
+	MetricZoneTime, MetricZoneShare}
+
+// Valid reports whether m is a supported metric
+func (m Metric) Valid() bool {
+	for _, known := range Metrics {
+		if m == known {
+			return true
+		}
+	}
+	return false
+}
+
+// Unit returns the unit a metric's values are expressed in
+func (m Metric) Unit() string {
+	switch m {
+	case MetricDistance:
+		return "km"
+	case MetricMovingTime:
+		return "hours"
+	case MetricElevation:
+		return "m"
+	case MetricCount:
+		return "activities"
+	case MetricKudos:
+		return "kudos"
+	case MetricLoad:
+		return "TRIMP"
+	case MetricZoneTime:
+		return "min"
+	case MetricZoneShare:
+		return "%"
+	default:
+		return ""
+	}
+}
+
+// Zoned reports whether the metric measures time in a goal's zones
+func (m Metric) Zoned() bool {
+	return m == MetricZoneTime || m == MetricZoneShare
+}
+
+// Value returns the amount an activity contributes to the metric. Training
+// load is always estimated here; Engine weighs it by heart rate. Zone metrics
+// need the activity's streams and the goal's zones, so only Engine computes them.
+func (m Metric) Value(a models.Activity) float64 {
+	switch m {
+	case MetricDistance:
+		return a.Distance / 1000
+	case MetricMovingTime:
+		return float64(a.MovingTime) / 3600
+	case MetricElevation:
+		return a.TotalElevGain
+	case MetricCount:
+		return 1
+	case MetricKudos:
+		return float64(a.Kudos)
+	case MetricLoad:
+		return HeartRate{}.TRIMP(a)
+	default:
+		return 0
+	}
+}
+
+// Goal is a single target: a metric summed over matching activities within a period
+type Goal struct {
+	Name          string
+	Metric        Metric
+	ActivityTypes []string  // activity types that count; empty means all
+	SplitPace     int       // seconds per km every full kilometre split must beat; zero means any
+	Zones         ZoneRange // zones measured by the zone metrics
+	Period        Period
+	Target        float64
+	Max           float64 // upper end of a target range; zero means no limit
+	Notes         string  // free-form description shown alongside progress
+}
+
+// Validate checks that the goal is well-formed
+func (g Goal) Validate() error {
+	if g.Name == "" {
+		return fmt.Errorf("goal name is required")
+	}
+	if !g.Metric.Valid() {
+		return fmt.Errorf("goal %q: unknown metric %q", g.Name, g.Metric)
+	}
+	if err := g.Period.Validate(); err != nil {
+		return fmt.Errorf("goal %q: %w", g.Name, err)
+	}
+	if math.IsNaN(g.Target) || math.IsInf(g.Target, 0) || math.IsNaN(g.Max) || math.IsInf(g.Max, 0) {
+		return fmt.Errorf("goal %q: target and max must be finite numbers", g.Name)
+	}
+	if g.Target < 0 {
+		return fmt.Errorf("goal %q: target must be non-negative", g.Name)
+	}
+	if g.SplitPace < 0 {
+		return fmt.Errorf("goal %q: split pace must be non-negative", g.Name)
+	}
+	if g.Max != 0 && g.Max < g.Target {
+		return fmt.Errorf("goal %q: max must not be below the target", g.Name)
+	}
+	switch {
+	case g.Metric.Zoned() && g.Zones.IsZero():
+		return fmt.Errorf("goal %q: metric %s needs zones", g.Name, g.Metric)
+	case !g.Metric.Zoned() && !g.Zones.IsZero():
+		return fmt.Errorf("goal %q: zones only apply to the zone_time and zone_share metrics", g.Name)
+	case g.Metric.Zoned():
+		if err := g.Zones.Validate(); err != nil {
+			return fmt.Errorf("goal %q: %w", g.Name, err)
+		}
+	}
+	return nil
+}
+
+// Matches reports whether an activity counts toward the goal
+func (g Goal) Matches(a models.Activity) bool {
+	if g.SplitPace > 0 && !fastSplits(a, g.SplitPace) {
+		return false
+	}
+	if len(g.ActivityTypes) == 0 {
+		return true
+	}
+	for _, t := range g.ActivityTypes {
+		if a.Type == t {
+			return true
+		}
+	}
+	return false
+}
+
+// fastSplits reports whether an activity has full kilometre splits, all
+// faster than pace seconds per km. Activities without stored details have none.
+func fastSplits(a models.Activity, pace int) bool {
+	full := 0
+	for _, split := range a.Splits {
+		if !split.Full() {
+			continue
+		}
+		if split.Pace() >= float64(pace) {
+			return false
+		}
+		full++
+	}
+	return full > 0
+}
+
+// Reached reports whether a total meets the target without exceeding the max
+func (g Goal) Reached(total float64) bool {
+	return total >= g.Target && (g.Max == 0 || total <= g.Max)
+}
+
+// Result is a goal's progress within its current period
+type Result struct {
+	Goal        Goal
+	Actual      float64
+	Activities  int // matching activities counted
+	PeriodStart time.Time
+	PeriodEnd   time.Time // exclusive
+	Zones       ZoneTime  // time per zone across the activities, for zone metrics
+}
+
+// Percent returns progress as a percentage of the target. A goal with only
+// a max, such as a zone share of at most 20%, is complete while the actual
+// stays within it and falls short in proportion once it exceeds it.
+func (r Result) Percent() float64 {
+	if r.Goal.Target == 0 {
+		if r.Goal.Max == 0 {
+			return 0
+		}
+		if r.Actual <= r.Goal.Max {
+			return 100
+		}
+		return r.Goal.Max / r.Actual * 100
+	}
+	return (r.Actual / r.Goal.Target) * 100
+}
+
+// Remaining returns how much is still needed to reach the target
+func (r Result) Remaining() float64 {
+	remaining := r.Goal.Target - r.Actual
+	if remaining < 0 {
+		return 0
+	}
+	return remaining
+}
+
+// Achieved reports whether the target has been reached, and the max not exceeded
+func (r Result) Achieved() bool {
+	return r.Goal.Reached(r.Actual)
+}
+
+// Over reports whether the actual total exceeds the goal's max
+func (r Result) Over() bool {
+	return r.Goal.Max > 0 && r.Actual > r.Goal.Max
+}
+
+// Engine evaluates a set of goals against activities
+type Engine struct {
+	Goals     []Goal
+	Calendar  Calendar
+	Clock     Clock
+	HeartRate HeartRate // weighs training load; estimated when not configured
+	Zones     ZoneSettings
+	Streams   StreamSource // activity streams for the zone metrics; none count without
+
+	zoneTimes *zoneCache // nil disables caching
+}
+
+// NewEngine creates an engine for the given goals using the default calendar
+// and the system clock
+func NewEngine(goals []Goal) *Engine {
+	return &Engine{Goals: goals, Calendar: DefaultCalendar(), Clock: SystemClock{},
+		zoneTimes: &zoneCache{times: map[zoneKey]ZoneTime{}}}
+}
+
+// EvaluateFrom loads the activities needed from source and evaluates every
+// goal as of the engine's clock
+func (e *Engine) EvaluateFrom(source ActivitySource) ([]Result, error) {
+	now := e.Clock.Now()
+	activities, err := source.ActivitiesSince(e.Since(now))
+	if err != nil {
+		return nil, fmt.Errorf("load activities: %w", err)
+	}
+	return e.Evaluate(activities, now), nil
+}
+
+// Evaluate computes each goal's progress in the period containing now, as it
+// stood at now: activities that started later are ignored. Result windows are
+// expressed in the calendar's wall-clock time.
+func (e *Engine) Evaluate(activities []models.Activity, now time.Time) []Result {
+	results := make([]Result, 0, len(e.Goals))
+	for _, goal := range e.Goals {
+		start, end := e.Calendar.Window(goal.Period, now)
+		results = append(results, e.evaluateWindow(goal, activities, start, end, now))
+	}
+	return results
+}
+
+// History evaluates every period of each goal that overlaps the range
+// [from, to), as it stood at now. Results are ordered by goal, then period.
+func (e *Engine) History(activities []models.Activity, from, to, now time.Time) []Result {
+	rangeStart, rangeEnd := e.Calendar.Now(from), e.Calendar.Now(to)
+
+	var results []Result
+	for _, goal := range e.Goals {
+		start, end := e.Calendar.Window(goal.Period, from)
+		for start.Before(rangeEnd) {
+			if end.After(rangeStart) {
+				results = append(results, e.evaluateWindow(goal, activities, start, end, now))
+			}
+			if goal.Period.Kind == PeriodCustom {
+				break // A custom period has a single window
+			}
+			start, end = goal.Period.Window(end, e.Calendar.WeekStart)
+		}
+	}
+	return results
+}
+
+// evaluateWindow sums a goal's matching activities within the wall-clock
+// window [start, end), ignoring those that started after now
+func (e *Engine) evaluateWindow(goal Goal, activities []models.Activity, start, end, now time.Time) Result {
+	result := Result{Goal: goal, PeriodStart: start, PeriodEnd: end}
+
+	var t tally
+	for _, activity := range activities {
+		if startTime, err := activity.StartTime(); err != nil || startTime.After(now) {
+			continue // Skip activities with invalid dates or after the evaluation instant
+		}
+		activityTime, err := e.Calendar.ActivityTime(activity)
+		if err != nil {
+			continue
+		}
+		if activityTime.Before(start) || !activityTime.Before(end) {
+			continue
+		}
+		if !goal.Matches(activity) {
+			continue
+		}
+		e.add(&t, goal, activity)
+		result.Activities++
+	}
+	result.Actual, result.Zones = t.total(goal), t.zones
+	return result
+}
+
+// value returns the amount an activity contributes to a goal's metric,
+// computing training load from the engine's heart rate profile and zone
+// time from the activity's streams. A zone share is not a sum, see tally.
+func (e *Engine) value(goal Goal, a models.Activity) float64 {
+	switch goal.Metric {
+	case MetricLoad:
+		return e.HeartRate.TRIMP(a)
+	case MetricZoneTime:
+		spent, _ := e.ZoneTime(a, goal.Zones.Kind)
+		return float64(spent.In(goal.Zones)) / 60
+	case MetricZoneShare:
+		return 0
+	}
+	return goal.Metric.Value(a)
+}
+
+// tally accumulates a goal's metric over activities. Shares do not add up,
+// so zone time is summed per zone and the share taken from the totals.
+type tally struct {
+	sum   float64
+	zones ZoneTime
+}
+
+func (e *Engine) add(t *tally, goal Goal, a models.Activity) {
+	if !goal.Metric.Zoned() {
+		t.sum += e.value(goal, a)
+		return
+	}
+	spent, _ := e.ZoneTime(a, goal.Zones.Kind)
+	t.zones = t.zones.Add(spent)
+}
+
+// total returns the goal's metric over everything added
+func (t tally) total(goal Goal) float64 {
+	switch goal.Metric {
+	case MetricZoneTime:
+		return float64(t.zones.In(goal.Zones)) / 60
+	case MetricZoneShare:
+		recorded := t.zones.Total()
+		if recorded == 0 {
+			return 0
+		}
+		return float64(t.zones.In(goal.Zones)) / float64(recorded) * 100
+	default:
+		return t.sum
+	}
+}
+
+// Since returns how far back activities are needed to evaluate the goals at
+// now. Windows are wall-clock times, so a day of slack covers any UTC offset.
+func (e *Engine) Since(now time.Time) time.Time {
+	earliest := e.Calendar.Now(now)
+	for _, goal := range e.Goals {
+		if start, _ := e.Calendar.Window(goal.Period, now); start.Before(earliest) {
+			earliest = start
+		}
+	}
+	return earliest.AddDate(0, 0, -1)
+}
+
+// MotivationalMessage returns an encouraging message for a set of results
+func MotivationalMessage(results []Result) string {
+	if len(results) == 0 {
+		return "🎯 No goals configured yet. Add some to start tracking!"
+	}
+
+	achieved := 0
+	total := 0.0
+	for _, r := range results {
+		if r.Achieved() {
+			achieved++
+		}
+		percent := r.Percent()
+		if percent > 100 {
+			percent = 100
+		}
+		total += percent
+	}
+	average := total / float64(len(results))
+
+	switch {
+	case achieved == len(results):
+		return "🎉 Congratulations! You've achieved all of your goals!"
+	case achieved > 0:
+		return fmt.Sprintf("🏆 %d of %d goals achieved! Keep up the momentum!", achieved, len(results))
+	case average > 50:
+		return "🔥 You're over halfway there! Keep pushing!"
+	case average > 0:
+		return "💪 Good start! Every session brings you closer!"
+	default:
+		return "🚀 Plenty of time left! Time to start building towards your goals!"
+	}
+}
@@ -0,0 +1,176 @@
+package goals
+
+import (
+	"math"
+	"testing"
+	"time"
+
+	"strava-custom-goals/internal/models"
+)
+
+func TestEngineEvaluatesEachMetric(t *testing.T) {
+	// Wednesday; the week runs from Monday Oct 6 to Monday Oct 13
+	now := time.Date(2025, 10, 8, 12, 0, 0, 0, time.UTC)
+	activities := []models.Activity{
+		{Type: "Run", Distance: 5000, MovingTime: 1800, TotalElevGain: 40, Kudos: 3, StartDate: "2025-10-06T07:00:00Z"},
+		{Type: "Ride", Distance: 20000, MovingTime: 3600, TotalElevGain: 200, Kudos: 1, StartDate: "2025-10-07T07:00:00Z"},
+		{Type: "Run", Distance: 8000, MovingTime: 2700, TotalElevGain: 60, Kudos: 5, StartDate: "2025-10-05T07:00:00Z"}, // previous week
+	}
+
+	goals := []Goal{
+		{Name: "Run km", Metric: MetricDistance, ActivityTypes: []string{"Run"}, Period: Every(PeriodWeek), Target: 10},
+		{Name: "Hours", Metric: MetricMovingTime, Period: Every(PeriodWeek), Target: 2},
+		{Name: "Climb", Metric: MetricElevation, ActivityTypes: []string{"Run", "Ride"}, Period: Every(PeriodWeek), Target: 200},
+		{Name: "Sessions", Metric: MetricCount, Period: Every(PeriodWeek), Target: 2},
+		{Name: "Kudos", Metric: MetricKudos, Period: Every(PeriodWeek), Target: 10},
+	}
+
+	results := NewEngine(goals).Evaluate(activities, now)
+	if len(results) != len(goals) {
+		t.Fatalf("Expected %d results, got %d", len(goals), len(results))
+	}
+
+	expected := []struct {
+		actual   float64
+		count    int
+		achieved bool
+	}{
+		{5, 1, false},
+		{1.5, 2, false},
+		{240, 2, true},
+		{2, 2, true},
+		{4, 2, false},
+	}
+	for i, want := range expected {
+		r := results[i]
+		if r.Actual != want.actual || r.Activities != want.count || r.Achieved() != want.achieved {
+			t.Errorf("%s: expected actual %.2f over %d activities (achieved %v), got %.2f over %d (achieved %v)",
+				r.Goal.Name, want.actual, want.count, want.achieved, r.Actual, r.Activities, r.Achieved())
+		}
+	}
+
+	if results[0].Percent() != 50 || results[0].Remaining() != 5 {
+		t.Errorf("Expected 50%% with 5 km remaining, got %.1f%% and %.1f", results[0].Percent(), results[0].Remaining())
+	}
+	if !results[0].PeriodStart.Equal(time.Date(2025, 10, 6, 0, 0, 0, 0, time.UTC)) {
+		t.Errorf("Unexpected period start %v", results[0].PeriodStart)
+	}
+}
+
+func TestResultWithOnlyAMax(t *testing.T) {
+	goal := Goal{Name: "Easy share", Metric: MetricZoneShare, Period: Every(PeriodWeek), Max: 20}
+
+	within := Result{Goal: goal, Actual: 15}
+	if !within.Achieved() || within.Percent() != 100 || within.Remaining() != 0 {
+		t.Errorf("Expected 15%% of at most 20%% to be complete, got %.1f%% (achieved %v)", within.Percent(), within.Achieved())
+	}
+
+	over := Result{Goal: goal, Actual: 25}
+	if over.Achieved() || !over.Over() || over.Percent() != 80 {
+		t.Errorf("Expected 25%% of at most 20%% to be over at 80%%, got %.1f%% (achieved %v)", over.Percent(), over.Achieved())
+	}
+
+	if none := (Result{Goal: Goal{Name: "Nothing"}, Actual: 3}); none.Percent() != 0 {
+		t.Errorf("Expected 0%% without a target or max, got %.1f%%", none.Percent())
+	}
+}
+
+func TestEngineHistory(t *testing.T) {
+	activities := []models.Activity{
+		{Type: "Run", Distance: 5000, StartDate: "2025-09-30T07:00:00Z"},
+		{Type: "Run", Distance: 8000, StartDate: "2025-10-07T07:00:00Z"},
+		{Type: "Run", Distance: 3000, StartDate: "2025-10-16T07:00:00Z"},
+	}
+	goals := []Goal{
+		{Name: "Weekly", Metric: MetricDistance, Period: Every(PeriodWeek), Target: 6},
+		{Name: "Monthly", Metric: MetricDistance, Period: Every(PeriodMonth), Target: 20},
+		{Name: "Block", Metric: MetricCount, Period: Range(time.Date(2025, 11, 1, 0, 0, 0, 0, time.UTC), time.Date(2025, 12, 1, 0, 0, 0, 0, time.UTC)), Target: 1},
+	}
+
+	// Oct 1 (Wednesday) to Oct 15 overlaps three weeks and one month; the custom block is outside
+	from := time.Date(2025, 10, 1, 0, 0, 0, 0, time.UTC)
+	to := time.Date(2025, 10, 15, 0, 0, 0, 0, time.UTC)
+	now := time.Date(2025, 10, 20, 0, 0, 0, 0, time.UTC)
+	results := NewEngine(goals).History(activities, from, to, now)
+
+	if len(results) != 4 {
+		t.Fatalf("Expected 3 weekly results and 1 monthly result, got %d", len(results))
+	}
+
+	weekly := []float64{5, 8, 3}
+	for i, want := range weekly {
+		if results[i].Actual != want {
+			t.Errorf("Week %d: expected %.0f km, got %.0f", i, want, results[i].Actual)
+		}
+	}
+	if !results[0].PeriodStart.Equal(time.Date(2025, 9, 29, 0, 0, 0, 0, time.UTC)) {
+		t.Errorf("Expected the first week to start Sep 29, got %v", results[0].PeriodStart)
+	}
+
+	// The whole month is evaluated, including the run after the range end
+	if results[3].Goal.Name != "Monthly" || results[3].Actual != 11 {
+		t.Errorf("Expected 11 km for October, got %s %.0f", results[3].Goal.Name, results[3].Actual)
+	}
+}
+
+func TestWeeklyGoalsAreGenericGoals(t *testing.T) {
+	goals := WeeklyGoals{RunningGoalKm: 10, WorkoutGoalHours: 3}.Goals()
+	if len(goals) != 2 {
+		t.Fatalf("Expected 2 goals, got %d", len(goals))
+	}
+	for _, g := range goals {
+		if err := g.Validate(); err != nil {
+			t.Errorf("Built-in goal invalid: %v", err)
+		}
+	}
+	if goals[0].Metric != MetricDistance || goals[0].Target != 10 {
+		t.Errorf("Unexpected running goal %+v", goals[0])
+	}
+	if goals[1].Metric != MetricMovingTime || goals[1].Target != 3 {
+		t.Errorf("Unexpected workout goal %+v", goals[1])
+	}
+}
+
+func TestGoalValidate(t *testing.T) {
+	testCases := []struct {
+		goal  Goal
+		valid bool
+	}{
+		{Goal{Name: "ok", Metric: MetricCount, Period: Every(PeriodWeek), Target: 3}, true},
+		{Goal{Metric: MetricCount, Period: Every(PeriodWeek)}, false},
+		{Goal{Name: "bad metric", Metric: "pace", Period: Every(PeriodWeek)}, false},
+		{Goal{Name: "bad period", Metric: MetricCount, Period: Every("fortnight")}, false},
+		{Goal{Name: "negative", Metric: MetricCount, Period: Every(PeriodWeek), Target: -1}, false},
+		{Goal{Name: "nan", Metric: MetricCount, Period: Every(PeriodWeek), Target: math.NaN()}, false},
+		{Goal{Name: "inf max", Metric: MetricCount, Period: Every(PeriodWeek), Target: 3, Max: math.Inf(1)}, false},
+	}
+
+	for _, tc := range testCases {
+		err := tc.goal.Validate()
+		if (err == nil) != tc.valid {
+			t.Errorf("For goal %+v, expected valid=%v, got error %v", tc.goal, tc.valid, err)
+		}
+	}
+}
+
+func TestGoalMatchesSplitPace(t *testing.T) {
+	goal := Goal{Name: "Fast runs", Metric: MetricCount, ActivityTypes: []string{"Run"}, SplitPace: 300, Period: Every(PeriodMonth), Target: 3}
+	splits := func(seconds ...int) []models.Split {
+		var s []models.Split
+		for i, sec := range seconds {
+			s = append(s, models.Split{Split: i + 1, Distance: 1000, MovingTime: sec})
+		}
+		return s
+	}
+
+	fast := models.Activity{Type: "Run", Splits: append(splits(290, 295), models.Split{Split: 3, Distance: 400, MovingTime: 150})}
+	if !goal.Matches(fast) {
+		t.Error("Expected sub-5:00 full splits to match, ignoring the short last split")
+	}
+	if slow := (models.Activity{Type: "Run", Splits: splits(290, 301)}); goal.Matches(slow) {
+		t.Error("Expected a 5:01 split to disqualify the run")
+	}
+	if goal.Matches(models.Activity{Type: "Run"}) {
+		t.Error("Expected a run without stored splits not to match")
+	}
+}
@@ -0,0 +1,156 @@
+package goals
+
+import (
+	"fmt"
+	"math"
+	"time"
+
+	"strava-custom-goals/internal/models"
+)
+
+// Training load time constants, in days
+const (
+	FitnessDays = 42 // chronic training load (CTL)
+	FatigueDays = 7  // acute training load (ATL)
+)
+
+// EstimatedIntensity is the share of heart rate reserve assumed for
+// activities without heart rate data, about an easy aerobic effort
+const EstimatedIntensity = 0.6
+
+// HeartRate is the athlete's heart rate profile used to weigh training load
+type HeartRate struct {
+	Max     float64 // bpm
+	Resting float64 // bpm
+}
+
+// Configured reports whether the profile can be used to compute load
+func (h HeartRate) Configured() bool {
+	return h.Resting > 0 && h.Max > h.Resting
+}
+
+// Measured reports whether an activity's load comes from its heart rate
+// rather than an estimate
+func (h HeartRate) Measured(a models.Activity) bool {
+	return h.Configured() && a.HasHeartrate && a.AverageHeartrate > 0
+}
+
+// TRIMP returns Banister's training impulse for an activity: moving minutes
+// weighted exponentially by the share of heart rate reserve used. Activities
+// without heart rate, or without a configured profile, use EstimatedIntensity.
+func (h HeartRate) TRIMP(a models.Activity) float64 {
+	intensity := EstimatedIntensity
+	if h.Measured(a) {
+		intensity = math.Max(0, math.Min(1, (a.AverageHeartrate-h.Resting)/(h.Max-h.Resting)))
+	}
+	minutes := float64(a.MovingTime) / 60
+	return minutes * intensity * 0.64 * math.Exp(1.92*intensity)
+}
+
+// FormZone classifies form (TSB)
+type FormZone string
+
+// Form zones, from freshest to most fatigued
+const (
+	ZoneTransition   FormZone = "transition"   // rested to the point of losing fitness
+	ZoneFresh        FormZone = "fresh"        // ready to race
+	ZoneNeutral      FormZone = "neutral"      // maintaining
+	ZoneOptimal      FormZone = "optimal"      // productive training
+	ZoneOverreaching FormZone = "overreaching" // fatigue outpaces fitness
+)
+
+// FormZoneOf classifies a form value
+func FormZoneOf(form float64) FormZone {
+	switch {
+	case form > 25:
+		return ZoneTransition
+	case form > 5:
+		return ZoneFresh
+	case form > -10:
+		return ZoneNeutral
+	case form > -30:
+		return ZoneOptimal
+	default:
+		return ZoneOverreaching
+	}
+}
+
+// LoadDay is one calendar day of training load
+type LoadDay struct {
+	Date       time.Time // wall-clock midnight
+	Load       float64   // TRIMP of the day's activities
+	Activities int
+	Estimated  int     // activities whose load was estimated without heart rate
+	Fitness    float64 // chronic training load (CTL) at the end of the day
+	Fatigue    float64 // acute training load (ATL) at the end of the day
+	Form       float64 // training stress balance (TSB): the previous day's fitness minus fatigue
+}
+
+// Zone classifies the day's form
+func (d LoadDay) Zone() FormZone {
+	return FormZoneOf(d.Form)
+}
+
+// TrainingLoad computes each day's load, fitness, fatigue and form from the
+// first activity up to the day containing now. Activities should cover the
+// whole history, as fitness builds up from zero.
+func (e *Engine) TrainingLoad(activities []models.Activity, now time.Time) []LoadDay {
+	type dayTotal struct {
+		load                  float64
+		activities, estimated int
+	}
+	totals := map[time.Time]*dayTotal{}
+	var first time.Time
+	for _, activity := range activities {
+		if start, err := activity.StartTime(); err != nil || start.After(now) {
+			continue
+		}
+		activityTime, err := e.Calendar.ActivityTime(activity)
+		if err != nil {
+			continue
+		}
+		day, _ := Every(PeriodDay).Window(activityTime, e.Calendar.WeekStart)
+		total := totals[day]
+		if total == nil {
+			total = &dayTotal{}
+			totals[day] = total
+		}
+		total.load += e.HeartRate.TRIMP(activity)
+		total.activities++
+		if !e.HeartRate.Measured(activity) {
+			total.estimated++
+		}
+		if first.IsZero() || day.Before(first) {
+			first = day
+		}
+	}
+
+	today, _ := e.Calendar.Window(Every(PeriodDay), now)
+	if first.IsZero() || first.After(today) {
+		return nil
+	}
+
+	var days []LoadDay
+	var fitness, fatigue float64
+	for day := first; !day.After(today); day = day.AddDate(0, 0, 1) {
+		d := LoadDay{Date: day, Form: fitness - fatigue}
+		if total := totals[day]; total != nil {
+			d.Load, d.Activities, d.Estimated = total.load, total.activities, total.estimated
+		}
+		fitness += (d.Load - fitness) / FitnessDays
+		fatigue += (d.Load - fatigue) / FatigueDays
+		d.Fitness, d.Fatigue = fitness, fatigue
+		days = append(days, d)
+	}
+	return days
+}
+
+// TrainingLoadFrom loads the full history from source and computes
+// TrainingLoad as of the engine's clock
+func (e *Engine) TrainingLoadFrom(source ActivitySource) ([]LoadDay, error) {
+	activities, err := source.ActivitiesSince(time.Time{})
+	if err != nil {
+		return nil, fmt.Errorf("load activities: %w", err)
+	}
+	return e.TrainingLoad(activities, e.Clock.Now()), nil
+}
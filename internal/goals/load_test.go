@@ -0,0 +1,93 @@
+package goals
+
+import (
+	"math"
+	"testing"
+	"time"
+
+	"strava-custom-goals/internal/models"
+)
+
+func TestTRIMPUsesHeartRateReserve(t *testing.T) {
+	profile := HeartRate{Max: 190, Resting: 50}
+	hour := models.Activity{Type: "Run", MovingTime: 3600, HasHeartrate: true, AverageHeartrate: 148}
+
+	// 60 min × 0.7 × 0.64 × e^(1.92 × 0.7)
+	if load := profile.TRIMP(hour); math.Abs(load-103.07) > 0.01 {
+		t.Errorf("Expected TRIMP 103.07, got %.2f", load)
+	}
+	if !profile.Measured(hour) {
+		t.Error("Expected an activity with heart rate to be measured")
+	}
+
+	// Without heart rate data, or without a profile, the load is estimated
+	hour.HasHeartrate, hour.AverageHeartrate = false, 0
+	if load := profile.TRIMP(hour); math.Abs(load-72.91) > 0.01 {
+		t.Errorf("Expected estimated TRIMP 72.91, got %.2f", load)
+	}
+	hour.HasHeartrate, hour.AverageHeartrate = true, 148
+	if load := (HeartRate{}).TRIMP(hour); math.Abs(load-72.91) > 0.01 {
+		t.Errorf("Expected estimated TRIMP 72.91 without a profile, got %.2f", load)
+	}
+}
+
+func TestEngineTrainingLoad(t *testing.T) {
+	engine := NewEngine(nil)
+	engine.Calendar = Calendar{Location: time.UTC, WeekStart: time.Monday}
+	engine.HeartRate = HeartRate{Max: 190, Resting: 50}
+
+	activities := []models.Activity{
+		{Type: "Run", MovingTime: 3600, HasHeartrate: true, AverageHeartrate: 148, StartDate: "2025-10-06T07:00:00Z"},
+		{Type: "WeightTraining", MovingTime: 3600, StartDate: "2025-10-06T18:00:00Z"},
+		{Type: "Run", MovingTime: 3600, StartDate: "2025-10-20T07:00:00Z"}, // after now
+	}
+	days := engine.TrainingLoad(activities, time.Date(2025, 10, 8, 12, 0, 0, 0, time.UTC))
+
+	if len(days) != 3 {
+		t.Fatalf("Expected Oct 6 to Oct 8, got %d days", len(days))
+	}
+	first := days[0]
+	if first.Activities != 2 || first.Estimated != 1 || math.Abs(first.Load-175.98) > 0.01 {
+		t.Errorf("Expected 2 activities (1 estimated) with load 175.98 on Oct 6, got %+v", first)
+	}
+	if math.Abs(first.Fitness-first.Load/FitnessDays) > 1e-9 || math.Abs(first.Fatigue-first.Load/FatigueDays) > 1e-9 {
+		t.Errorf("Expected fitness and fatigue to ramp from zero, got %+v", first)
+	}
+	if first.Form != 0 {
+		t.Errorf("Expected neutral form before the first day, got %.2f", first.Form)
+	}
+
+	second := days[1]
+	if second.Load != 0 || math.Abs(second.Form-(first.Fitness-first.Fatigue)) > 1e-9 {
+		t.Errorf("Expected a rest day with yesterday's form, got %+v", second)
+	}
+	if second.Fatigue >= first.Fatigue || second.Zone() != ZoneOptimal {
+		t.Errorf("Expected fatigue to decay into the optimal zone, got %+v (%s)", second, second.Zone())
+	}
+}
+
+func TestRangeGoalIsNotAchievedWhenOver(t *testing.T) {
+	goal := Goal{Name: "Load", Metric: MetricLoad, Period: Every(PeriodWeek), Target: 400, Max: 500}
+	start := time.Date(2025, 10, 6, 0, 0, 0, 0, time.UTC)
+	result := Result{Goal: goal, Actual: 520, PeriodStart: start, PeriodEnd: start.AddDate(0, 0, 7)}
+
+	if result.Achieved() || !result.Over() {
+		t.Errorf("Expected 520 to be over a 400–500 range, got achieved=%v over=%v", result.Achieved(), result.Over())
+	}
+	result.Actual = 450
+	if !result.Achieved() || result.Over() {
+		t.Errorf("Expected 450 to be within a 400–500 range, got achieved=%v over=%v", result.Achieved(), result.Over())
+	}
+
+	// Halfway through the week at 450, the pace projects well past the max
+	engine := NewEngine([]Goal{goal})
+	engine.Calendar = Calendar{Location: time.UTC, WeekStart: time.Monday}
+	projection := engine.Project(result, nil, start.Add(84*time.Hour))
+	if projection.Status != StatusOver {
+		t.Errorf("Expected status over, got %s (projected %.0f)", projection.Status, projection.Projected)
+	}
+
+	if err := (Goal{Name: "Load", Metric: MetricLoad, Period: Every(PeriodWeek), Target: 400, Max: 300}).Validate(); err == nil {
+		t.Error("Expected a max below the target to be rejected")
+	}
+}
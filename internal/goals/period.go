@@ -0,0 +1,113 @@
+package goals
+
+import (
+	"fmt"
+	"time"
+)
+
+// PeriodKind is the kind of time window a goal is measured over
+type PeriodKind string
+
+// Supported period kinds
+const (
+	PeriodDay     PeriodKind = "day"
+	PeriodWeek    PeriodKind = "week" // seven days from the calendar's week start
+	PeriodMonth   PeriodKind = "month"
+	PeriodQuarter PeriodKind = "quarter"
+	PeriodYear    PeriodKind = "year"
+	PeriodCustom  PeriodKind = "custom" // fixed Start/End range, e.g. a training block
+)
+
+// PeriodKinds lists the calendar period kinds
+var PeriodKinds = []PeriodKind{PeriodDay, PeriodWeek, PeriodMonth, PeriodQuarter, PeriodYear}
+
+// Period is a goal's measurement window: a recurring calendar period or a fixed range
+type Period struct {
+	Kind  PeriodKind
+	Start time.Time // custom periods only, inclusive
+	End   time.Time // custom periods only, exclusive
+}
+
+// Every returns a recurring calendar period
+func Every(kind PeriodKind) Period {
+	return Period{Kind: kind}
+}
+
+// Range returns a fixed period covering [start, end)
+func Range(start, end time.Time) Period {
+	return Period{Kind: PeriodCustom, Start: start, End: end}
+}
+
+// Validate checks that the period is well-formed
+func (p Period) Validate() error {
+	if p.Kind == PeriodCustom {
+		if p.Start.IsZero() || p.End.IsZero() {
+			return fmt.Errorf("custom period needs both a start and an end")
+		}
+		if !p.End.After(p.Start) {
+			return fmt.Errorf("custom period must end after it starts")
+		}
+		return nil
+	}
+	for _, kind := range PeriodKinds {
+		if p.Kind == kind {
+			return nil
+		}
+	}
+	return fmt.Errorf("unknown period %q", p.Kind)
+}
+
+// Window returns the [start, end) window of the period containing now, with
+// weeks starting on weekStart. Custom periods always return their fixed range.
+func (p Period) Window(now time.Time, weekStart time.Weekday) (time.Time, time.Time) {
+	day := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location())
+
+	switch p.Kind {
+	case PeriodCustom:
+		return p.Start, p.End
+	case PeriodDay:
+		return day, day.AddDate(0, 0, 1)
+	case PeriodMonth:
+		start := time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, now.Location())
+		return start, start.AddDate(0, 1, 0)
+	case PeriodQuarter:
+		firstMonth := time.Month((int(now.Month())-1)/3*3 + 1)
+		start := time.Date(now.Year(), firstMonth, 1, 0, 0, 0, 0, now.Location())
+		return start, start.AddDate(0, 3, 0)
+	case PeriodYear:
+		start := time.Date(now.Year(), time.January, 1, 0, 0, 0, 0, now.Location())
+		return start, start.AddDate(1, 0, 0)
+	default: // PeriodWeek
+		offset := (int(now.Weekday()) - int(weekStart) + 7) % 7
+		start := day.AddDate(0, 0, -offset)
+		return start, start.AddDate(0, 0, 7)
+	}
+}
+
+// Adjective describes the period for messages such as "your weekly goal"
+func (p Period) Adjective() string {
+	switch p.Kind {
+	case PeriodDay:
+		return "daily"
+	case PeriodWeek:
+		return "weekly"
+	case PeriodMonth:
+		return "monthly"
+	case PeriodQuarter:
+		return "quarterly"
+	case PeriodYear:
+		return "yearly"
+	case PeriodCustom:
+		return fmt.Sprintf("%s – %s", p.Start.Format("Jan 2"), p.End.AddDate(0, 0, -1).Format("Jan 2, 2006"))
+	default:
+		return string(p.Kind)
+	}
+}
+
+// String returns the period kind, or the date range for custom periods
+func (p Period) String() string {
+	if p.Kind == PeriodCustom {
+		return fmt.Sprintf("%s..%s", p.Start.Format("2006-01-02"), p.End.Format("2006-01-02"))
+	}
+	return string(p.Kind)
+}
@@ -0,0 +1,83 @@
+package goals
+
+import (
+	"testing"
+	"time"
+
+	"strava-custom-goals/internal/models"
+)
+
+func TestPeriodWindow(t *testing.T) {
+	now := time.Date(2025, 8, 17, 15, 30, 0, 0, time.UTC) // a Sunday
+	date := func(y int, m time.Month, d int) time.Time { return time.Date(y, m, d, 0, 0, 0, 0, time.UTC) }
+
+	testCases := []struct {
+		period     Period
+		start, end time.Time
+	}{
+		{Every(PeriodDay), date(2025, 8, 17), date(2025, 8, 18)},
+		{Every(PeriodWeek), date(2025, 8, 11), date(2025, 8, 18)},
+		{Every(PeriodMonth), date(2025, 8, 1), date(2025, 9, 1)},
+		{Every(PeriodQuarter), date(2025, 7, 1), date(2025, 10, 1)},
+		{Every(PeriodYear), date(2025, 1, 1), date(2026, 1, 1)},
+		{Range(date(2025, 6, 2), date(2025, 8, 25)), date(2025, 6, 2), date(2025, 8, 25)},
+	}
+
+	for _, tc := range testCases {
+		start, end := tc.period.Window(now, time.Monday)
+		if !start.Equal(tc.start) || !end.Equal(tc.end) {
+			t.Errorf("%s: expected [%v, %v), got [%v, %v)", tc.period, tc.start, tc.end, start, end)
+		}
+	}
+}
+
+func TestPeriodValidate(t *testing.T) {
+	start := time.Date(2025, 6, 2, 0, 0, 0, 0, time.UTC)
+	testCases := []struct {
+		period Period
+		valid  bool
+	}{
+		{Every(PeriodQuarter), true},
+		{Range(start, start.AddDate(0, 0, 84)), true},
+		{Range(start, start), false},
+		{Period{Kind: PeriodCustom, Start: start}, false},
+		{Every("fortnight"), false},
+	}
+
+	for _, tc := range testCases {
+		if err := tc.period.Validate(); (err == nil) != tc.valid {
+			t.Errorf("For %+v, expected valid=%v, got %v", tc.period, tc.valid, err)
+		}
+	}
+}
+
+func TestEngineUsesEachGoalsPeriod(t *testing.T) {
+	now := time.Date(2025, 10, 8, 12, 0, 0, 0, time.UTC)
+	activities := []models.Activity{
+		{Type: "Run", Distance: 10000, StartDate: "2025-10-07T07:00:00Z"}, // this week
+		{Type: "Run", Distance: 20000, StartDate: "2025-10-02T07:00:00Z"}, // this month
+		{Type: "Run", Distance: 30000, StartDate: "2025-08-20T07:00:00Z"}, // this year, in the block
+		{Type: "Run", Distance: 40000, StartDate: "2024-12-30T07:00:00Z"}, // last year
+	}
+
+	block := Range(time.Date(2025, 7, 14, 0, 0, 0, 0, time.UTC), time.Date(2025, 10, 6, 0, 0, 0, 0, time.UTC))
+	goals := []Goal{
+		{Name: "Week", Metric: MetricDistance, Period: Every(PeriodWeek), Target: 20},
+		{Name: "Month", Metric: MetricDistance, Period: Every(PeriodMonth), Target: 100},
+		{Name: "Year", Metric: MetricDistance, Period: Every(PeriodYear), Target: 1000},
+		{Name: "Block", Metric: MetricDistance, Period: block, Target: 400},
+	}
+
+	engine := NewEngine(goals)
+	results := engine.Evaluate(activities, now)
+	expected := []float64{10, 30, 60, 50}
+	for i, want := range expected {
+		if results[i].Actual != want {
+			t.Errorf("%s: expected %.0f km, got %.0f", results[i].Goal.Name, want, results[i].Actual)
+		}
+	}
+
+	if since := engine.Since(now); !since.Equal(time.Date(2024, 12, 31, 0, 0, 0, 0, time.UTC)) {
+		t.Errorf("Expected activities needed since Dec 31 (Jan 1 less a day of slack), got %v", since)
+	}
+}
@@ -0,0 +1,177 @@
+package goals
+
+import (
+	"fmt"
+	"math"
+	"time"
+
+	"strava-custom-goals/internal/models"
+)
+
+// ProjectionLookbackWeeks is how many weeks before a period its weekday
+// pattern is learned from
+const ProjectionLookbackWeeks = 12
+
+// ProjectionStatus summarises a projection against the target
+type ProjectionStatus string
+
+// Projection statuses
+const (
+	StatusAchieved ProjectionStatus = "achieved" // target already reached
+	StatusAhead    ProjectionStatus = "ahead"    // projected to beat the target comfortably
+	StatusOnTrack  ProjectionStatus = "on_track" // projected to reach the target
+	StatusBehind   ProjectionStatus = "behind"   // projected to fall short
+	StatusOver     ProjectionStatus = "over"     // projected to exceed the goal's max
+)
+
+// aheadMargin is how far above the target a projection counts as ahead
+const aheadMargin = 1.15
+
+// Projection forecasts where a goal will stand at the end of its period
+type Projection struct {
+	Elapsed        float64 // fraction of the period elapsed, 0 to 1
+	DaysRemaining  int     // calendar days left, including today
+	PaceTotal      float64 // total if the pace so far continues
+	PatternTotal   float64 // total if the usual weekday pattern continues
+	HasPattern     bool    // whether there was history to learn a pattern from
+	Projected      float64 // headline forecast: the pattern when known, else the pace
+	RequiredPerDay float64 // amount needed per remaining day to reach the target, zero for shares
+	Status         ProjectionStatus
+}
+
+// Projections forecasts each result at the end of its period. Activities
+// must reach back ProjectionLookbackWeeks before the earliest period start.
+func (e *Engine) Projections(results []Result, activities []models.Activity, now time.Time) []Projection {
+	projections := make([]Projection, 0, len(results))
+	for _, result := range results {
+		projections = append(projections, e.Project(result, activities, now))
+	}
+	return projections
+}
+
+// ProjectionsFrom loads the history needed from source and forecasts results
+// as of the engine's clock
+func (e *Engine) ProjectionsFrom(source ActivitySource, results []Result) ([]Projection, error) {
+	now := e.Clock.Now()
+	// An extra week so an athlete's history never looks shorter than the lookback
+	since := e.Since(now).AddDate(0, 0, -7*(ProjectionLookbackWeeks+1))
+	activities, err := source.ActivitiesSince(since)
+	if err != nil {
+		return nil, fmt.Errorf("load activities: %w", err)
+	}
+	return e.Projections(results, activities, now), nil
+}
+
+// Project forecasts one result at the end of its period
+func (e *Engine) Project(result Result, activities []models.Activity, now time.Time) Projection {
+	wallNow := e.Calendar.Now(now)
+	start, end := result.PeriodStart, result.PeriodEnd
+
+	p := Projection{Elapsed: 1}
+	if total := end.Sub(start); total > 0 {
+		p.Elapsed = math.Max(0, math.Min(1, float64(wallNow.Sub(start))/float64(total)))
+	}
+
+	// Days left from today (or the period start, if it lies ahead) to the end
+	from := wallNow
+	if from.Before(start) {
+		from = start
+	}
+	today := time.Date(from.Year(), from.Month(), from.Day(), 0, 0, 0, 0, time.UTC)
+	if today.Before(end) {
+		p.DaysRemaining = int(math.Ceil(end.Sub(today).Hours() / 24))
+	}
+
+	// A share does not grow with time, so it is projected to hold
+	share := result.Goal.Metric == MetricZoneShare
+
+	p.PaceTotal = result.Actual
+	if p.Elapsed > 0 && p.Elapsed < 1 && !share {
+		p.PaceTotal = result.Actual / p.Elapsed
+	}
+
+	if pattern, ok := e.weekdayPattern(result, activities); ok && !share {
+		p.HasPattern = true
+		p.PatternTotal = result.Actual + expectedUntil(pattern, from, end)
+	}
+
+	p.Projected = p.PaceTotal
+	if p.HasPattern {
+		p.Projected = p.PatternTotal
+	}
+
+	if p.DaysRemaining > 0 && !share {
+		p.RequiredPerDay = result.Remaining() / float64(p.DaysRemaining)
+	}
+
+	goal := result.Goal
+	switch {
+	case result.Over() || (goal.Max > 0 && p.Projected > goal.Max):
+		p.Status = StatusOver
+	case result.Achieved():
+		p.Status = StatusAchieved
+	case goal.Max == 0 && p.Projected >= goal.Target*aheadMargin:
+		p.Status = StatusAhead
+	case p.Projected >= goal.Target:
+		p.Status = StatusOnTrack
+	default:
+		p.Status = StatusBehind
+	}
+	return p
+}
+
+// weekdayPattern averages a goal's metric per weekday over the lookback weeks
+// before its period. It reports false when there is no history to learn from.
+func (e *Engine) weekdayPattern(result Result, activities []models.Activity) ([7]float64, bool) {
+	var pattern [7]float64
+	start := result.PeriodStart
+	lookback := start.AddDate(0, 0, -7*ProjectionLookbackWeeks)
+
+	// Shorten the lookback when the history does not reach back that far,
+	// treating the earliest activity as the start of the athlete's history
+	var earliest time.Time
+	for _, activity := range activities {
+		activityTime, err := e.Calendar.ActivityTime(activity)
+		if err != nil {
+			continue
+		}
+		if earliest.IsZero() || activityTime.Before(earliest) {
+			earliest = activityTime
+		}
+	}
+	if earliest.IsZero() || !earliest.Before(start) {
+		return pattern, false
+	}
+	weeks := ProjectionLookbackWeeks
+	if earliest.After(lookback) {
+		weeks = int(math.Ceil(start.Sub(earliest).Hours() / (24 * 7)))
+		lookback = start.AddDate(0, 0, -7*weeks)
+	}
+
+	for _, activity := range activities {
+		activityTime, err := e.Calendar.ActivityTime(activity)
+		if err != nil || activityTime.Before(lookback) || !activityTime.Before(start) || !result.Goal.Matches(activity) {
+			continue
+		}
+		pattern[activityTime.Weekday()] += e.value(result.Goal, activity)
+	}
+	for i := range pattern {
+		pattern[i] /= float64(weeks)
+	}
+	return pattern, true
+}
+
+// expectedUntil sums the weekday pattern from the wall-clock time from to end,
+// counting only the unelapsed part of the first day
+func expectedUntil(pattern [7]float64, from, end time.Time) float64 {
+	expected := 0.0
+	day := time.Date(from.Year(), from.Month(), from.Day(), 0, 0, 0, 0, time.UTC)
+	for ; day.Before(end); day = day.AddDate(0, 0, 1) {
+		share := 1.0
+		if day.Before(from) {
+			share = 1 - from.Sub(day).Hours()/24
+		}
+		expected += pattern[day.Weekday()] * share
+	}
+	return expected
+}
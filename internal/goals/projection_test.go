@@ -0,0 +1,92 @@
+package goals
+
+import (
+	"math"
+	"testing"
+	"time"
+
+	"strava-custom-goals/internal/models"
+)
+
+func TestProjectWithoutHistoryUsesPace(t *testing.T) {
+	goal := Goal{Name: "Running", Metric: MetricDistance, Period: Every(PeriodWeek), Target: 20}
+	activities := []models.Activity{run(6, "2025-10-06T07:00:00Z"), run(6, "2025-10-08T07:00:00Z")}
+	engine := NewEngine([]Goal{goal})
+
+	// Thursday 00:00: three of seven days elapsed
+	now := time.Date(2025, 10, 9, 0, 0, 0, 0, time.UTC)
+	result := engine.Evaluate(activities, now)[0]
+	p := engine.Project(result, activities, now)
+
+	if p.HasPattern {
+		t.Error("Expected no weekday pattern without earlier history")
+	}
+	if math.Abs(p.Elapsed-3.0/7) > 1e-9 || math.Abs(p.Projected-28) > 1e-9 {
+		t.Errorf("Expected 3/7 elapsed projecting 28 km, got %.3f and %.2f", p.Elapsed, p.Projected)
+	}
+	if p.DaysRemaining != 4 || p.RequiredPerDay != 2 {
+		t.Errorf("Expected 2 km/day for 4 days, got %.2f for %d", p.RequiredPerDay, p.DaysRemaining)
+	}
+	if p.Status != StatusAhead {
+		t.Errorf("Expected ahead, got %s", p.Status)
+	}
+}
+
+func TestProjectUsesWeekdayPattern(t *testing.T) {
+	goal := Goal{Name: "Running", Metric: MetricDistance, Period: Every(PeriodWeek), Target: 30}
+
+	// For the past 12 weeks: 5 km on Tuesdays and a 20 km long run on Sundays
+	var activities []models.Activity
+	weekStart := time.Date(2025, 10, 6, 0, 0, 0, 0, time.UTC)
+	for week := 1; week <= ProjectionLookbackWeeks; week++ {
+		monday := weekStart.AddDate(0, 0, -7*week)
+		activities = append(activities,
+			run(5, monday.AddDate(0, 0, 1).Add(7*time.Hour).Format(time.RFC3339)),
+			run(20, monday.AddDate(0, 0, 6).Add(7*time.Hour).Format(time.RFC3339)))
+	}
+	// This week so far: the usual Tuesday run
+	activities = append(activities, run(5, "2025-10-07T07:00:00Z"))
+
+	engine := NewEngine([]Goal{goal})
+	now := time.Date(2025, 10, 8, 0, 0, 0, 0, time.UTC) // Wednesday
+	result := engine.Evaluate(activities, now)[0]
+	p := engine.Project(result, activities, now)
+
+	// Pace alone says 5 km in 2 days -> 17.5 km, the pattern knows about Sunday
+	if !p.HasPattern || math.Abs(p.PatternTotal-25) > 1e-9 {
+		t.Errorf("Expected a pattern projection of 25 km, got %.2f (pattern %v)", p.PatternTotal, p.HasPattern)
+	}
+	if math.Abs(p.PaceTotal-17.5) > 1e-9 {
+		t.Errorf("Expected a pace projection of 17.5 km, got %.2f", p.PaceTotal)
+	}
+	if p.Projected != p.PatternTotal || p.Status != StatusBehind {
+		t.Errorf("Expected the pattern to drive a behind status, got %.2f %s", p.Projected, p.Status)
+	}
+}
+
+func TestProjectShortHistory(t *testing.T) {
+	goal := Goal{Name: "Running", Metric: MetricDistance, Period: Every(PeriodWeek), Target: 10}
+
+	// Only two weeks of history: 10 km each Saturday
+	activities := []models.Activity{run(10, "2025-09-27T07:00:00Z"), run(10, "2025-10-04T07:00:00Z")}
+	engine := NewEngine([]Goal{goal})
+	now := time.Date(2025, 10, 6, 0, 0, 0, 0, time.UTC) // Monday
+	result := engine.Evaluate(activities, now)[0]
+	p := engine.Project(result, activities, now)
+
+	if math.Abs(p.PatternTotal-10) > 1e-9 || p.Status != StatusOnTrack {
+		t.Errorf("Expected the two-week pattern to project 10 km (on track), got %.2f %s", p.PatternTotal, p.Status)
+	}
+}
+
+func TestProjectAchievedAndFinished(t *testing.T) {
+	goal := Goal{Name: "Block", Metric: MetricCount, Period: Range(time.Date(2025, 9, 1, 0, 0, 0, 0, time.UTC), time.Date(2025, 10, 1, 0, 0, 0, 0, time.UTC)), Target: 2}
+	activities := []models.Activity{run(5, "2025-09-10T07:00:00Z"), run(5, "2025-09-20T07:00:00Z")}
+	engine := NewEngine([]Goal{goal})
+
+	now := time.Date(2025, 10, 5, 0, 0, 0, 0, time.UTC)
+	p := engine.Project(engine.Evaluate(activities, now)[0], activities, now)
+	if p.Status != StatusAchieved || p.DaysRemaining != 0 || p.Elapsed != 1 {
+		t.Errorf("Expected an achieved, finished period, got %+v", p)
+	}
+}
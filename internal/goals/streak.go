@@ -0,0 +1,133 @@
+package goals
+
+import (
+	"fmt"
+	"time"
+
+	"strava-custom-goals/internal/models"
+)
+
+// ActiveDaysStreakName names the streak of consecutive days with any activity
+const ActiveDaysStreakName = "Active days"
+
+// Streak is a run of consecutive periods in which a goal was achieved
+type Streak struct {
+	Name         string
+	Period       PeriodKind
+	Current      int       // consecutive achieved periods up to now
+	Longest      int       // longest run ever, including the current one
+	LongestStart time.Time // wall-clock start of the longest run
+	LongestEnd   time.Time // wall-clock end of the longest run, exclusive
+	// AtRisk is set when the current period is not achieved yet: the
+	// current streak ends unless it is completed in time.
+	AtRisk bool
+}
+
+// Message describes the streak, e.g. "🔥 Running: 4-week streak (longest 9)"
+func (s Streak) Message() string {
+	switch {
+	case s.AtRisk && s.Period == PeriodDay:
+		return fmt.Sprintf("⚠️ %s: %d-day streak at risk, get moving today to keep it going (longest %d)",
+			s.Name, s.Current, s.Longest)
+	case s.AtRisk:
+		return fmt.Sprintf("⚠️ %s: %d-%s streak at risk, reach this %s's target to keep it going (longest %d)",
+			s.Name, s.Current, s.Period, s.Period, s.Longest)
+	case s.Current > 0:
+		return fmt.Sprintf("🔥 %s: %d-%s streak (longest %d)", s.Name, s.Current, s.Period, s.Longest)
+	case s.Longest > 0:
+		return fmt.Sprintf("💤 %s: no current streak (longest %d %ss)", s.Name, s.Longest, s.Period)
+	default:
+		return fmt.Sprintf("🌱 %s: no streak yet", s.Name)
+	}
+}
+
+// Streaks computes the streak of every recurring goal with a target, followed
+// by the active-days streak. Activities should cover the whole history.
+func (e *Engine) Streaks(activities []models.Activity, now time.Time) []Streak {
+	streaks := make([]Streak, 0, len(e.Goals)+1)
+	for _, goal := range e.Goals {
+		if goal.Period.Kind == PeriodCustom || goal.Target <= 0 {
+			continue
+		}
+		streaks = append(streaks, e.GoalStreak(goal, activities, now))
+	}
+	return append(streaks, e.ActiveDaysStreak(activities, now))
+}
+
+// StreaksFrom loads the full history from source and computes Streaks as of
+// the engine's clock
+func (e *Engine) StreaksFrom(source ActivitySource) ([]Streak, error) {
+	activities, err := source.ActivitiesSince(time.Time{})
+	if err != nil {
+		return nil, fmt.Errorf("load activities: %w", err)
+	}
+	return e.Streaks(activities, e.Clock.Now()), nil
+}
+
+// ActiveDaysStreak computes the streak of consecutive days with any activity
+func (e *Engine) ActiveDaysStreak(activities []models.Activity, now time.Time) Streak {
+	day := Goal{Name: ActiveDaysStreakName, Metric: MetricCount, Period: Every(PeriodDay), Target: 1}
+	return e.GoalStreak(day, activities, now)
+}
+
+// GoalStreak computes a recurring goal's streak as it stood at now
+func (e *Engine) GoalStreak(goal Goal, activities []models.Activity, now time.Time) Streak {
+	streak := Streak{Name: goal.Name, Period: goal.Period.Kind}
+	if goal.Period.Kind == PeriodCustom || goal.Target <= 0 {
+		return streak // A single window or a target that is always met has no streak
+	}
+
+	// Total the metric per period, keyed by the period's wall-clock start
+	totals := map[time.Time]tally{}
+	var earliest time.Time
+	for _, activity := range activities {
+		if start, err := activity.StartTime(); err != nil || start.After(now) {
+			continue
+		}
+		activityTime, err := e.Calendar.ActivityTime(activity)
+		if err != nil || !goal.Matches(activity) {
+			continue
+		}
+		start, _ := goal.Period.Window(activityTime, e.Calendar.WeekStart)
+		t := totals[start]
+		e.add(&t, goal, activity)
+		totals[start] = t
+		if earliest.IsZero() || start.Before(earliest) {
+			earliest = start
+		}
+	}
+
+	current, _ := e.Calendar.Window(goal.Period, now)
+	if earliest.IsZero() || earliest.After(current) {
+		return streak
+	}
+
+	// Walk every period from the first with activity up to the current one
+	run := 0
+	var runStart time.Time
+	for start := earliest; !start.After(current); {
+		_, end := goal.Period.Window(start, e.Calendar.WeekStart)
+
+		achieved := goal.Reached(totals[start].total(goal))
+		switch {
+		case achieved:
+			if run == 0 {
+				runStart = start
+			}
+			run++
+			if run > streak.Longest {
+				streak.Longest, streak.LongestStart, streak.LongestEnd = run, runStart, end
+			}
+		case start.Equal(current):
+			// The current period can still be completed
+			streak.AtRisk = run > 0
+		default:
+			run = 0
+		}
+
+		start = end
+	}
+
+	streak.Current = run
+	return streak
+}
@@ -0,0 +1,97 @@
+package goals
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"strava-custom-goals/internal/models"
+)
+
+// run returns a run of km kilometres starting at the given UTC time
+func run(km float64, start string) models.Activity {
+	return models.Activity{Type: "Run", Distance: km * 1000, StartDate: start}
+}
+
+func TestGoalStreakCountsConsecutiveWeeks(t *testing.T) {
+	goal := Goal{Name: "Running", Metric: MetricDistance, ActivityTypes: []string{"Run"}, Period: Every(PeriodWeek), Target: 10}
+	activities := []models.Activity{
+		// Three met weeks, a missed week, then two met weeks
+		run(12, "2025-09-01T07:00:00Z"),
+		run(11, "2025-09-08T07:00:00Z"),
+		run(6, "2025-09-15T07:00:00Z"), run(5, "2025-09-17T07:00:00Z"),
+		run(4, "2025-09-22T07:00:00Z"),
+		run(10, "2025-09-29T07:00:00Z"),
+		run(10, "2025-10-06T07:00:00Z"),
+	}
+	engine := NewEngine([]Goal{goal})
+
+	// Wednesday Oct 8: the current week is already met
+	streak := engine.GoalStreak(goal, activities, time.Date(2025, 10, 8, 12, 0, 0, 0, time.UTC))
+	if streak.Current != 2 || streak.Longest != 3 || streak.AtRisk {
+		t.Errorf("Expected current 2, longest 3, not at risk; got %+v", streak)
+	}
+	if !streak.LongestStart.Equal(time.Date(2025, 9, 1, 0, 0, 0, 0, time.UTC)) ||
+		!streak.LongestEnd.Equal(time.Date(2025, 9, 22, 0, 0, 0, 0, time.UTC)) {
+		t.Errorf("Expected the longest streak Sep 1 – Sep 22, got %v – %v", streak.LongestStart, streak.LongestEnd)
+	}
+
+	// Wednesday Oct 15: nothing yet this week, so the streak is at risk
+	streak = engine.GoalStreak(goal, activities, time.Date(2025, 10, 15, 12, 0, 0, 0, time.UTC))
+	if streak.Current != 2 || !streak.AtRisk {
+		t.Errorf("Expected a 2-week streak at risk, got %+v", streak)
+	}
+	if !strings.Contains(streak.Message(), "at risk") {
+		t.Errorf("Expected an at-risk message, got %q", streak.Message())
+	}
+
+	// A whole missed week breaks it
+	streak = engine.GoalStreak(goal, activities, time.Date(2025, 10, 22, 12, 0, 0, 0, time.UTC))
+	if streak.Current != 0 || streak.AtRisk || streak.Longest != 3 {
+		t.Errorf("Expected a broken streak with longest 3, got %+v", streak)
+	}
+}
+
+func TestGoalStreakIgnoresFutureActivities(t *testing.T) {
+	goal := Goal{Name: "Running", Metric: MetricDistance, Period: Every(PeriodWeek), Target: 10}
+	activities := []models.Activity{run(10, "2025-09-29T07:00:00Z"), run(10, "2025-10-10T07:00:00Z")}
+
+	// As of Oct 8 the run on Oct 10 has not happened yet
+	streak := NewEngine([]Goal{goal}).GoalStreak(goal, activities, time.Date(2025, 10, 8, 12, 0, 0, 0, time.UTC))
+	if streak.Current != 1 || !streak.AtRisk {
+		t.Errorf("Expected a 1-week streak at risk, got %+v", streak)
+	}
+}
+
+func TestActiveDaysStreak(t *testing.T) {
+	activities := []models.Activity{
+		run(5, "2025-10-01T07:00:00Z"),
+		run(5, "2025-10-02T07:00:00Z"),
+		{Type: "Yoga", MovingTime: 1800, StartDate: "2025-10-03T18:00:00Z"},
+		run(5, "2025-10-05T07:00:00Z"),
+		run(5, "2025-10-06T07:00:00Z"), run(3, "2025-10-06T18:00:00Z"),
+	}
+	engine := NewEngine(nil)
+
+	// Morning of Oct 7 before any activity: the two-day streak is at risk
+	streak := engine.ActiveDaysStreak(activities, time.Date(2025, 10, 7, 6, 0, 0, 0, time.UTC))
+	if streak.Name != ActiveDaysStreakName || streak.Current != 2 || streak.Longest != 3 || !streak.AtRisk {
+		t.Errorf("Expected current 2 at risk with longest 3, got %+v", streak)
+	}
+}
+
+func TestStreaksSkipsCustomAndTargetlessGoals(t *testing.T) {
+	goals := []Goal{
+		{Name: "Weekly", Metric: MetricCount, Period: Every(PeriodWeek), Target: 1},
+		{Name: "Block", Metric: MetricCount, Period: Range(time.Date(2025, 10, 1, 0, 0, 0, 0, time.UTC), time.Date(2025, 11, 1, 0, 0, 0, 0, time.UTC)), Target: 1},
+		{Name: "Tally", Metric: MetricCount, Period: Every(PeriodWeek)},
+	}
+	streaks := NewEngine(goals).Streaks([]models.Activity{run(5, "2025-10-06T07:00:00Z")}, time.Date(2025, 10, 6, 12, 0, 0, 0, time.UTC))
+
+	if len(streaks) != 2 || streaks[0].Name != "Weekly" || streaks[1].Name != ActiveDaysStreakName {
+		t.Fatalf("Expected the weekly and active-days streaks, got %+v", streaks)
+	}
+	if streaks[0].Current != 1 || streaks[1].Current != 1 {
+		t.Errorf("Expected both streaks to be 1, got %+v", streaks)
+	}
+}
@@ -1,4 +1,4 @@
-// Package goals provides functionality for tracking weekly fitness goals
+// Package goals provides functionality for tracking fitness goals
 package goals
 
 import (
@@ -21,67 +21,82 @@ type WeeklyProgress struct {
 	TotalActivities int
 	RunCount        int
 	WorkoutCount    int
+	RunningStreak   Streak // consecutive weeks the running goal was met
+	ActiveDays      Streak // consecutive days with any activity
+}
+
+// Names of the two built-in goals
+const (
+	RunningGoalName = "Running"
+	WorkoutGoalName = "Workout"
+)
+
+// WorkoutTypes are the activity types that count as a workout (gym, weight
+// lifting, strength training)
+var WorkoutTypes = []string{
+	"WeightTraining",
+	"Workout",
+	"Crossfit",
+	"StairStepper",
+	"Elliptical",
+	"Yoga",
+	"Pilates",
+	"RockClimbing",
+	"Swimming",
+}
+
+// Goals expresses the weekly running and workout targets as generic goals
+func (g WeeklyGoals) Goals() []Goal {
+	return []Goal{
+		{
+			Name:          RunningGoalName,
+			Metric:        MetricDistance,
+			ActivityTypes: []string{"Run"},
+			Period:        Every(PeriodWeek),
+			Target:        g.RunningGoalKm,
+		},
+		{
+			Name:          WorkoutGoalName,
+			Metric:        MetricMovingTime,
+			ActivityTypes: WorkoutTypes,
+			Period:        Every(PeriodWeek),
+			Target:        g.WorkoutGoalHours,
+		},
+	}
 }
 
 // CalculateWeeklyProgress calculates progress toward weekly goals from activities
 func CalculateWeeklyProgress(activities []models.Activity, goals WeeklyGoals) *WeeklyProgress {
+	return CalculateWeeklyProgressAt(activities, goals, time.Now())
+}
+
+// CalculateWeeklyProgressAt calculates weekly progress as it stood at now
+func CalculateWeeklyProgressAt(activities []models.Activity, goals WeeklyGoals, now time.Time) *WeeklyProgress {
 	progress := &WeeklyProgress{
 		Goals: goals,
 	}
 
-	// Get the start of current week (Monday)
-	now := time.Now()
-	weekday := now.Weekday()
-	if weekday == time.Sunday {
-		weekday = 7 // Treat Sunday as day 7
-	}
-	weekStart := now.AddDate(0, 0, -int(weekday-time.Monday))
-	weekStart = time.Date(weekStart.Year(), weekStart.Month(), weekStart.Day(), 0, 0, 0, 0, weekStart.Location())
-
-	for _, activity := range activities {
-		// Parse activity start date
-		activityTime, err := time.Parse(time.RFC3339, activity.StartDate)
-		if err != nil {
-			continue // Skip activities with invalid dates
-		}
-
-		// Only count activities from current week
-		if activityTime.Before(weekStart) {
-			continue
-		}
+	// A target-less count goal tallies every activity in the week, whatever its type
+	all := Goal{Name: "All", Metric: MetricCount, Period: Every(PeriodWeek)}
+	engine := NewEngine(append(goals.Goals(), all))
+	results := engine.Evaluate(activities, now)
 
-		progress.TotalActivities++
-
-		// Track running activities
-		if activity.Type == "Run" {
-			progress.RunningDistance += activity.DistanceKm
-			progress.RunCount++
-		}
+	progress.RunningDistance = results[0].Actual
+	progress.RunCount = results[0].Activities
+	progress.WorkoutHours = results[1].Actual
+	progress.WorkoutCount = results[1].Activities
+	progress.TotalActivities = results[2].Activities
 
-		// Track workout activities (gym, weight lifting, strength training)
-		if isWorkoutActivity(activity.Type) {
-			progress.WorkoutHours += activity.MovingTimeHours
-			progress.WorkoutCount++
-		}
-	}
+	// Streaks span however much history the activities cover
+	progress.RunningStreak = engine.GoalStreak(engine.Goals[0], activities, now)
+	progress.ActiveDays = engine.ActiveDaysStreak(activities, now)
 
 	return progress
 }
 
 // isWorkoutActivity determines if an activity type counts as a workout
 func isWorkoutActivity(activityType string) bool {
-	workoutTypes := map[string]bool{
-		"WeightTraining": true,
-		"Workout":        true,
-		"Crossfit":       true,
-		"StairStepper":   true,
-		"Elliptical":     true,
-		"Yoga":           true,
-		"Pilates":        true,
-		"RockClimbing":   true,
-		"Swimming":       true,
-	}
-	return workoutTypes[activityType]
+	return Goal{ActivityTypes: WorkoutTypes}.Matches(models.Activity{Type: activityType})
 }
 
 // GetRunningProgressPercentage returns running progress as percentage
@@ -154,3 +169,9 @@ func (p *WeeklyProgress) GetMotivationalMessage() string {
 		}
 	}
 }
+
+// GetStreakMessages returns the running and active-day streak messages shown
+// alongside the motivational message
+func (p *WeeklyProgress) GetStreakMessages() []string {
+	return []string{p.RunningStreak.Message(), p.ActiveDays.Message()}
+}
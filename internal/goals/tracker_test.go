@@ -8,9 +8,8 @@ import (
 )
 
 func TestCalculateWeeklyProgress(t *testing.T) {
-	// Test data - create mock activities
-	now := time.Now()
-	weekStart := now.AddDate(0, 0, -int(now.Weekday()-time.Monday))
+	// Test data - create mock activities around the week of Monday Oct 6, 2025
+	weekStart := time.Date(2025, 10, 6, 0, 0, 0, 0, time.UTC)
 
 	activities := []models.Activity{
 		{
@@ -40,30 +39,34 @@ func TestCalculateWeeklyProgress(t *testing.T) {
 		WorkoutGoalHours: 2.0,
 	}
 
-	progress := CalculateWeeklyProgress(activities, goals)
+	// Evaluate on every day of the week, including Sunday
+	for day := 3; day < 7; day++ {
+		now := weekStart.AddDate(0, 0, day).Add(18 * time.Hour)
+		progress := CalculateWeeklyProgressAt(activities, goals, now)
 
-	// Test running progress
-	if progress.RunningDistance != 5.0 {
-		t.Errorf("Expected running distance 5.0, got %f", progress.RunningDistance)
-	}
+		// Test running progress
+		if progress.RunningDistance != 5.0 {
+			t.Errorf("%s: expected running distance 5.0, got %f", now.Weekday(), progress.RunningDistance)
+		}
 
-	// Test workout progress
-	if progress.WorkoutHours != 1.0 {
-		t.Errorf("Expected workout hours 1.0, got %f", progress.WorkoutHours)
-	}
+		// Test workout progress
+		if progress.WorkoutHours != 1.0 {
+			t.Errorf("%s: expected workout hours 1.0, got %f", now.Weekday(), progress.WorkoutHours)
+		}
 
-	// Test counts
-	if progress.RunCount != 1 {
-		t.Errorf("Expected run count 1, got %d", progress.RunCount)
-	}
+		// Test counts
+		if progress.RunCount != 1 {
+			t.Errorf("%s: expected run count 1, got %d", now.Weekday(), progress.RunCount)
+		}
 
-	if progress.WorkoutCount != 1 {
-		t.Errorf("Expected workout count 1, got %d", progress.WorkoutCount)
-	}
+		if progress.WorkoutCount != 1 {
+			t.Errorf("%s: expected workout count 1, got %d", now.Weekday(), progress.WorkoutCount)
+		}
 
-	// Test total activities (should only count current week)
-	if progress.TotalActivities != 2 {
-		t.Errorf("Expected total activities 2, got %d", progress.TotalActivities)
+		// Test total activities (should only count current week)
+		if progress.TotalActivities != 2 {
+			t.Errorf("%s: expected total activities 2, got %d", now.Weekday(), progress.TotalActivities)
+		}
 	}
 }
 
@@ -102,3 +105,23 @@ func TestIsWorkoutActivity(t *testing.T) {
 		}
 	}
 }
+
+func TestWeeklyProgressStreaks(t *testing.T) {
+	activities := []models.Activity{
+		run(10, "2025-09-29T07:00:00Z"),
+		run(4, "2025-10-06T07:00:00Z"),
+		{Type: "WeightTraining", MovingTime: 3600, StartDate: "2025-10-07T07:00:00Z"},
+	}
+
+	progress := CalculateWeeklyProgressAt(activities, WeeklyGoals{RunningGoalKm: 10, WorkoutGoalHours: 2}, time.Date(2025, 10, 8, 12, 0, 0, 0, time.UTC))
+
+	if progress.RunningStreak.Current != 1 || !progress.RunningStreak.AtRisk {
+		t.Errorf("Expected a 1-week running streak at risk, got %+v", progress.RunningStreak)
+	}
+	if progress.ActiveDays.Current != 2 || !progress.ActiveDays.AtRisk {
+		t.Errorf("Expected a 2-day activity streak at risk, got %+v", progress.ActiveDays)
+	}
+	if len(progress.GetStreakMessages()) != 2 {
+		t.Errorf("Expected 2 streak messages, got %v", progress.GetStreakMessages())
+	}
+}
@@ -0,0 +1,288 @@
+package goals
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"sync"
+
+	"strava-custom-goals/internal/models"
+)
+
+// MaxSampleGap is the longest gap between stream samples, in seconds, that
+// still counts as time in a zone; longer gaps are pauses
+const MaxSampleGap = 30
+
+// ZoneKind selects the stream that zones are measured on
+type ZoneKind string
+
+// Zone kinds
+const (
+	ZoneKindHeartRate ZoneKind = "heartrate" // bpm
+	ZoneKindPower     ZoneKind = "power"     // watts
+)
+
+// Valid reports whether k is a supported zone kind
+func (k ZoneKind) Valid() bool {
+	return k == ZoneKindHeartRate || k == ZoneKindPower
+}
+
+// Zones holds the upper bound of every zone but the last, which is
+// open-ended: {120, 140} puts up to 120 in zone 1, up to 140 in zone 2 and
+// anything above in zone 3
+type Zones []int
+
+// Count returns the number of zones
+func (z Zones) Count() int {
+	if len(z) == 0 {
+		return 0
+	}
+	return len(z) + 1
+}
+
+// Of returns the zone a value falls in, counting from 1
+func (z Zones) Of(value int) int {
+	for i, bound := range z {
+		if value <= bound {
+			return i + 1
+		}
+	}
+	return len(z) + 1
+}
+
+// Validate checks that the bounds are positive and increasing
+func (z Zones) Validate() error {
+	for i, bound := range z {
+		if bound <= 0 {
+			return fmt.Errorf("zone bounds must be positive, got %d", bound)
+		}
+		if i > 0 && bound <= z[i-1] {
+			return fmt.Errorf("zone bounds must increase, got %d after %d", bound, z[i-1])
+		}
+	}
+	return nil
+}
+
+// HeartRateZonesFromMax derives five zones split at 60, 70, 80 and 90% of a
+// maximum heart rate
+func HeartRateZonesFromMax(max float64) Zones {
+	if max <= 0 {
+		return nil
+	}
+	return zonesFromPercents(max, 60, 70, 80, 90)
+}
+
+// PowerZonesFromFTP derives the seven Coggan power zones, split at 55, 75,
+// 90, 105, 120 and 150% of functional threshold power
+func PowerZonesFromFTP(ftp int) Zones {
+	if ftp <= 0 {
+		return nil
+	}
+	return zonesFromPercents(float64(ftp), 55, 75, 90, 105, 120, 150)
+}
+
+func zonesFromPercents(reference float64, percents ...float64) Zones {
+	zones := make(Zones, len(percents))
+	for i, percent := range percents {
+		zones[i] = int(reference * percent / 100)
+	}
+	return zones
+}
+
+// ZoneSettings holds an athlete's heart rate and power zones
+type ZoneSettings struct {
+	HeartRate Zones
+	Power     Zones
+}
+
+// Of returns the zones of a kind, nil when not configured
+func (s ZoneSettings) Of(kind ZoneKind) Zones {
+	switch kind {
+	case ZoneKindHeartRate:
+		return s.HeartRate
+	case ZoneKindPower:
+		return s.Power
+	default:
+		return nil
+	}
+}
+
+// ZoneRange selects consecutive zones of one kind: From to To inclusive, or
+// From and every zone above it when To is zero
+type ZoneRange struct {
+	Kind ZoneKind
+	From int
+	To   int
+}
+
+// ParseZoneRange parses a zone such as "2", a range such as "2-3", or an
+// open-ended range such as "5+"
+func ParseZoneRange(kind ZoneKind, s string) (ZoneRange, error) {
+	r := ZoneRange{Kind: kind}
+	var err error
+	switch from, to, isRange := strings.Cut(s, "-"); {
+	case strings.HasSuffix(s, "+"):
+		r.From, err = strconv.Atoi(strings.TrimSuffix(s, "+"))
+	case isRange:
+		r.From, err = strconv.Atoi(from)
+		if err == nil {
+			r.To, err = strconv.Atoi(to)
+		}
+	default:
+		r.From, err = strconv.Atoi(s)
+		r.To = r.From
+	}
+	if err != nil {
+		return ZoneRange{}, fmt.Errorf("zones must look like 2, 2-3 or 5+, got %q", s)
+	}
+	return r, r.Validate()
+}
+
+// IsZero reports whether no zones are selected
+func (r ZoneRange) IsZero() bool {
+	return r.From == 0
+}
+
+// Validate checks that the range selects at least one zone of a known kind
+func (r ZoneRange) Validate() error {
+	if !r.Kind.Valid() {
+		return fmt.Errorf("unknown zone type %q (expected heartrate or power)", r.Kind)
+	}
+	if r.From < 1 {
+		return fmt.Errorf("zones are numbered from 1, got %d", r.From)
+	}
+	if r.To != 0 && r.To < r.From {
+		return fmt.Errorf("zone range %d-%d ends before it starts", r.From, r.To)
+	}
+	return nil
+}
+
+// Contains reports whether the range includes a zone
+func (r ZoneRange) Contains(zone int) bool {
+	return zone >= r.From && (r.To == 0 || zone <= r.To)
+}
+
+// String describes the range, e.g. "HR zone 2" or "power zones 5+"
+func (r ZoneRange) String() string {
+	kind := "HR"
+	if r.Kind == ZoneKindPower {
+		kind = "power"
+	}
+	switch {
+	case r.To == 0:
+		return fmt.Sprintf("%s zones %d+", kind, r.From)
+	case r.To == r.From:
+		return fmt.Sprintf("%s zone %d", kind, r.From)
+	default:
+		return fmt.Sprintf("%s zones %d-%d", kind, r.From, r.To)
+	}
+}
+
+// ZoneTime is the time spent in each zone in seconds, zone 1 first
+type ZoneTime []int
+
+// Total returns the time spent across all zones
+func (t ZoneTime) Total() int {
+	total := 0
+	for _, seconds := range t {
+		total += seconds
+	}
+	return total
+}
+
+// In returns the time spent within a range of zones
+func (t ZoneTime) In(r ZoneRange) int {
+	seconds := 0
+	for i, zone := range t {
+		if r.Contains(i + 1) {
+			seconds += zone
+		}
+	}
+	return seconds
+}
+
+// Add returns the zone-by-zone sum of t and other
+func (t ZoneTime) Add(other ZoneTime) ZoneTime {
+	sum := make(ZoneTime, max(len(t), len(other)))
+	copy(sum, t)
+	for i, seconds := range other {
+		sum[i] += seconds
+	}
+	return sum
+}
+
+// TimeInZones computes how long the streams spent in each zone. It reports
+// false when the streams lack the series the zones are measured on.
+// Heart rate dropouts, recorded as zero, are left out.
+func TimeInZones(streams *models.Streams, kind ZoneKind, zones Zones) (ZoneTime, bool) {
+	var series []int
+	switch kind {
+	case ZoneKindHeartRate:
+		series = streams.Heartrate
+	case ZoneKindPower:
+		series = streams.Watts
+	}
+	if zones.Count() == 0 || len(series) == 0 || len(series) != streams.Len() {
+		return nil, false
+	}
+
+	durations := streams.SampleDurations(MaxSampleGap)
+	spent := make(ZoneTime, zones.Count())
+	for i, value := range series {
+		if kind == ZoneKindHeartRate && value <= 0 {
+			continue
+		}
+		spent[zones.Of(value)-1] += durations[i]
+	}
+	return spent, true
+}
+
+// StreamSource loads an activity's stored streams, or nil when they have not
+// been fetched
+type StreamSource interface {
+	Streams(activityID int64) (*models.Streams, error)
+}
+
+// zoneCache remembers computed zone times. Stored streams never change, so
+// entries stay valid for the engine's lifetime.
+type zoneCache struct {
+	mu    sync.Mutex
+	times map[zoneKey]ZoneTime
+}
+
+type zoneKey struct {
+	activityID int64
+	kind       ZoneKind
+}
+
+// ZoneTime returns the time an activity spent in each zone of a kind. It
+// reports false when its streams are not stored or cannot be loaded, lack the
+// series, or the zones are not configured; such activities count no zone time.
+func (e *Engine) ZoneTime(a models.Activity, kind ZoneKind) (ZoneTime, bool) {
+	zones := e.Zones.Of(kind)
+	if e.Streams == nil || zones.Count() == 0 {
+		return nil, false
+	}
+
+	key := zoneKey{activityID: a.ID, kind: kind}
+	if e.zoneTimes != nil {
+		e.zoneTimes.mu.Lock()
+		spent, cached := e.zoneTimes.times[key]
+		e.zoneTimes.mu.Unlock()
+		if cached {
+			return spent, spent != nil
+		}
+	}
+
+	streams, err := e.Streams.Streams(a.ID)
+	if err != nil || streams == nil {
+		return nil, false // Not cached: a later sync may still fetch them
+	}
+	spent, ok := TimeInZones(streams, kind, zones)
+	if e.zoneTimes != nil {
+		e.zoneTimes.mu.Lock()
+		e.zoneTimes.times[key] = spent
+		e.zoneTimes.mu.Unlock()
+	}
+	return spent, ok
+}
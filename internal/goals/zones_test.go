@@ -0,0 +1,147 @@
+package goals
+
+import (
+	"errors"
+	"math"
+	"testing"
+	"time"
+
+	"strava-custom-goals/internal/models"
+)
+
+// mapStreams serves streams by activity ID and counts lookups
+type mapStreams struct {
+	streams map[int64]*models.Streams
+	loads   int
+}
+
+func (m *mapStreams) Streams(id int64) (*models.Streams, error) {
+	m.loads++
+	if id < 0 {
+		return nil, errors.New("database locked")
+	}
+	return m.streams[id], nil
+}
+
+// steadyHeartRate records one sample per second at each heart rate for the
+// given number of seconds, in order
+func steadyHeartRate(bpms []int, seconds []int) *models.Streams {
+	s := &models.Streams{}
+	elapsed := 0
+	for i, bpm := range bpms {
+		for j := 0; j < seconds[i]; j++ {
+			s.Time = append(s.Time, elapsed)
+			s.Heartrate = append(s.Heartrate, bpm)
+			elapsed++
+		}
+	}
+	return s
+}
+
+func TestParseZoneRange(t *testing.T) {
+	testCases := map[string]ZoneRange{
+		"2":   {Kind: ZoneKindHeartRate, From: 2, To: 2},
+		"2-3": {Kind: ZoneKindHeartRate, From: 2, To: 3},
+		"5+":  {Kind: ZoneKindHeartRate, From: 5},
+	}
+	for input, want := range testCases {
+		if got, err := ParseZoneRange(ZoneKindHeartRate, input); err != nil || got != want {
+			t.Errorf("%s: expected %+v, got %+v, %v", input, want, got, err)
+		}
+	}
+	for _, input := range []string{"", "0", "3-2", "two", "2-", "+"} {
+		if _, err := ParseZoneRange(ZoneKindHeartRate, input); err == nil {
+			t.Errorf("%q: expected error, got nil", input)
+		}
+	}
+}
+
+func TestTimeInZonesSkipsPausesAndDropouts(t *testing.T) {
+	zones := HeartRateZonesFromMax(200) // 120, 140, 160, 180
+	streams := &models.Streams{
+		Time:      []int{0, 5, 10, 15, 300, 305},
+		Heartrate: []int{110, 130, 0, 150, 190, 150},
+	}
+
+	spent, ok := TimeInZones(streams, ZoneKindHeartRate, zones)
+	if !ok {
+		t.Fatal("Expected heart rate zones to be computed")
+	}
+	// The 150 bpm sample before the pause counts nothing; the last sample one second
+	want := ZoneTime{5, 5, 1, 0, 5}
+	for i := range want {
+		if spent[i] != want[i] {
+			t.Fatalf("Expected %v, got %v", want, spent)
+		}
+	}
+	if _, ok := TimeInZones(streams, ZoneKindPower, PowerZonesFromFTP(250)); ok {
+		t.Error("Expected no power zones without a watts stream")
+	}
+}
+
+func TestEngineEvaluatesZoneGoals(t *testing.T) {
+	source := &mapStreams{streams: map[int64]*models.Streams{
+		1: steadyHeartRate([]int{130, 170}, []int{1800, 600}), // 30 min zone 2, 10 min zone 4
+		2: steadyHeartRate([]int{135, 185}, []int{3000, 600}), // 50 min zone 2, 10 min zone 5
+		3: {},                                                 // manual activity, nothing recorded
+	}}
+	engine := NewEngine([]Goal{
+		{Name: "Zone 2", Metric: MetricZoneTime, Zones: ZoneRange{Kind: ZoneKindHeartRate, From: 2, To: 2}, Period: Every(PeriodWeek), Target: 150},
+		{Name: "Hard", Metric: MetricZoneShare, Zones: ZoneRange{Kind: ZoneKindHeartRate, From: 4}, Period: Every(PeriodWeek), Max: 20},
+	})
+	engine.Calendar = Calendar{Location: time.UTC, WeekStart: time.Monday}
+	engine.Zones = ZoneSettings{HeartRate: HeartRateZonesFromMax(200)}
+	engine.Streams = source
+
+	activities := []models.Activity{
+		{ID: 1, Type: "Run", StartDate: "2025-10-06T07:00:00Z"},
+		{ID: 2, Type: "Ride", StartDate: "2025-10-08T07:00:00Z"},
+		{ID: 3, Type: "Workout", StartDate: "2025-10-09T07:00:00Z"},
+		{ID: 4, Type: "Run", StartDate: "2025-10-09T18:00:00Z"}, // streams not fetched yet
+	}
+	now := time.Date(2025, 10, 10, 12, 0, 0, 0, time.UTC)
+	results := engine.Evaluate(activities, now)
+
+	zone2, hard := results[0], results[1]
+	if zone2.Actual != 80 || zone2.Activities != 4 {
+		t.Errorf("Expected 80 min in zone 2 from 4 activities, got %.1f from %d", zone2.Actual, zone2.Activities)
+	}
+	if len(zone2.Zones) != 5 || zone2.Zones[1] != 80*60 || zone2.Zones.Total() != 100*60 {
+		t.Errorf("Expected the period's zone distribution, got %v", zone2.Zones)
+	}
+	// 20 of 100 recorded minutes above zone 3: the share, not a sum of shares
+	if hard.Actual != 20 || !hard.Achieved() {
+		t.Errorf("Expected 20%% above zone 3 and achieved, got %.1f", hard.Actual)
+	}
+
+	projection := engine.Project(hard, activities, now)
+	if projection.Projected != 20 || projection.RequiredPerDay != 0 {
+		t.Errorf("Expected a share to be projected to hold, got %+v", projection)
+	}
+
+	// Stored streams are only read once per activity and zone kind
+	loads := source.loads
+	engine.Evaluate(activities, now)
+	if source.loads != loads+2 {
+		t.Errorf("Expected only the unfetched activity to be looked up again, once per goal, got %d more loads", source.loads-loads)
+	}
+}
+
+func TestZoneGoalsWithoutZonesOrStreamsCountNothing(t *testing.T) {
+	goal := Goal{Name: "Zone 2", Metric: MetricZoneTime, Zones: ZoneRange{Kind: ZoneKindPower, From: 2, To: 2}, Period: Every(PeriodWeek), Target: 60}
+	engine := NewEngine([]Goal{goal})
+	engine.Streams = &mapStreams{}
+	engine.Zones = ZoneSettings{HeartRate: HeartRateZonesFromMax(190)} // no FTP, so no power zones
+
+	// Streams of activity -1 fail to load
+	activities := []models.Activity{{ID: -1, Type: "Ride", StartDate: "2025-10-06T07:00:00Z"}}
+	now := time.Date(2025, 10, 8, 12, 0, 0, 0, time.UTC)
+	if result := engine.Evaluate(activities, now)[0]; result.Actual != 0 {
+		t.Errorf("Expected no zone time without power zones, got %+v", result)
+	}
+
+	engine.Zones.Power = PowerZonesFromFTP(250)
+	if result := engine.Evaluate(activities, now)[0]; result.Actual != 0 || math.IsNaN(result.Percent()) {
+		t.Errorf("Expected no zone time when streams fail to load, got %+v", result)
+	}
+}
@@ -0,0 +1,361 @@
+package importer
+
+import (
+	"archive/zip"
+	"encoding/csv"
+	"fmt"
+	"io"
+	"path"
+	"strconv"
+	"strings"
+	"time"
+
+	"strava-custom-goals/internal/models"
+)
+
+// archiveDateLayouts are the "Activity Date" formats of Strava exports, by
+// account language. Dates are in UTC.
+var archiveDateLayouts = []string{
+	"Jan 2, 2006, 3:04:05 PM",
+	"2 Jan 2006, 15:04:05",
+	"2006-01-02 15:04:05",
+}
+
+// archiveBatchSize is how many activities are parsed before they and their
+// streams are saved, so large exports are not held in memory at once
+const archiveBatchSize = 50
+
+// archiveTypes maps the activity type names of export CSVs that are not
+// the API's type with the spaces removed
+var archiveTypes = map[string]string{
+	"E-Bike Ride":          "EBikeRide",
+	"E-Mountain Bike Ride": "EMountainBikeRide",
+	"Rock Climb":           "RockClimbing",
+}
+
+// ArchiveStore keeps the activities of a bulk export and its sync state, so
+// the first sync afterwards only fetches what is newer
+type ArchiveStore interface {
+	Store
+	Streams(activityID int64) (*models.Streams, error)
+	DeleteActivities(ids []int64) error
+	SyncState() (models.SyncState, error)
+	SaveSyncState(state models.SyncState) error
+}
+
+// ArchiveResult summarises an imported bulk export
+type ArchiveResult struct {
+	Activities int // rows read from activities.csv
+	Added      int
+	Existing   int       // already stored, by a sync or an earlier import, and left as they are
+	Replaced   int       // activities imported from files earlier, replaced by their Strava copy
+	Streams    int       // activities whose streams were read from their original file
+	FileErrors []error   // original files that could not be read; sync fetches their streams instead
+	Watermark  time.Time // start of the newest activity
+}
+
+// ImportArchive imports the zip from Strava's "Download your data" export:
+// the activities in activities.csv, keeping their Strava IDs, with streams
+// from the original files it references. Activities already stored keep
+// their synced data, gaining only missing streams; ones imported from files
+// earlier are replaced by their Strava copy. The export has no local
+// start times, so they are taken in location; the sync re-check corrects
+// recent ones. Activities are saved in batches as their files are read, so
+// a failure part way keeps the batches before it. Unless dryRun is set, the
+// sync watermark moves to the newest activity.
+func ImportArchive(store ArchiveStore, file string, location *time.Location, dryRun bool) (*ArchiveResult, error) {
+	archive, err := zip.OpenReader(file)
+	if err != nil {
+		return nil, fmt.Errorf("open archive %s: %w", file, err)
+	}
+	defer archive.Close()
+
+	// Exports unpacked and re-zipped may have a top-level folder
+	entries := make(map[string]*zip.File, len(archive.File))
+	var index *zip.File
+	for _, f := range archive.File {
+		entries[f.Name] = f
+		if path.Base(f.Name) == "activities.csv" && (index == nil || len(f.Name) < len(index.Name)) {
+			index = f
+		}
+	}
+	if index == nil {
+		return nil, fmt.Errorf("%s has no activities.csv; is it a Strava export?", file)
+	}
+	root := path.Dir(index.Name)
+
+	rows, err := readArchiveIndex(index)
+	if err != nil {
+		return nil, err
+	}
+
+	stored, err := store.ActivitiesBetween(time.Time{}, time.Time{})
+	if err != nil {
+		return nil, err
+	}
+	existing := make(map[int64]bool, len(stored))
+	hasImported := false
+	for _, a := range stored {
+		existing[a.ID] = true
+		hasImported = hasImported || !isStrava(a)
+	}
+
+	result := &ArchiveResult{Activities: len(rows)}
+	var (
+		activities []models.Activity
+		streams    []models.Streams
+		replaced   []int64
+	)
+	// save stores the parsed batch; a dry run only counts it
+	save := func() error {
+		defer func() { activities, streams, replaced = activities[:0], streams[:0], replaced[:0] }()
+		if dryRun {
+			return nil
+		}
+		if _, _, err := store.UpsertActivities(activities); err != nil {
+			return fmt.Errorf("store activities: %w", err)
+		}
+		if err := store.DeleteActivities(replaced); err != nil {
+			return fmt.Errorf("delete replaced imports: %w", err)
+		}
+		now := time.Now()
+		for _, s := range streams {
+			if err := store.SaveStreams(s, now); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+	for _, row := range rows {
+		a := row.activity
+		start, _ := a.StartTime()
+		a.StartDateLocal = localDate(start, location)
+		if start.After(result.Watermark) {
+			result.Watermark = start
+		}
+
+		if existing[a.ID] {
+			result.Existing++
+			s, err := store.Streams(a.ID)
+			if err != nil {
+				return nil, err
+			}
+			if s != nil {
+				continue
+			}
+		}
+
+		switch entry := entries[path.Join(root, row.filename)]; {
+		case row.filename == "":
+			// Manual activity: store empty streams so sync does not ask for them
+			streams = append(streams, models.Streams{ActivityID: a.ID})
+		case entry == nil:
+			result.FileErrors = append(result.FileErrors, fmt.Errorf("activity %d: %s is missing from the archive", a.ID, row.filename))
+		default:
+			recorded, s, err := readArchiveFile(entry, location)
+			if err != nil {
+				result.FileErrors = append(result.FileErrors, fmt.Errorf("activity %d: %s: %w", a.ID, row.filename, err))
+				break
+			}
+			fillMissing(&a, recorded)
+			s.ActivityID = a.ID
+			streams = append(streams, *s)
+			result.Streams++
+		}
+		if !existing[a.ID] {
+			activities = append(activities, a)
+			result.Added++
+			if hasImported {
+				duplicate, err := findDuplicate(store, a, func(c models.Activity) bool { return !isStrava(c) })
+				if err != nil {
+					return nil, err
+				}
+				if duplicate != nil {
+					replaced = append(replaced, duplicate.ID)
+					result.Replaced++
+				}
+			}
+		}
+		if len(activities) >= archiveBatchSize || len(streams) >= archiveBatchSize {
+			if err := save(); err != nil {
+				return nil, err
+			}
+		}
+	}
+	// Rows skipped above may have ended the loop with a batch pending
+	if err := save(); err != nil {
+		return nil, err
+	}
+	if dryRun {
+		return result, nil
+	}
+
+	state, err := store.SyncState()
+	if err != nil {
+		return nil, fmt.Errorf("load sync state: %w", err)
+	}
+	if result.Watermark.After(state.Watermark) {
+		state.Watermark = result.Watermark
+		if err := store.SaveSyncState(state); err != nil {
+			return nil, fmt.Errorf("save sync state: %w", err)
+		}
+	}
+	return result, nil
+}
+
+// archiveRow is an activity read from activities.csv with its file, relative
+// to the archive root
+type archiveRow struct {
+	activity models.Activity
+	filename string
+}
+
+// readArchiveIndex reads activities.csv. Strava repeats some column names:
+// the first Distance is in kilometres and the first Elapsed Time rounded,
+// so the last occurrence of a name is used.
+func readArchiveIndex(f *zip.File) ([]archiveRow, error) {
+	rc, err := f.Open()
+	if err != nil {
+		return nil, fmt.Errorf("open activities.csv: %w", err)
+	}
+	defer rc.Close()
+
+	r := csv.NewReader(rc)
+	r.FieldsPerRecord = -1
+	r.LazyQuotes = true
+	header, err := r.Read()
+	if err != nil {
+		return nil, fmt.Errorf("read activities.csv header: %w", err)
+	}
+
+	columns := map[string]int{}
+	occurrences := map[string]int{}
+	for i, name := range header {
+		name = strings.TrimSpace(strings.TrimPrefix(name, "\ufeff"))
+		columns[name] = i
+		occurrences[name]++
+	}
+	for _, required := range []string{"Activity ID", "Activity Date", "Activity Type"} {
+		if _, ok := columns[required]; !ok {
+			return nil, fmt.Errorf("activities.csv has no %q column", required)
+		}
+	}
+
+	var rows []archiveRow
+	for line := 2; ; line++ {
+		record, err := r.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("read activities.csv: %w", err)
+		}
+		field := func(name string) string {
+			if i, ok := columns[name]; ok && i < len(record) {
+				return strings.TrimSpace(record[i])
+			}
+			return ""
+		}
+		// number reads an optional numeric column, keeping the first error
+		var numberErr error
+		number := func(name string) float64 {
+			text := field(name)
+			if text == "" {
+				return 0
+			}
+			value, err := strconv.ParseFloat(text, 64)
+			if err != nil && numberErr == nil {
+				numberErr = fmt.Errorf("activities.csv line %d: invalid %s %q", line, name, text)
+			}
+			return value
+		}
+
+		id, err := strconv.ParseInt(field("Activity ID"), 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("activities.csv line %d: invalid activity ID %q", line, field("Activity ID"))
+		}
+		start, err := parseArchiveDate(field("Activity Date"))
+		if err != nil {
+			return nil, fmt.Errorf("activities.csv line %d: %w", line, err)
+		}
+
+		a := models.Activity{
+			ID:               id,
+			Name:             field("Activity Name"),
+			Type:             archiveType(field("Activity Type")),
+			Source:           models.SourceStrava,
+			StartDate:        start.Format(time.RFC3339),
+			Distance:         number("Distance"),
+			ElapsedTime:      int(number("Elapsed Time")),
+			MovingTime:       int(number("Moving Time")),
+			TotalElevGain:    number("Elevation Gain"),
+			AverageSpeed:     number("Average Speed"),
+			MaxSpeed:         number("Max Speed"),
+			AverageHeartrate: number("Average Heart Rate"),
+		}
+		if numberErr != nil {
+			return nil, numberErr
+		}
+		if occurrences["Distance"] == 1 {
+			a.Distance *= 1000 // older exports only have the kilometres
+		}
+		if a.MovingTime == 0 {
+			a.MovingTime = a.ElapsedTime
+		}
+		if a.AverageSpeed == 0 && a.MovingTime > 0 {
+			a.AverageSpeed = a.Distance / float64(a.MovingTime)
+		}
+		a.HasHeartrate = a.AverageHeartrate > 0
+		rows = append(rows, archiveRow{activity: a, filename: field("Filename")})
+	}
+	return rows, nil
+}
+
+// parseArchiveDate parses an "Activity Date", tolerating the no-break
+// spaces some exports put before AM and PM
+func parseArchiveDate(s string) (time.Time, error) {
+	s = strings.NewReplacer("\u202f", " ", "\u00a0", " ").Replace(s)
+	for _, layout := range archiveDateLayouts {
+		if t, err := time.Parse(layout, s); err == nil {
+			return t, nil
+		}
+	}
+	return time.Time{}, fmt.Errorf("unrecognised activity date %q", s)
+}
+
+// archiveType converts an export's activity type, such as "Virtual Ride",
+// to the API's, such as "VirtualRide"
+func archiveType(name string) string {
+	if t, ok := archiveTypes[name]; ok {
+		return t
+	}
+	return strings.ReplaceAll(name, " ", "")
+}
+
+// readArchiveFile summarises an original activity file from the archive
+func readArchiveFile(f *zip.File, location *time.Location) (models.Activity, *models.Streams, error) {
+	rc, err := f.Open()
+	if err != nil {
+		return models.Activity{}, nil, err
+	}
+	defer rc.Close()
+
+	track, err := Parse(f.Name, rc)
+	if err != nil {
+		return models.Activity{}, nil, err
+	}
+	return track.Activity(location)
+}
+
+// fillMissing copies summary values the CSV left empty from the recording
+func fillMissing(a *models.Activity, recorded models.Activity) {
+	if a.Distance == 0 {
+		a.Distance, a.AverageSpeed, a.MaxSpeed = recorded.Distance, recorded.AverageSpeed, recorded.MaxSpeed
+	}
+	if a.TotalElevGain == 0 {
+		a.TotalElevGain = recorded.TotalElevGain
+	}
+	if !a.HasHeartrate && recorded.HasHeartrate {
+		a.HasHeartrate, a.AverageHeartrate = true, recorded.AverageHeartrate
+	}
+}
@@ -0,0 +1,196 @@
+package importer
+
+import (
+	"archive/zip"
+	"compress/gzip"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"strava-custom-goals/internal/models"
+	"strava-custom-goals/internal/store"
+)
+
+// testArchiveCSV follows Strava's export, including the repeated Elapsed
+// Time and Distance columns and a no-break space before PM
+const testArchiveCSV = "Activity ID,Activity Date,Activity Name,Activity Type,Elapsed Time,Distance,Filename," +
+	"Elapsed Time,Moving Time,Distance,Max Speed,Average Speed,Elevation Gain,Average Heart Rate\n" +
+	"101,\"Oct 6, 2025, 12:00:00 PM\",Lunch Run,Run,60,0.2,activities/101.gpx.gz,60.0,,,,,,\n" +
+	"102,\"Oct 7, 2025, 5:30:00\u202fPM\",Gym,Weight Training,3600,0,,3600.0,3000.0,0,,,,\n" +
+	"103,\"Oct 8, 2025, 6:00:00 AM\",Commute,E-Bike Ride,1200,8.5,activities/103.fit.gz,1200.0,1100.0,8500.0,9.1,7.7,40,\n" +
+	"104,\"Oct 1, 2025, 6:00:00 AM\",Synced,Run,1800,5.0,,1800.0,1750.0,5000.0,4.0,2.9,10,150\n"
+
+func writeTestArchive(t *testing.T, file string) {
+	t.Helper()
+	out, err := os.Create(file)
+	if err != nil {
+		t.Fatalf("Create archive: %v", err)
+	}
+	defer out.Close()
+	archive := zip.NewWriter(out)
+
+	w, _ := archive.Create("export_42/activities.csv")
+	w.Write([]byte(testArchiveCSV))
+	w, _ = archive.Create("export_42/activities/101.gpx.gz")
+	gz := gzip.NewWriter(w)
+	gz.Write([]byte(testGPX))
+	gz.Close()
+	// 103.fit.gz is referenced but missing
+	if err := archive.Close(); err != nil {
+		t.Fatalf("Write archive: %v", err)
+	}
+}
+
+func TestImportArchiveSeedsStoreAndWatermark(t *testing.T) {
+	dir := t.TempDir()
+	db, err := store.Open(filepath.Join(dir, "activities.db"))
+	if err != nil {
+		t.Fatalf("Open store: %v", err)
+	}
+	defer db.Close()
+	file := filepath.Join(dir, "export_42.zip")
+	writeTestArchive(t, file)
+
+	// Activity 104 was synced already and keeps its API data
+	db.UpsertActivities([]models.Activity{{ID: 104, Name: "Synced", Type: "Run", Kudos: 3,
+		StartDate: "2025-10-01T06:00:00Z", StartDateLocal: "2025-10-01T08:00:00Z"}})
+
+	dryRun, err := ImportArchive(db, file, time.UTC, true)
+	if err != nil || dryRun.Added != 3 {
+		t.Fatalf("Expected a dry run to find 3 new activities, got %+v, %v", dryRun, err)
+	}
+	if n, _ := db.Count(); n != 1 {
+		t.Fatalf("Expected a dry run to store nothing, got %d activities", n)
+	}
+
+	result, err := ImportArchive(db, file, time.UTC, false)
+	if err != nil {
+		t.Fatalf("ImportArchive returned error: %v", err)
+	}
+	if result.Activities != 4 || result.Added != 3 || result.Existing != 1 || result.Streams != 1 || len(result.FileErrors) != 1 {
+		t.Errorf("Expected 4 rows, 3 added, 1 existing, 1 file read and 1 missing, got %+v", result)
+	}
+
+	activities, _ := db.ActivitiesBetween(time.Time{}, time.Time{})
+	byID := map[int64]models.Activity{}
+	for _, a := range activities {
+		byID[a.ID] = a
+	}
+	if run := byID[101]; run.Source != models.SourceStrava || run.StartDate != "2025-10-06T12:00:00Z" ||
+		run.ElapsedTime != 60 || run.Distance < 199 || run.Distance > 201 || !run.HasHeartrate {
+		t.Errorf("Expected the run filled in from its GPX, got %+v", run)
+	}
+	if gym := byID[102]; gym.Type != "WeightTraining" || gym.MovingTime != 3000 || gym.Distance != 0 {
+		t.Errorf("Expected the gym session's type and moving time, got %+v", gym)
+	}
+	if ride := byID[103]; ride.Type != "EBikeRide" || ride.Distance != 8500 || ride.AverageSpeed != 7.7 || ride.TotalElevGain != 40 {
+		t.Errorf("Expected the ride's CSV values in meters, got %+v", ride)
+	}
+	if synced := byID[104]; synced.Kudos != 3 || synced.StartDateLocal != "2025-10-01T08:00:00Z" {
+		t.Errorf("Expected the synced activity left as it was, got %+v", synced)
+	}
+
+	// Activities without a file get empty streams; the missing file is left for sync
+	for id, want := range map[int64]bool{101: true, 102: true, 103: false, 104: true} {
+		if streams, _ := db.Streams(id); (streams != nil) != want {
+			t.Errorf("Activity %d: expected streams stored %v, got %+v", id, want, streams)
+		}
+	}
+	if ids, _ := db.ActivitiesWithoutStreams(10); len(ids) != 1 || ids[0] != 103 {
+		t.Errorf("Expected sync to fetch only the streams of activity 103, got %v", ids)
+	}
+
+	state, _ := db.SyncState()
+	if want := time.Date(2025, 10, 8, 6, 0, 0, 0, time.UTC); !state.Watermark.Equal(want) {
+		t.Errorf("Expected the watermark at the newest activity %v, got %v", want, state.Watermark)
+	}
+
+	if Format("export_42/activities/103.fit.gz") != FormatFIT || Format("activities.csv") != "" {
+		t.Error("Expected gzipped files to be recognised by their inner extension")
+	}
+}
+
+func TestImportArchiveReplacesImportedFiles(t *testing.T) {
+	dir := t.TempDir()
+	db, err := store.Open(filepath.Join(dir, "activities.db"))
+	if err != nil {
+		t.Fatalf("Open store: %v", err)
+	}
+	defer db.Close()
+	file := filepath.Join(dir, "export_42.zip")
+	writeTestArchive(t, file)
+
+	// The lunch run was imported from its GPX before the export
+	gpx := filepath.Join(dir, "lunch.gpx")
+	os.WriteFile(gpx, []byte(testGPX), 0600)
+	imported, _, err := (&Importer{Store: db, Location: time.UTC}).ImportFile(gpx)
+	if err != nil {
+		t.Fatalf("ImportFile returned error: %v", err)
+	}
+
+	result, err := ImportArchive(db, file, time.UTC, false)
+	if err != nil {
+		t.Fatalf("ImportArchive returned error: %v", err)
+	}
+	if result.Added != 4 || result.Replaced != 1 {
+		t.Errorf("Expected 4 added, 1 replacing an import, got %+v", result)
+	}
+	activities, _ := db.ActivitiesBetween(time.Time{}, time.Time{})
+	for _, a := range activities {
+		if a.ID == imported.ID {
+			t.Errorf("Expected the imported GPX to be replaced by activity 101, got %+v", a)
+		}
+	}
+	if streams, _ := db.Streams(imported.ID); streams != nil {
+		t.Errorf("Expected the import's streams to be deleted with it, got %+v", streams)
+	}
+}
+
+func TestImportArchiveReportsInvalidNumbers(t *testing.T) {
+	dir := t.TempDir()
+	db, err := store.Open(filepath.Join(dir, "activities.db"))
+	if err != nil {
+		t.Fatalf("Open store: %v", err)
+	}
+	defer db.Close()
+
+	file := filepath.Join(dir, "export.zip")
+	out, _ := os.Create(file)
+	archive := zip.NewWriter(out)
+	w, _ := archive.Create("activities.csv")
+	w.Write([]byte("Activity ID,Activity Date,Activity Type,Moving Time\n" +
+		"101,\"Oct 6, 2025, 12:00:00 PM\",Run,\n" +
+		"102,\"Oct 7, 2025, 12:00:00 PM\",Run,1:00:00\n"))
+	archive.Close()
+	out.Close()
+
+	_, err = ImportArchive(db, file, time.UTC, true)
+	if err == nil || !strings.Contains(err.Error(), "line 3") || !strings.Contains(err.Error(), "Moving Time") {
+		t.Errorf("Expected an error naming line 3 and Moving Time, got %v", err)
+	}
+}
+
+func TestImportArchiveSavesBatchEndingWithSyncedActivity(t *testing.T) {
+	dir := t.TempDir()
+	db, err := store.Open(filepath.Join(dir, "activities.db"))
+	if err != nil {
+		t.Fatalf("Open store: %v", err)
+	}
+	defer db.Close()
+	file := filepath.Join(dir, "export_42.zip")
+	writeTestArchive(t, file)
+
+	// The last row, activity 104, is synced already with its streams
+	db.UpsertActivities([]models.Activity{{ID: 104, Name: "Synced", Type: "Run", StartDate: "2025-10-01T06:00:00Z"}})
+	db.SaveStreams(models.Streams{ActivityID: 104, Time: []int{0, 1}}, time.Now())
+
+	result, err := ImportArchive(db, file, time.UTC, false)
+	if err != nil {
+		t.Fatalf("ImportArchive returned error: %v", err)
+	}
+	if n, _ := db.Count(); result.Added != 3 || n != 4 {
+		t.Errorf("Expected the 3 added activities to be stored alongside the synced one, got %d added and %d stored", result.Added, n)
+	}
+}
@@ -0,0 +1,283 @@
+package importer
+
+import (
+	"encoding/binary"
+	"fmt"
+	"io"
+	"math"
+	"time"
+)
+
+// FIT global message and field numbers read from files
+const (
+	fitMessageSport   = 12
+	fitMessageSession = 18
+	fitMessageRecord  = 20
+
+	fitFieldTimestamp = 253
+)
+
+// fitEpoch is the start of FIT time, 1989-12-31T00:00:00Z
+var fitEpoch = time.Date(1989, 12, 31, 0, 0, 0, 0, time.UTC)
+
+// fitSports maps FIT sport numbers to Strava activity types
+var fitSports = map[int64]string{
+	1:  "Run",
+	2:  "Ride",
+	4:  "Workout", // fitness equipment
+	5:  "Swim",
+	10: "Workout", // training
+	11: "Walk",
+	17: "Hike",
+}
+
+// fitField is one field of a definition message
+type fitField struct {
+	num, size, baseType byte
+}
+
+// fitDefinition describes the layout of a local message type's data messages
+type fitDefinition struct {
+	global    uint16
+	order     binary.ByteOrder
+	fields    []fitField
+	devFields int // bytes of developer data to skip
+}
+
+// ParseFIT reads the records of a FIT activity file. Only the fields needed
+// for the summary and streams are decoded; everything else is skipped.
+func ParseFIT(r io.Reader) (*Track, error) {
+	raw, err := io.ReadAll(r)
+	if err != nil {
+		return nil, fmt.Errorf("read FIT file: %w", err)
+	}
+	if len(raw) < 12 || (raw[0] != 12 && raw[0] != 14) || string(raw[8:12]) != ".FIT" {
+		return nil, fmt.Errorf("not a FIT file")
+	}
+	headerSize := int(raw[0])
+	end := headerSize + int(binary.LittleEndian.Uint32(raw[4:8]))
+	if len(raw) < end+2 {
+		return nil, fmt.Errorf("FIT file is truncated")
+	}
+	if fitCRC(raw[:end]) != binary.LittleEndian.Uint16(raw[end:end+2]) {
+		return nil, fmt.Errorf("FIT file is corrupt (checksum mismatch)")
+	}
+
+	d := &fitDecoder{data: raw[headerSize:end], track: &Track{Format: FormatFIT}}
+	if err := d.decode(); err != nil {
+		return nil, err
+	}
+	return d.track, nil
+}
+
+// fitDecoder walks the messages of a FIT file's data section
+type fitDecoder struct {
+	data        []byte
+	pos         int
+	definitions [16]*fitDefinition
+	timestamp   uint32 // of the last message that had one, for compressed headers
+	track       *Track
+}
+
+func (d *fitDecoder) decode() error {
+	for d.pos < len(d.data) {
+		header := d.data[d.pos]
+		d.pos++
+
+		switch {
+		case header&0x80 != 0:
+			// Compressed timestamp header: a 5-bit offset from the last timestamp
+			offset := uint32(header & 0x1F)
+			timestamp := d.timestamp&^0x1F | offset
+			if offset < d.timestamp&0x1F {
+				timestamp += 0x20
+			}
+			d.timestamp = timestamp
+			if err := d.message(int(header>>5&0x03), true); err != nil {
+				return err
+			}
+		case header&0x40 != 0:
+			if err := d.definition(int(header&0x0F), header&0x20 != 0); err != nil {
+				return err
+			}
+		default:
+			if err := d.message(int(header&0x0F), false); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// take returns the next n bytes of data
+func (d *fitDecoder) take(n int) ([]byte, error) {
+	if d.pos+n > len(d.data) {
+		return nil, fmt.Errorf("FIT message at byte %d runs past the end of the data", d.pos)
+	}
+	b := d.data[d.pos : d.pos+n]
+	d.pos += n
+	return b, nil
+}
+
+func (d *fitDecoder) definition(local int, developer bool) error {
+	fixed, err := d.take(5)
+	if err != nil {
+		return err
+	}
+	def := &fitDefinition{order: binary.LittleEndian}
+	if fixed[1] == 1 {
+		def.order = binary.BigEndian
+	}
+	def.global = def.order.Uint16(fixed[2:4])
+
+	fields, err := d.take(3 * int(fixed[4]))
+	if err != nil {
+		return err
+	}
+	for i := 0; i < len(fields); i += 3 {
+		def.fields = append(def.fields, fitField{num: fields[i], size: fields[i+1], baseType: fields[i+2]})
+	}
+
+	if developer {
+		count, err := d.take(1)
+		if err != nil {
+			return err
+		}
+		devFields, err := d.take(3 * int(count[0]))
+		if err != nil {
+			return err
+		}
+		for i := 0; i < len(devFields); i += 3 {
+			def.devFields += int(devFields[i+1])
+		}
+	}
+	d.definitions[local] = def
+	return nil
+}
+
+// message decodes a data message. Messages with a compressed header carry
+// their timestamp in it rather than in a field.
+func (d *fitDecoder) message(local int, compressed bool) error {
+	def := d.definitions[local]
+	if def == nil {
+		return fmt.Errorf("FIT data message at byte %d has no definition", d.pos-1)
+	}
+
+	values := map[byte]int64{}
+	for _, field := range def.fields {
+		b, err := d.take(int(field.size))
+		if err != nil {
+			return err
+		}
+		if value, ok := field.value(b, def.order); ok {
+			values[field.num] = value
+		}
+	}
+	if _, err := d.take(def.devFields); err != nil {
+		return err
+	}
+
+	if timestamp, ok := values[fitFieldTimestamp]; ok && !compressed {
+		d.timestamp = uint32(timestamp)
+	}
+
+	switch def.global {
+	case fitMessageRecord:
+		d.record(values)
+	case fitMessageSession, fitMessageSport:
+		// Both carry the sport, in field 5 of a session and field 0 of a sport
+		field := byte(5)
+		if def.global == fitMessageSport {
+			field = 0
+		}
+		if sport, ok := values[field]; ok && d.track.Type == "" {
+			d.track.Type = fitSports[sport]
+		}
+	}
+	return nil
+}
+
+// record adds a track point from a record message
+func (d *fitDecoder) record(values map[byte]int64) {
+	p := Point{Time: fitEpoch.Add(time.Duration(d.timestamp) * time.Second)}
+
+	lat, hasLat := values[0]
+	lng, hasLng := values[1]
+	if hasLat && hasLng {
+		semicircles := 180 / math.Pow(2, 31)
+		p.Lat, p.Lng, p.HasPosition = float64(lat)*semicircles, float64(lng)*semicircles, true
+	}
+	// Enhanced fields supersede their 16-bit originals
+	for _, field := range []byte{2, 78} {
+		if altitude, ok := values[field]; ok {
+			p.Altitude, p.HasAltitude = float64(altitude)/5-500, true
+		}
+	}
+	if distance, ok := values[5]; ok {
+		p.Distance = float64(distance) / 100
+	}
+	p.HeartRate = int(values[3])
+	p.Cadence = int(values[4])
+	p.Power = int(values[7])
+	d.track.Points = append(d.track.Points, p)
+}
+
+// value decodes a single integer field, reporting false for the base
+// type's invalid value, arrays, strings and floats
+func (f fitField) value(b []byte, order binary.ByteOrder) (int64, bool) {
+	var size byte
+	switch f.baseType & 0x1F {
+	case 0x00, 0x01, 0x02, 0x0A, 0x0D: // enum, sint8, uint8, uint8z, byte
+		size = 1
+	case 0x03, 0x04, 0x0B: // sint16, uint16, uint16z
+		size = 2
+	case 0x05, 0x06, 0x0C: // sint32, uint32, uint32z
+		size = 4
+	}
+	if size == 0 || f.size != size {
+		return 0, false
+	}
+
+	var u uint64
+	switch size {
+	case 1:
+		u = uint64(b[0])
+	case 2:
+		u = uint64(order.Uint16(b))
+	case 4:
+		u = uint64(order.Uint32(b))
+	}
+	bits := 8 * uint(size)
+	allOnes := uint64(1)<<bits - 1
+
+	switch f.baseType & 0x1F {
+	case 0x01, 0x03, 0x05:
+		if u == allOnes>>1 {
+			return 0, false
+		}
+		// Sign-extend from the field's width
+		shift := 64 - bits
+		return int64(u<<shift) >> shift, true
+	case 0x0A, 0x0B, 0x0C:
+		return int64(u), u != 0
+	default:
+		return int64(u), u != allOnes
+	}
+}
+
+// fitCRC computes the FIT checksum of data
+func fitCRC(data []byte) uint16 {
+	table := [16]uint16{
+		0x0000, 0xCC01, 0xD801, 0x1400, 0xF001, 0x3C00, 0x2800, 0xE401,
+		0xA001, 0x6C00, 0x7800, 0xB401, 0x5000, 0x9C01, 0x8801, 0x4400,
+	}
+	var crc uint16
+	for _, b := range data {
+		for _, nibble := range []byte{b & 0x0F, b >> 4} {
+			tmp := table[crc&0x0F]
+			crc = (crc >> 4) & 0x0FFF
+			crc = crc ^ tmp ^ table[nibble]
+		}
+	}
+	return crc
+}
@@ -0,0 +1,106 @@
+package importer
+
+import (
+	"bytes"
+	"encoding/binary"
+	"testing"
+	"time"
+)
+
+// fitBuilder writes the data section of a FIT file
+type fitBuilder struct {
+	data bytes.Buffer
+}
+
+// define writes a little-endian definition of a local message type; each
+// field is {number, size, base type}
+func (b *fitBuilder) define(local byte, global uint16, fields ...[3]byte) {
+	b.data.WriteByte(0x40 | local)
+	b.data.Write([]byte{0, 0})
+	binary.Write(&b.data, binary.LittleEndian, global)
+	b.data.WriteByte(byte(len(fields)))
+	for _, field := range fields {
+		b.data.Write(field[:])
+	}
+}
+
+// message writes a data message with a normal header, values in field order
+func (b *fitBuilder) message(local byte, values ...interface{}) {
+	b.data.WriteByte(local)
+	for _, value := range values {
+		binary.Write(&b.data, binary.LittleEndian, value)
+	}
+}
+
+// file wraps the data section in a 14-byte header and appends the checksum
+func (b *fitBuilder) file() []byte {
+	var file bytes.Buffer
+	file.Write([]byte{14, 0x20})
+	binary.Write(&file, binary.LittleEndian, uint16(2132))
+	binary.Write(&file, binary.LittleEndian, uint32(b.data.Len()))
+	file.WriteString(".FIT")
+	file.Write([]byte{0, 0}) // header checksum is optional
+	file.Write(b.data.Bytes())
+	binary.Write(&file, binary.LittleEndian, fitCRC(file.Bytes()))
+	return file.Bytes()
+}
+
+func TestParseFITDecodesRecordsAndSport(t *testing.T) {
+	start := time.Date(2025, 10, 8, 6, 30, 0, 0, time.UTC)
+	timestamp := uint32(start.Sub(fitEpoch).Seconds())
+	semicircles := func(degrees float64) int32 { return int32(degrees / 180 * (1 << 31)) }
+
+	var b fitBuilder
+	b.define(0, fitMessageRecord,
+		[3]byte{253, 4, 0x86}, // timestamp
+		[3]byte{0, 4, 0x85},   // position_lat
+		[3]byte{1, 4, 0x85},   // position_long
+		[3]byte{2, 2, 0x84},   // altitude
+		[3]byte{3, 1, 0x02},   // heart_rate
+		[3]byte{5, 4, 0x86},   // distance
+		[3]byte{7, 2, 0x84},   // power
+	)
+	b.message(0, timestamp, semicircles(-33.9), semicircles(18.4), uint16((100+500)*5), uint8(130), uint32(0), uint16(0xFFFF))
+	b.message(0, timestamp+5, semicircles(-33.9001), semicircles(18.4), uint16((102+500)*5), uint8(0xFF), uint32(1500), uint16(250))
+
+	// A record with a developer field, and one with a compressed timestamp
+	b.data.WriteByte(0x40 | 0x20 | 1)
+	b.data.Write([]byte{0, 0})
+	binary.Write(&b.data, binary.LittleEndian, uint16(fitMessageRecord))
+	b.data.Write([]byte{1, 3, 1, 0x02, 1, 0, 2, 0})
+	b.message(1, uint8(140), uint16(0xBEEF))
+	b.define(2, fitMessageRecord, [3]byte{3, 1, 0x02})
+	b.data.WriteByte(0x80 | 2<<5 | byte((timestamp+12)&0x1F))
+	b.data.WriteByte(150)
+
+	b.define(3, fitMessageSession, [3]byte{5, 1, 0x00})
+	b.message(3, uint8(1))
+
+	track, err := ParseFIT(bytes.NewReader(b.file()))
+	if err != nil {
+		t.Fatalf("ParseFIT returned error: %v", err)
+	}
+	if track.Type != "Run" || track.Format != FormatFIT || len(track.Points) != 4 {
+		t.Fatalf("Expected a FIT Run with 4 points, got %+v", track)
+	}
+
+	first, second := track.Points[0], track.Points[1]
+	if !first.Time.Equal(start) || !first.HasPosition || first.Lat > -33.899 || first.Lat < -33.901 || first.Altitude != 100 || first.HeartRate != 130 || first.Power != 0 {
+		t.Errorf("Expected the first record decoded, got %+v", first)
+	}
+	if second.HeartRate != 0 || second.Distance != 15 || second.Power != 250 {
+		t.Errorf("Expected invalid heart rate skipped and distance in meters, got %+v", second)
+	}
+	if track.Points[2].HeartRate != 140 || !track.Points[2].Time.Equal(start.Add(5*time.Second)) {
+		t.Errorf("Expected the developer field skipped, got %+v", track.Points[2])
+	}
+	if last := track.Points[3]; last.HeartRate != 150 || !last.Time.Equal(start.Add(12*time.Second)) {
+		t.Errorf("Expected the compressed timestamp 12s in, got %+v", last)
+	}
+
+	corrupt := b.file()
+	corrupt[20] ^= 0xFF
+	if _, err := ParseFIT(bytes.NewReader(corrupt)); err == nil {
+		t.Error("Expected an error for a corrupt FIT file")
+	}
+}
@@ -0,0 +1,98 @@
+package importer
+
+import (
+	"encoding/xml"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+)
+
+// gpxFile is the part of a GPX 1.0/1.1 document that is read. Tags match
+// any namespace, so Garmin's TrackPointExtension fields are found whatever
+// prefix the file gives them.
+type gpxFile struct {
+	Name   string `xml:"metadata>name"`
+	Tracks []struct {
+		Name     string `xml:"name"`
+		Type     string `xml:"type"`
+		Segments []struct {
+			Points []struct {
+				Lat       *float64 `xml:"lat,attr"`
+				Lon       *float64 `xml:"lon,attr"`
+				Elevation *float64 `xml:"ele"`
+				Time      string   `xml:"time"`
+				HeartRate float64  `xml:"extensions>TrackPointExtension>hr"`
+				Cadence   float64  `xml:"extensions>TrackPointExtension>cad"`
+				Power     float64  `xml:"extensions>power"`
+			} `xml:"trkpt"`
+		} `xml:"trkseg"`
+	} `xml:"trk"`
+}
+
+// ParseGPX reads the tracks of a GPX file as one recording
+func ParseGPX(r io.Reader) (*Track, error) {
+	var doc gpxFile
+	if err := xml.NewDecoder(r).Decode(&doc); err != nil {
+		return nil, fmt.Errorf("decode GPX: %w", err)
+	}
+	if len(doc.Tracks) == 0 {
+		return nil, fmt.Errorf("no tracks in GPX file")
+	}
+
+	track := &Track{Format: FormatGPX, Name: doc.Name}
+	for _, trk := range doc.Tracks {
+		if track.Name == "" {
+			track.Name = strings.TrimSpace(trk.Name)
+		}
+		if track.Type == "" {
+			track.Type = activityType(trk.Type)
+		}
+		for _, segment := range trk.Segments {
+			for _, trkpt := range segment.Points {
+				p := Point{
+					HeartRate: int(trkpt.HeartRate),
+					Cadence:   int(trkpt.Cadence),
+					Power:     int(trkpt.Power),
+				}
+				if trkpt.Time != "" {
+					t, err := time.Parse(time.RFC3339, strings.TrimSpace(trkpt.Time))
+					if err != nil {
+						return nil, fmt.Errorf("track point time %q: %w", trkpt.Time, err)
+					}
+					p.Time = t
+				}
+				if trkpt.Lat != nil && trkpt.Lon != nil {
+					p.Lat, p.Lng, p.HasPosition = *trkpt.Lat, *trkpt.Lon, true
+				}
+				if trkpt.Elevation != nil {
+					p.Altitude, p.HasAltitude = *trkpt.Elevation, true
+				}
+				track.Points = append(track.Points, p)
+			}
+		}
+	}
+	return track, nil
+}
+
+// activityType maps the sport names used by GPX and TCX files to Strava
+// activity types, or "" when unknown. Strava's own GPX exports number
+// rides 1 and runs 9.
+func activityType(sport string) string {
+	switch strings.ToLower(strings.TrimSpace(sport)) {
+	case "run", "running", "trail_running", "treadmill_running", "9":
+		return "Run"
+	case "ride", "cycling", "biking", "road_biking", "mountain_biking", "indoor_cycling", "1":
+		return "Ride"
+	case "swim", "swimming", "lap_swimming", "open_water_swimming":
+		return "Swim"
+	case "walk", "walking":
+		return "Walk"
+	case "hike", "hiking":
+		return "Hike"
+	case "other", "workout", "training":
+		return "Workout"
+	default:
+		return ""
+	}
+}
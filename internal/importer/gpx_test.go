@@ -0,0 +1,56 @@
+package importer
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+const testGPX = `<?xml version="1.0" encoding="UTF-8"?>
+<gpx version="1.1" creator="Garmin Connect" xmlns="http://www.topografix.com/GPX/1/1"
+  xmlns:gpxtpx="http://www.garmin.com/xmlschemas/TrackPointExtension/v1">
+  <trk>
+    <name>Lunch Run</name>
+    <type>running</type>
+    <trkseg>
+      <trkpt lat="51.5000" lon="-0.1000"><ele>10.0</ele><time>2025-10-06T12:00:00Z</time>
+        <extensions><gpxtpx:TrackPointExtension><gpxtpx:hr>120</gpxtpx:hr><gpxtpx:cad>80</gpxtpx:cad></gpxtpx:TrackPointExtension></extensions>
+      </trkpt>
+      <trkpt lat="51.5009" lon="-0.1000"><ele>14.0</ele><time>2025-10-06T12:00:30Z</time>
+        <extensions><gpxtpx:TrackPointExtension><gpxtpx:hr>140</gpxtpx:hr></gpxtpx:TrackPointExtension></extensions>
+      </trkpt>
+    </trkseg>
+    <trkseg>
+      <trkpt lat="51.5018" lon="-0.1000"><ele>12.0</ele><time>2025-10-06T12:01:00Z</time></trkpt>
+    </trkseg>
+  </trk>
+</gpx>`
+
+func TestParseGPXReadsPointsAcrossSegments(t *testing.T) {
+	track, err := ParseGPX(strings.NewReader(testGPX))
+	if err != nil {
+		t.Fatalf("ParseGPX returned error: %v", err)
+	}
+	if track.Name != "Lunch Run" || track.Type != "Run" || track.Format != FormatGPX {
+		t.Errorf("Expected a GPX Run named Lunch Run, got %+v", track)
+	}
+	if len(track.Points) != 3 {
+		t.Fatalf("Expected 3 points, got %d", len(track.Points))
+	}
+
+	first := track.Points[0]
+	if !first.HasPosition || first.Lat != 51.5 || first.Lng != -0.1 || first.Altitude != 10 ||
+		first.HeartRate != 120 || first.Cadence != 80 {
+		t.Errorf("Expected the first point's position, elevation and sensors, got %+v", first)
+	}
+	if want := time.Date(2025, 10, 6, 12, 0, 0, 0, time.UTC); !first.Time.Equal(want) {
+		t.Errorf("Expected time %v, got %v", want, first.Time)
+	}
+	if track.Points[2].HeartRate != 0 {
+		t.Errorf("Expected no heart rate on the last point, got %d", track.Points[2].HeartRate)
+	}
+
+	if _, err := ParseGPX(strings.NewReader(`<gpx version="1.1"></gpx>`)); err == nil {
+		t.Error("Expected an error for a GPX file without tracks")
+	}
+}
@@ -0,0 +1,253 @@
+// Package importer reads activities recorded outside Strava from GPX, TCX and
+// FIT files, and whole histories from Strava bulk exports, and stores them
+// alongside the synchronised ones.
+package importer
+
+import (
+	"compress/gzip"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"strava-custom-goals/internal/models"
+)
+
+// Supported file formats, also used as the source tag of imported activities
+const (
+	FormatGPX = "gpx"
+	FormatTCX = "tcx"
+	FormatFIT = "fit"
+)
+
+// Duplicate tolerances: an imported activity matches a stored one that
+// started within DuplicateStartWindow and lasted about as long
+const (
+	DuplicateStartWindow   = 2 * time.Minute
+	DuplicateDurationSlack = 0.1 // share of the elapsed time
+)
+
+// Track is a recording parsed from a file
+type Track struct {
+	Format string
+	Name   string // empty when the file has none
+	Type   string // Strava activity type, empty when the file does not say
+	Points []Point
+}
+
+// Point is a single recorded sample. Missing values are zero, except that
+// positions are only set when HasPosition is.
+type Point struct {
+	Time        time.Time
+	Lat, Lng    float64
+	HasPosition bool
+	Altitude    float64 // meters
+	HasAltitude bool
+	Distance    float64 // meters from the start as recorded by the device, zero if not
+	HeartRate   int
+	Cadence     int
+	Power       int
+}
+
+// Store keeps imported activities with the synchronised ones
+type Store interface {
+	ActivitiesBetween(after, before time.Time) ([]models.Activity, error)
+	UpsertActivities(activities []models.Activity) (added, updated int, err error)
+	SaveStreams(streams models.Streams, fetchedAt time.Time) error
+	SaveImport(activity models.Activity, streams models.Streams, fetchedAt time.Time) error
+}
+
+// Importer parses files and stores their activities, skipping any that
+// duplicate a stored activity
+type Importer struct {
+	Store    Store
+	Location *time.Location // wall-clock zone for local start times; nil means the system zone
+	Type     string         // overrides the activity type read from files
+	DryRun   bool           // parse and check for duplicates without storing
+}
+
+// ImportFile imports one file. When the activity duplicates a stored one,
+// nothing is stored and that activity is returned as the duplicate.
+func (im *Importer) ImportFile(path string) (activity models.Activity, duplicate *models.Activity, err error) {
+	track, err := ParseFile(path)
+	if err != nil {
+		return models.Activity{}, nil, err
+	}
+	if track.Name == "" {
+		base := strings.TrimSuffix(filepath.Base(path), ".gz")
+		track.Name = strings.TrimSuffix(base, filepath.Ext(base))
+	}
+	if im.Type != "" {
+		track.Type = im.Type
+	}
+
+	activity, streams, err := track.Activity(im.Location)
+	if err != nil {
+		return models.Activity{}, nil, fmt.Errorf("%s: %w", path, err)
+	}
+
+	duplicate, err = FindDuplicate(im.Store, activity)
+	if err != nil || duplicate != nil || im.DryRun {
+		return activity, duplicate, err
+	}
+
+	if err := im.Store.SaveImport(activity, *streams, time.Now()); err != nil {
+		return activity, nil, err
+	}
+	return activity, nil, nil
+}
+
+// FindDuplicate returns a stored activity that started within
+// DuplicateStartWindow of a and lasted about as long, or nil
+func FindDuplicate(store Store, a models.Activity) (*models.Activity, error) {
+	return findDuplicate(store, a, func(models.Activity) bool { return true })
+}
+
+// ReplaceStore can remove imported activities once Strava has a copy
+type ReplaceStore interface {
+	Store
+	ImportedActivities() ([]models.Activity, error)
+	DeleteActivities(ids []int64) error
+}
+
+// RemoveReplacedImports deletes imported activities that duplicate a stored
+// Strava activity, which carries more data, such as one synced after its
+// file was imported. It returns the number deleted.
+func RemoveReplacedImports(store ReplaceStore) (int, error) {
+	imported, err := store.ImportedActivities()
+	if err != nil {
+		return 0, err
+	}
+	var replaced []int64
+	for _, a := range imported {
+		duplicate, err := findDuplicate(store, a, isStrava)
+		if err != nil {
+			return 0, err
+		}
+		if duplicate != nil {
+			replaced = append(replaced, a.ID)
+		}
+	}
+	if len(replaced) == 0 {
+		return 0, nil
+	}
+	if err := store.DeleteActivities(replaced); err != nil {
+		return 0, fmt.Errorf("delete replaced imports: %w", err)
+	}
+	return len(replaced), nil
+}
+
+// isStrava reports whether a stored activity was synchronised from Strava
+func isStrava(a models.Activity) bool {
+	return a.Source == models.SourceStrava
+}
+
+// findDuplicate is FindDuplicate limited to stored activities matching keep
+func findDuplicate(store Store, a models.Activity, keep func(models.Activity) bool) (*models.Activity, error) {
+	start, err := a.StartTime()
+	if err != nil {
+		return nil, fmt.Errorf("activity %d: invalid start date %q", a.ID, a.StartDate)
+	}
+	candidates, err := store.ActivitiesBetween(start.Add(-DuplicateStartWindow-time.Second), start.Add(DuplicateStartWindow+time.Second))
+	if err != nil {
+		return nil, err
+	}
+
+	for i, candidate := range candidates {
+		if !keep(candidate) {
+			continue
+		}
+		slack := DuplicateDurationSlack * float64(max(a.ElapsedTime, candidate.ElapsedTime))
+		if diff := a.ElapsedTime - candidate.ElapsedTime; float64(diff) <= slack && float64(-diff) <= slack {
+			return &candidates[i], nil
+		}
+	}
+	return nil, nil
+}
+
+// ParseFile parses a GPX, TCX or FIT file, chosen by its extension
+func ParseFile(path string) (*Track, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("open %s: %w", path, err)
+	}
+	defer f.Close()
+
+	track, err := Parse(path, f)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	return track, nil
+}
+
+// Parse reads a recording in the format named by its file name, which may
+// be gzip-compressed as in Strava's bulk exports
+func Parse(name string, r io.Reader) (*Track, error) {
+	format := Format(name)
+	if format == "" {
+		return nil, fmt.Errorf("unsupported file type (expected .gpx, .tcx or .fit, optionally gzipped)")
+	}
+	if strings.HasSuffix(strings.ToLower(name), ".gz") {
+		gz, err := gzip.NewReader(r)
+		if err != nil {
+			return nil, fmt.Errorf("decompress: %w", err)
+		}
+		defer gz.Close()
+		r = gz
+	}
+
+	switch format {
+	case FormatGPX:
+		return ParseGPX(r)
+	case FormatTCX:
+		return ParseTCX(r)
+	default:
+		return ParseFIT(r)
+	}
+}
+
+// Format returns the format of a file from its extension, ignoring a .gz
+// suffix, or "" when it is not supported
+func Format(path string) string {
+	path = strings.TrimSuffix(strings.ToLower(path), ".gz")
+	switch ext := strings.TrimPrefix(filepath.Ext(path), "."); ext {
+	case FormatGPX, FormatTCX, FormatFIT:
+		return ext
+	default:
+		return ""
+	}
+}
+
+// Files expands paths into the supported files they name, walking
+// directories recursively, in lexical order
+func Files(paths []string) ([]string, error) {
+	var files []string
+	for _, path := range paths {
+		info, err := os.Stat(path)
+		if err != nil {
+			return nil, err
+		}
+		if !info.IsDir() {
+			files = append(files, path)
+			continue
+		}
+		err = filepath.WalkDir(path, func(file string, d fs.DirEntry, err error) error {
+			if err != nil {
+				return err
+			}
+			if !d.IsDir() && Format(file) != "" {
+				files = append(files, file)
+			}
+			return nil
+		})
+		if err != nil {
+			return nil, fmt.Errorf("walk %s: %w", path, err)
+		}
+	}
+	sort.Strings(files)
+	return files, nil
+}
@@ -0,0 +1,107 @@
+package importer
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"strava-custom-goals/internal/models"
+	"strava-custom-goals/internal/store"
+)
+
+func TestImportFileStoresActivityAndSkipsDuplicates(t *testing.T) {
+	dir := t.TempDir()
+	db, err := store.Open(filepath.Join(dir, "activities.db"))
+	if err != nil {
+		t.Fatalf("Open store: %v", err)
+	}
+	defer db.Close()
+
+	gpx := filepath.Join(dir, "lunch.gpx")
+	tcx := filepath.Join(dir, "rides", "turbo.tcx")
+	os.MkdirAll(filepath.Dir(tcx), 0700)
+	os.WriteFile(gpx, []byte(testGPX), 0600)
+	os.WriteFile(tcx, []byte(testTCX), 0600)
+	os.WriteFile(filepath.Join(dir, "notes.txt"), []byte("not a track"), 0600)
+
+	files, err := Files([]string{dir})
+	if err != nil || len(files) != 2 || files[0] != gpx || files[1] != tcx {
+		t.Fatalf("Expected the GPX and TCX files, got %v, %v", files, err)
+	}
+
+	// The turbo session was already synced from Strava, started a minute later
+	db.UpsertActivities([]models.Activity{
+		{ID: 42, Name: "Zwift", Type: "Ride", ElapsedTime: 11, StartDate: "2025-10-07T06:01:00Z"},
+	})
+
+	im := &Importer{Store: db, Location: time.UTC}
+	activity, duplicate, err := im.ImportFile(gpx)
+	if err != nil || duplicate != nil {
+		t.Fatalf("Expected the GPX to import, got %v, %v", duplicate, err)
+	}
+	if activity.Name != "Lunch Run" || activity.Type != "Run" || activity.Source != FormatGPX {
+		t.Errorf("Expected the GPX's name, type and source, got %+v", activity)
+	}
+
+	_, duplicate, err = im.ImportFile(tcx)
+	if err != nil || duplicate == nil || duplicate.ID != 42 {
+		t.Errorf("Expected the TCX to duplicate activity 42, got %v, %v", duplicate, err)
+	}
+
+	// Importing the GPX again finds the first import
+	_, duplicate, _ = im.ImportFile(gpx)
+	if duplicate == nil || duplicate.ID != activity.ID {
+		t.Errorf("Expected the re-import to duplicate %d, got %v", activity.ID, duplicate)
+	}
+
+	if n, _ := db.Count(); n != 2 {
+		t.Errorf("Expected 2 stored activities, got %d", n)
+	}
+	streams, err := db.Streams(activity.ID)
+	if err != nil || streams == nil || streams.Len() != 3 || len(streams.Heartrate) != 3 {
+		t.Errorf("Expected the GPX's 3 samples to be stored as streams, got %+v, %v", streams, err)
+	}
+
+	// A dry run of a later recording applies the type override but stores nothing
+	os.WriteFile(gpx, []byte(strings.ReplaceAll(testGPX, "2025-10-06", "2025-10-13")), 0600)
+	im.DryRun, im.Type = true, "Hike"
+	activity, duplicate, err = im.ImportFile(gpx)
+	if err != nil || duplicate != nil || activity.Type != "Hike" {
+		t.Errorf("Expected a new Hike, got %+v, %v, %v", activity, duplicate, err)
+	}
+	if n, _ := db.Count(); n != 2 {
+		t.Errorf("Expected a dry run to store nothing, got %d activities", n)
+	}
+}
+
+func TestRemoveReplacedImportsKeepsStravaCopies(t *testing.T) {
+	dir := t.TempDir()
+	db, err := store.Open(filepath.Join(dir, "activities.db"))
+	if err != nil {
+		t.Fatalf("Open store: %v", err)
+	}
+	defer db.Close()
+
+	gpx := filepath.Join(dir, "lunch.gpx")
+	os.WriteFile(gpx, []byte(testGPX), 0600)
+	if _, _, err := (&Importer{Store: db, Location: time.UTC}).ImportFile(gpx); err != nil {
+		t.Fatalf("ImportFile returned error: %v", err)
+	}
+	if removed, err := RemoveReplacedImports(db); err != nil || removed != 0 {
+		t.Errorf("Expected nothing removed before the Strava copy is synced, got %d, %v", removed, err)
+	}
+
+	// A later sync brings in the Strava copy of the same run
+	db.UpsertActivities([]models.Activity{
+		{ID: 42, Name: "Lunch Run", Type: "Run", ElapsedTime: 60, StartDate: "2025-10-06T12:00:05Z"},
+	})
+	if removed, err := RemoveReplacedImports(db); err != nil || removed != 1 {
+		t.Errorf("Expected the import to be removed, got %d, %v", removed, err)
+	}
+	activities, _ := db.ActivitiesBetween(time.Time{}, time.Time{})
+	if len(activities) != 1 || activities[0].ID != 42 {
+		t.Errorf("Expected only the Strava copy, got %+v", activities)
+	}
+}
@@ -0,0 +1,230 @@
+package importer
+
+import (
+	"encoding/binary"
+	"fmt"
+	"hash/fnv"
+	"math"
+	"sort"
+	"time"
+
+	"strava-custom-goals/internal/models"
+)
+
+// Thresholds for summarising a track
+const (
+	PauseGap      = 30  // seconds between samples beyond which the time is a pause
+	MovingSpeed   = 0.5 // m/s below which a sample counts as standing still
+	ClimbNoise    = 2.0 // meters of altitude change ignored as noise
+	earthRadiusKm = 6371.0088
+)
+
+// DefaultType is the activity type of tracks that do not name one
+const DefaultType = "Workout"
+
+// Activity summarises the track as an activity with its streams. The ID is
+// negative, so it never collides with Strava IDs, and derived from the
+// recorded points, so recordings starting in the same second still differ;
+// ImportFile skips recordings that are already stored.
+func (t *Track) Activity(location *time.Location) (models.Activity, *models.Streams, error) {
+	points := make([]Point, 0, len(t.Points))
+	for _, p := range t.Points {
+		if !p.Time.IsZero() {
+			points = append(points, p)
+		}
+	}
+	if len(points) == 0 {
+		return models.Activity{}, nil, fmt.Errorf("no timed track points")
+	}
+	sort.SliceStable(points, func(i, j int) bool { return points[i].Time.Before(points[j].Time) })
+
+	start := points[0].Time
+	a := models.Activity{
+		ID:             recordingID(points),
+		Name:           t.Name,
+		Type:           t.Type,
+		Source:         t.Format,
+		StartDate:      start.UTC().Format(time.RFC3339),
+		StartDateLocal: localDate(start, location),
+		ElapsedTime:    int(points[len(points)-1].Time.Sub(start).Seconds()),
+	}
+	if a.Type == "" {
+		a.Type = DefaultType
+	}
+
+	streams := t.streams(points, start)
+	streams.ActivityID = a.ID
+	if n := len(streams.Distance); n > 0 {
+		a.Distance = streams.Distance[n-1]
+	}
+
+	// Moving time counts the gaps spent above walking pace, or every gap
+	// short of a pause when the track has no distance
+	durations := streams.SampleDurations(PauseGap)
+	for i := 1; i < len(points); i++ {
+		gap := durations[i-1]
+		if gap == 0 {
+			continue
+		}
+		if len(streams.Distance) > 0 {
+			speed := (streams.Distance[i] - streams.Distance[i-1]) / float64(gap)
+			if speed < MovingSpeed {
+				continue
+			}
+			a.MaxSpeed = math.Max(a.MaxSpeed, speed)
+		}
+		a.MovingTime += gap
+	}
+	if a.MovingTime > 0 {
+		a.AverageSpeed = a.Distance / float64(a.MovingTime)
+	}
+
+	a.TotalElevGain = climb(streams.Altitude)
+
+	// Average heart rate weights each reading by the time it stands for, as
+	// moving time does, so denser recording in one part does not skew it. If
+	// every reading precedes a pause, they are averaged plainly.
+	beats, seconds, plainBeats, samples := 0, 0, 0, 0
+	for i, bpm := range streams.Heartrate {
+		if bpm > 0 {
+			beats += bpm * durations[i]
+			seconds += durations[i]
+			plainBeats += bpm
+			samples++
+		}
+	}
+	if seconds == 0 {
+		beats, seconds = plainBeats, samples
+	}
+	if samples > 0 {
+		a.HasHeartrate = true
+		a.AverageHeartrate = math.Round(float64(beats)/float64(seconds)*10) / 10
+	}
+	return a, streams, nil
+}
+
+// streams turns the points into one series per recorded value. Series the
+// track never recorded are left empty; gaps within recorded ones repeat the
+// previous position, altitude or distance, or are zero for sensors.
+func (t *Track) streams(points []Point, start time.Time) *models.Streams {
+	var hasPosition, hasAltitude, hasDistance, hasHeartRate, hasCadence, hasPower bool
+	for _, p := range points {
+		hasPosition = hasPosition || p.HasPosition
+		hasAltitude = hasAltitude || p.HasAltitude
+		hasDistance = hasDistance || p.Distance > 0
+		hasHeartRate = hasHeartRate || p.HeartRate > 0
+		hasCadence = hasCadence || p.Cadence > 0
+		hasPower = hasPower || p.Power > 0
+	}
+
+	s := &models.Streams{}
+	var last Point
+	distance := 0.0
+	for i, p := range points {
+		s.Time = append(s.Time, int(p.Time.Sub(start).Seconds()))
+
+		// Prefer the device's distance, else measure between positions
+		switch {
+		case p.Distance > 0:
+			distance = math.Max(distance, p.Distance)
+		case !hasDistance && i > 0 && p.HasPosition && last.HasPosition:
+			distance += haversine(last.Lat, last.Lng, p.Lat, p.Lng)
+		}
+		if hasDistance || hasPosition {
+			s.Distance = append(s.Distance, distance)
+		}
+
+		if p.HasPosition {
+			last.Lat, last.Lng, last.HasPosition = p.Lat, p.Lng, true
+		}
+		if hasPosition {
+			s.LatLng = append(s.LatLng, [2]float64{last.Lat, last.Lng})
+		}
+		if p.HasAltitude {
+			last.Altitude, last.HasAltitude = p.Altitude, true
+		}
+		if hasAltitude {
+			s.Altitude = append(s.Altitude, last.Altitude)
+		}
+		if hasHeartRate {
+			s.Heartrate = append(s.Heartrate, p.HeartRate)
+		}
+		if hasCadence {
+			s.Cadence = append(s.Cadence, p.Cadence)
+		}
+		if hasPower {
+			s.Watts = append(s.Watts, p.Power)
+		}
+	}
+
+	// Positions before the first fix take the first one
+	if hasPosition {
+		for i := range points {
+			if points[i].HasPosition {
+				for j := 0; j < i; j++ {
+					s.LatLng[j] = s.LatLng[i]
+				}
+				break
+			}
+		}
+	}
+
+	if len(s.Distance) > 1 {
+		s.Velocity = make([]float64, len(s.Distance))
+		for i := 1; i < len(s.Distance); i++ {
+			if gap := s.Time[i] - s.Time[i-1]; gap > 0 {
+				s.Velocity[i] = (s.Distance[i] - s.Distance[i-1]) / float64(gap)
+			}
+		}
+	}
+	return s
+}
+
+// recordingID hashes the timed points of a recording into a negative ID
+// small enough to survive JSON readers that use doubles
+func recordingID(points []Point) int64 {
+	h := fnv.New64a()
+	for _, p := range points {
+		binary.Write(h, binary.LittleEndian, p.Time.UnixNano())
+		binary.Write(h, binary.LittleEndian, []float64{p.Lat, p.Lng, p.Altitude, p.Distance,
+			float64(p.HeartRate), float64(p.Cadence), float64(p.Power)})
+	}
+	return -int64(h.Sum64()>>11) - 1
+}
+
+// localDate renders the wall-clock time of t in location, or the system
+// zone when nil, with a Z suffix as Strava writes local start dates
+func localDate(t time.Time, location *time.Location) string {
+	if location == nil {
+		location = time.Local
+	}
+	return t.In(location).Format("2006-01-02T15:04:05") + "Z"
+}
+
+// climb sums the altitude gained, ignoring changes smaller than ClimbNoise
+func climb(altitudes []float64) float64 {
+	if len(altitudes) == 0 {
+		return 0
+	}
+	gain, low := 0.0, altitudes[0]
+	for _, altitude := range altitudes[1:] {
+		switch {
+		case altitude >= low+ClimbNoise:
+			gain += altitude - low
+			low = altitude
+		case altitude < low:
+			low = altitude
+		}
+	}
+	return math.Round(gain*10) / 10
+}
+
+// haversine returns the great-circle distance in meters between two points
+func haversine(lat1, lng1, lat2, lng2 float64) float64 {
+	toRadians := math.Pi / 180
+	dLat := (lat2 - lat1) * toRadians
+	dLng := (lng2 - lng1) * toRadians
+	h := math.Sin(dLat/2)*math.Sin(dLat/2) +
+		math.Cos(lat1*toRadians)*math.Cos(lat2*toRadians)*math.Sin(dLng/2)*math.Sin(dLng/2)
+	return 2 * earthRadiusKm * 1000 * math.Asin(math.Sqrt(h))
+}
@@ -0,0 +1,66 @@
+package importer
+
+import (
+	"math"
+	"testing"
+	"time"
+)
+
+func TestTrackActivitySummarisesPoints(t *testing.T) {
+	start := time.Date(2025, 10, 6, 12, 0, 0, 0, time.UTC)
+	at := func(seconds int) time.Time { return start.Add(time.Duration(seconds) * time.Second) }
+	track := &Track{Format: FormatGPX, Name: "Lunch Run", Points: []Point{
+		{Time: at(10), Lat: 0, Lng: 0.0009, HasPosition: true, Altitude: 11, HasAltitude: true, HeartRate: 150},
+		{Time: at(0), Lat: 0, Lng: 0, HasPosition: true, Altitude: 10, HasAltitude: true, HeartRate: 140},
+		{Time: at(20), Lat: 0, Lng: 0.0018, HasPosition: true, Altitude: 14, HasAltitude: true},
+		{Time: at(320), Lat: 0, Lng: 0.0027, HasPosition: true, Altitude: 9, HasAltitude: true},  // after a pause
+		{Time: at(330), Lat: 0, Lng: 0.0027, HasPosition: true, Altitude: 12, HasAltitude: true}, // standing still
+		{Time: at(340), Lat: 0, Lng: 0.0036, HasPosition: true, Altitude: 10.5, HasAltitude: true, HeartRate: 160},
+	}}
+
+	paris, _ := time.LoadLocation("Europe/Paris")
+	a, streams, err := track.Activity(paris)
+	if err != nil {
+		t.Fatalf("Activity returned error: %v", err)
+	}
+
+	if a.ID >= 0 || streams.ActivityID != a.ID || a.Source != FormatGPX || a.Type != DefaultType {
+		t.Errorf("Expected ID, source and default type from the track, got %+v", a)
+	}
+	if a.StartDate != "2025-10-06T12:00:00Z" || a.StartDateLocal != "2025-10-06T14:00:00Z" {
+		t.Errorf("Expected UTC and Paris wall-clock start dates, got %s and %s", a.StartDate, a.StartDateLocal)
+	}
+	// 0.0009 degrees of longitude at the equator is about 100 m
+	if math.Abs(a.Distance-400.3) > 0.5 {
+		t.Errorf("Expected about 400 m, got %.1f", a.Distance)
+	}
+	if a.ElapsedTime != 340 || a.MovingTime != 30 {
+		t.Errorf("Expected 340 s elapsed and 30 s moving, got %d and %d", a.ElapsedTime, a.MovingTime)
+	}
+	// Climbs from 10 to 14 m and from 9 to 12 m
+	if a.TotalElevGain != 7 {
+		t.Errorf("Expected 7 m climbed, got %.1f", a.TotalElevGain)
+	}
+	// 140 and 150 bpm stand for 10 s each, the final 160 bpm for 1 s
+	if !a.HasHeartrate || a.AverageHeartrate != 145.7 {
+		t.Errorf("Expected a time-weighted average of 145.7 bpm, got %v %.1f", a.HasHeartrate, a.AverageHeartrate)
+	}
+	if streams.Len() != 6 || len(streams.LatLng) != 6 || len(streams.Velocity) != 6 || len(streams.Watts) != 0 {
+		t.Errorf("Expected 6 samples with positions and no power, got %+v", streams)
+	}
+
+	// Another recording started in the same second gets its own ID, while
+	// the same recording parsed again keeps it
+	other := &Track{Points: append([]Point{}, track.Points...)}
+	other.Points[1].HeartRate = 141
+	if b, _, _ := other.Activity(paris); b.ID == a.ID || b.ID >= 0 {
+		t.Errorf("Expected a different negative ID for another recording, got %d and %d", a.ID, b.ID)
+	}
+	if again, _, _ := track.Activity(paris); again.ID != a.ID {
+		t.Errorf("Expected the same recording to keep ID %d, got %d", a.ID, again.ID)
+	}
+
+	if _, _, err := (&Track{Points: []Point{{Lat: 1, HasPosition: true}}}).Activity(nil); err == nil {
+		t.Error("Expected an error for a track without times")
+	}
+}
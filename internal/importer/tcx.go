@@ -0,0 +1,71 @@
+package importer
+
+import (
+	"encoding/xml"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+)
+
+// tcxFile is the part of a Garmin Training Center document that is read
+type tcxFile struct {
+	Activities []struct {
+		Sport string `xml:"Sport,attr"`
+		Notes string `xml:"Notes"`
+		Laps  []struct {
+			Points []struct {
+				Time      string   `xml:"Time"`
+				Lat       *float64 `xml:"Position>LatitudeDegrees"`
+				Lng       *float64 `xml:"Position>LongitudeDegrees"`
+				Altitude  *float64 `xml:"AltitudeMeters"`
+				Distance  float64  `xml:"DistanceMeters"`
+				HeartRate float64  `xml:"HeartRateBpm>Value"`
+				Cadence   float64  `xml:"Cadence"`
+				Run       float64  `xml:"Extensions>TPX>RunCadence"`
+				Watts     float64  `xml:"Extensions>TPX>Watts"`
+			} `xml:"Track>Trackpoint"`
+		} `xml:"Lap"`
+	} `xml:"Activities>Activity"`
+}
+
+// ParseTCX reads the first activity of a TCX file
+func ParseTCX(r io.Reader) (*Track, error) {
+	var doc tcxFile
+	if err := xml.NewDecoder(r).Decode(&doc); err != nil {
+		return nil, fmt.Errorf("decode TCX: %w", err)
+	}
+	if len(doc.Activities) == 0 {
+		return nil, fmt.Errorf("no activities in TCX file")
+	}
+
+	activity := doc.Activities[0]
+	track := &Track{
+		Format: FormatTCX,
+		Name:   strings.TrimSpace(activity.Notes),
+		Type:   activityType(activity.Sport),
+	}
+	for _, lap := range activity.Laps {
+		for _, trackpoint := range lap.Points {
+			p := Point{
+				Distance:  trackpoint.Distance,
+				HeartRate: int(trackpoint.HeartRate),
+				Cadence:   int(max(trackpoint.Cadence, trackpoint.Run)),
+				Power:     int(trackpoint.Watts),
+			}
+			t, err := time.Parse(time.RFC3339, strings.TrimSpace(trackpoint.Time))
+			if err != nil {
+				return nil, fmt.Errorf("trackpoint time %q: %w", trackpoint.Time, err)
+			}
+			p.Time = t
+			if trackpoint.Lat != nil && trackpoint.Lng != nil {
+				p.Lat, p.Lng, p.HasPosition = *trackpoint.Lat, *trackpoint.Lng, true
+			}
+			if trackpoint.Altitude != nil {
+				p.Altitude, p.HasAltitude = *trackpoint.Altitude, true
+			}
+			track.Points = append(track.Points, p)
+		}
+	}
+	return track, nil
+}
@@ -0,0 +1,54 @@
+package importer
+
+import (
+	"strings"
+	"testing"
+)
+
+const testTCX = `<?xml version="1.0" encoding="UTF-8"?>
+<TrainingCenterDatabase xmlns="http://www.garmin.com/xmlschemas/TrainingCenterDatabase/v2"
+  xmlns:ns3="http://www.garmin.com/xmlschemas/ActivityExtension/v2">
+  <Activities>
+    <Activity Sport="Biking">
+      <Id>2025-10-07T06:00:00Z</Id>
+      <Lap StartTime="2025-10-07T06:00:00Z">
+        <Track>
+          <Trackpoint>
+            <Time>2025-10-07T06:00:00Z</Time>
+            <DistanceMeters>0</DistanceMeters>
+            <HeartRateBpm><Value>110</Value></HeartRateBpm>
+            <Cadence>85</Cadence>
+            <Extensions><ns3:TPX><ns3:Watts>180</ns3:Watts></ns3:TPX></Extensions>
+          </Trackpoint>
+          <Trackpoint>
+            <Time>2025-10-07T06:00:10Z</Time>
+            <Position><LatitudeDegrees>48.1</LatitudeDegrees><LongitudeDegrees>11.5</LongitudeDegrees></Position>
+            <AltitudeMeters>520.5</AltitudeMeters>
+            <DistanceMeters>80.5</DistanceMeters>
+            <HeartRateBpm><Value>118</Value></HeartRateBpm>
+            <Extensions><ns3:TPX><ns3:Watts>210</ns3:Watts></ns3:TPX></Extensions>
+          </Trackpoint>
+        </Track>
+      </Lap>
+      <Notes>Turbo session</Notes>
+    </Activity>
+  </Activities>
+</TrainingCenterDatabase>`
+
+func TestParseTCXReadsTrackpoints(t *testing.T) {
+	track, err := ParseTCX(strings.NewReader(testTCX))
+	if err != nil {
+		t.Fatalf("ParseTCX returned error: %v", err)
+	}
+	if track.Name != "Turbo session" || track.Type != "Ride" || len(track.Points) != 2 {
+		t.Fatalf("Expected a Ride named Turbo session with 2 points, got %+v", track)
+	}
+
+	first, second := track.Points[0], track.Points[1]
+	if first.HasPosition || first.HasAltitude || first.Cadence != 85 || first.Power != 180 || first.HeartRate != 110 {
+		t.Errorf("Expected sensors without a position on the first point, got %+v", first)
+	}
+	if !second.HasPosition || second.Lat != 48.1 || second.Altitude != 520.5 || second.Distance != 80.5 || second.Power != 210 {
+		t.Errorf("Expected position, altitude, distance and power on the second point, got %+v", second)
+	}
+}
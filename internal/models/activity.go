@@ -14,6 +14,15 @@ type TokenResponse struct {
 	TokenType    string `json:"token_type"`
 }
 
+// ValidAt reports whether the access token can still be used at the given
+// time, keeping a safety margin so it does not expire mid-request
+func (t *TokenResponse) ValidAt(now time.Time, margin time.Duration) bool {
+	if t == nil || t.AccessToken == "" {
+		return false
+	}
+	return now.Add(margin).Before(time.Unix(t.ExpiresAt, 0))
+}
+
 // Activity represents a Strava activity with enhanced tracking data
 type Activity struct {
 	ID               int64   `json:"id"`
@@ -30,6 +39,16 @@ type Activity struct {
 	HasHeartrate     bool    `json:"has_heartrate"`
 	AverageHeartrate float64 `json:"average_heartrate"` // bpm
 	Kudos            int     `json:"kudos_count"`
+	Source           string  `json:"-"` // where the activity came from, see SourceStrava
+
+	// Detailed fields, only filled in once the activity has been fetched on its own
+	Calories         float64      `json:"calories"`
+	DeviceName       string       `json:"device_name"`
+	Gear             *Gear        `json:"gear,omitempty"`
+	Laps             []Lap        `json:"laps,omitempty"`
+	Splits           []Split      `json:"splits_metric,omitempty"`
+	BestEfforts      []BestEffort `json:"best_efforts,omitempty"`
+	DetailsFetchedAt time.Time    `json:"-"` // zero until details are stored
 
 	// Calculated fields for enhanced analysis
 	DistanceKm      float64 `json:"-"`
@@ -37,6 +56,27 @@ type Activity struct {
 	PaceMinPerKm    string  `json:"-"`
 }
 
+// SourceStrava is the source of activities synchronised from Strava. Imported
+// activities are tagged with the format of their file instead.
+const SourceStrava = "strava"
+
+// SyncState records how far the local activity store has been synchronised with Strava
+type SyncState struct {
+	Watermark   time.Time `json:"watermark"`    // start time of the newest stored activity
+	LastSync    time.Time `json:"last_sync"`    // last successful sync of any kind
+	LastRecheck time.Time `json:"last_recheck"` // last re-fetch of the trailing window
+}
+
+// HasDetails reports whether laps, splits and best efforts have been fetched
+func (a *Activity) HasDetails() bool {
+	return !a.DetailsFetchedAt.IsZero()
+}
+
+// StartTime parses the activity's UTC start date
+func (a *Activity) StartTime() (time.Time, error) {
+	return time.Parse(time.RFC3339, a.StartDate)
+}
+
 // EnhanceWithCalculatedFields adds calculated fields to an activity
 func (a *Activity) EnhanceWithCalculatedFields() {
 	// Convert distances and times to more readable units
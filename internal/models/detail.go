@@ -0,0 +1,60 @@
+package models
+
+// Gear is the bike or pair of shoes an activity was recorded with
+type Gear struct {
+	ID   string `json:"id"`
+	Name string `json:"name"`
+}
+
+// Lap is a lap of an activity, either manual or auto-lapped by the device
+type Lap struct {
+	LapIndex         int     `json:"lap_index"`
+	Name             string  `json:"name"`
+	StartDate        string  `json:"start_date"`
+	Distance         float64 `json:"distance"`     // meters
+	MovingTime       int     `json:"moving_time"`  // seconds
+	ElapsedTime      int     `json:"elapsed_time"` // seconds
+	TotalElevGain    float64 `json:"total_elevation_gain"`
+	AverageSpeed     float64 `json:"average_speed"` // m/s
+	MaxSpeed         float64 `json:"max_speed"`     // m/s
+	AverageHeartrate float64 `json:"average_heartrate,omitempty"`
+	MaxHeartrate     float64 `json:"max_heartrate,omitempty"`
+}
+
+// Split is one kilometre of an activity; the last split may be shorter
+type Split struct {
+	Split               int     `json:"split"`        // 1-based index
+	Distance            float64 `json:"distance"`     // meters
+	MovingTime          int     `json:"moving_time"`  // seconds
+	ElapsedTime         int     `json:"elapsed_time"` // seconds
+	ElevationDifference float64 `json:"elevation_difference"`
+	AverageSpeed        float64 `json:"average_speed"` // m/s
+	AverageHeartrate    float64 `json:"average_heartrate,omitempty"`
+}
+
+// fullSplitMeters is the shortest split treated as a whole kilometre
+const fullSplitMeters = 950
+
+// Full reports whether the split covers a whole kilometre
+func (s Split) Full() bool {
+	return s.Distance >= fullSplitMeters
+}
+
+// Pace returns the split's moving pace in seconds per kilometre
+func (s Split) Pace() float64 {
+	if s.Distance <= 0 {
+		return 0
+	}
+	return float64(s.MovingTime) / (s.Distance / 1000)
+}
+
+// BestEffort is Strava's fastest segment of a standard distance within an
+// activity, such as the best 5K of a half marathon
+type BestEffort struct {
+	Name        string  `json:"name"`
+	Distance    float64 `json:"distance"`     // meters
+	MovingTime  int     `json:"moving_time"`  // seconds
+	ElapsedTime int     `json:"elapsed_time"` // seconds
+	StartDate   string  `json:"start_date"`
+	PRRank      int     `json:"pr_rank"` // 1-3 when Strava ranked it among the athlete's best
+}
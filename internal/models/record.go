@@ -0,0 +1,15 @@
+package models
+
+import "time"
+
+// PersonalRecord is an athlete's best result for one record kind, such as the
+// fastest 5K or the longest run
+type PersonalRecord struct {
+	Kind         string    `json:"kind"`
+	Value        float64   `json:"value"` // seconds for time records, otherwise the record's unit
+	ActivityID   int64     `json:"activity_id"`
+	ActivityName string    `json:"activity_name"`
+	SetAt        time.Time `json:"set_at"`      // start of the activity that set the record
+	DetectedAt   time.Time `json:"detected_at"` // when the record was first seen locally
+	Source       string    `json:"source"`      // "activity", "best_effort" or "streams"
+}
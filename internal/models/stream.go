@@ -0,0 +1,65 @@
+package models
+
+import "math"
+
+// StreamKeys lists the activity streams fetched from Strava
+var StreamKeys = []string{"time", "distance", "heartrate", "velocity_smooth", "cadence", "watts", "altitude", "latlng"}
+
+// Streams holds an activity's sampled data. Every present series has one
+// value per sample; series the device did not record are empty.
+type Streams struct {
+	ActivityID int64        `json:"-"`
+	Time       []int        `json:"time,omitempty"`            // seconds since the start
+	Distance   []float64    `json:"distance,omitempty"`        // meters
+	Heartrate  []int        `json:"heartrate,omitempty"`       // bpm
+	Velocity   []float64    `json:"velocity_smooth,omitempty"` // m/s, smoothed
+	Cadence    []int        `json:"cadence,omitempty"`         // rpm, or steps per minute per foot for runs
+	Watts      []int        `json:"watts,omitempty"`
+	Altitude   []float64    `json:"altitude,omitempty"` // meters
+	LatLng     [][2]float64 `json:"latlng,omitempty"`
+}
+
+// Len returns the number of samples
+func (s *Streams) Len() int {
+	return len(s.Time)
+}
+
+// SampleDurations returns how many seconds each sample stands for: the gap
+// to the next sample, with the last sample counting as one second. Gaps
+// longer than maxGap seconds, such as auto-pauses, count as zero.
+func (s *Streams) SampleDurations(maxGap int) []int {
+	durations := make([]int, len(s.Time))
+	for i := range s.Time {
+		gap := 1
+		if i+1 < len(s.Time) {
+			gap = s.Time[i+1] - s.Time[i]
+		}
+		if gap > 0 && gap <= maxGap {
+			durations[i] = gap
+		}
+	}
+	return durations
+}
+
+// BestEffort returns the fastest time in seconds over any stretch of at least
+// meters, reporting false when the distance stream does not cover it
+func (s *Streams) BestEffort(meters float64) (int, bool) {
+	if len(s.Distance) != len(s.Time) || len(s.Distance) == 0 || s.Distance[len(s.Distance)-1]-s.Distance[0] < meters {
+		return 0, false
+	}
+
+	best := math.MaxInt
+	start := 0
+	for end := range s.Distance {
+		// Advance the start while the stretch would still cover the distance
+		for start+1 <= end && s.Distance[end]-s.Distance[start+1] >= meters {
+			start++
+		}
+		if s.Distance[end]-s.Distance[start] >= meters {
+			if elapsed := s.Time[end] - s.Time[start]; elapsed < best {
+				best = elapsed
+			}
+		}
+	}
+	return best, best != math.MaxInt
+}
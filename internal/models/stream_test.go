@@ -0,0 +1,29 @@
+package models
+
+import "testing"
+
+func TestStreamsSampleDurationsSkipPauses(t *testing.T) {
+	s := Streams{Time: []int{0, 1, 2, 4, 300, 301}}
+	got := s.SampleDurations(10)
+	want := []int{1, 1, 2, 0, 1, 1}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Errorf("Expected durations %v, got %v", want, got)
+			break
+		}
+	}
+}
+
+func TestStreamsBestEffortFindsFastestStretch(t *testing.T) {
+	s := Streams{
+		Time:     []int{0, 100, 200, 280, 360, 460},
+		Distance: []float64{0, 300, 600, 1000, 1400, 1700},
+	}
+	// 600 m to 1400 m takes 160 s, the fastest 800 m anywhere
+	if got, ok := s.BestEffort(800); !ok || got != 160 {
+		t.Errorf("Expected best 800 m in 160s, got %d, %v", got, ok)
+	}
+	if _, ok := s.BestEffort(2000); ok {
+		t.Error("Expected no best effort beyond the recorded distance")
+	}
+}
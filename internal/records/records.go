@@ -0,0 +1,335 @@
+// Package records detects personal records across the stored activity history.
+package records
+
+import (
+	"fmt"
+	"math"
+	"time"
+
+	"strava-custom-goals/internal/goals"
+	"strava-custom-goals/internal/models"
+)
+
+// Kind identifies a personal record
+type Kind string
+
+// Supported record kinds
+const (
+	Fastest1K       Kind = "fastest_1k"
+	Fastest5K       Kind = "fastest_5k"
+	Fastest10K      Kind = "fastest_10k"
+	FastestHalf     Kind = "fastest_half_marathon"
+	FastestMarathon Kind = "fastest_marathon"
+	LongestRun      Kind = "longest_run"
+	BiggestClimb    Kind = "biggest_climb"
+	LongestWorkout  Kind = "longest_workout"
+)
+
+// Record sources
+const (
+	SourceActivity   = "activity"    // derived from a whole activity's average pace
+	SourceBestEffort = "best_effort" // a best effort measured by Strava
+	SourceStreams    = "streams"     // the fastest stretch of an activity's recorded streams
+)
+
+// Definition describes how a record kind is measured
+type Definition struct {
+	Kind     Kind
+	Label    string
+	Distance float64 // metres covered by time records; zero for the others
+}
+
+// Definitions lists every record kind in display order
+var Definitions = []Definition{
+	{Fastest1K, "Fastest 1K", 1000},
+	{Fastest5K, "Fastest 5K", 5000},
+	{Fastest10K, "Fastest 10K", 10000},
+	{FastestHalf, "Fastest half marathon", 21097.5},
+	{FastestMarathon, "Fastest marathon", 42195},
+	{LongestRun, "Longest run", 0},
+	{BiggestClimb, "Biggest climb", 0},
+	{LongestWorkout, "Longest workout", 0},
+}
+
+// RunTypes are the activity types that count toward running records
+var RunTypes = []string{"Run", "TrailRun", "VirtualRun"}
+
+// bestEffortTolerance is how far a best effort's distance may differ from a
+// record distance and still count for it
+const bestEffortTolerance = 0.01
+
+// Effort is a best effort within an activity, e.g. Strava's fastest 5K segment
+// of a longer run
+type Effort struct {
+	ActivityID  int64
+	Distance    float64 // metres
+	ElapsedTime int     // seconds
+	Source      string  // SourceBestEffort when empty
+}
+
+// Lookup returns the definition of a record kind
+func Lookup(kind Kind) (Definition, bool) {
+	for _, def := range Definitions {
+		if def.Kind == kind {
+			return def, true
+		}
+	}
+	return Definition{}, false
+}
+
+// IsTime reports whether the record is a time where lower is better
+func (d Definition) IsTime() bool {
+	return d.Distance > 0
+}
+
+// Format renders a record value in the kind's unit
+func (d Definition) Format(value float64) string {
+	switch {
+	case d.IsTime():
+		return formatClock(int(math.Round(value)))
+	case d.Kind == LongestRun:
+		return fmt.Sprintf("%.2f km", value/1000)
+	case d.Kind == BiggestClimb:
+		return fmt.Sprintf("%.0f m", value)
+	default:
+		return models.FormatDuration(int(value))
+	}
+}
+
+// Efforts collects the best efforts of activities whose details are stored
+func Efforts(activities []models.Activity) []Effort {
+	var efforts []Effort
+	for _, activity := range activities {
+		for _, effort := range activity.BestEfforts {
+			efforts = append(efforts, Effort{ActivityID: activity.ID, Distance: effort.Distance,
+				ElapsedTime: effort.ElapsedTime, Source: SourceBestEffort})
+		}
+	}
+	return efforts
+}
+
+// StreamSource returns an activity's stored streams, or nil if there are
+// none, and keeps the efforts measured from them
+type StreamSource interface {
+	Streams(activityID int64) (*models.Streams, error)
+	StreamEfforts() (map[int64][]models.BestEffort, error)
+	SaveStreamEfforts(activityID int64, efforts []models.BestEffort) error
+}
+
+// StreamEfforts returns efforts at each record distance from the streams of
+// runs that have no best efforts from Strava, such as imported recordings.
+// Each activity's streams are measured once and the result kept in source.
+func StreamEfforts(activities []models.Activity, source StreamSource) ([]Effort, error) {
+	measured, err := source.StreamEfforts()
+	if err != nil {
+		return nil, err
+	}
+
+	var efforts []Effort
+	for _, activity := range activities {
+		if !hasType(activity, RunTypes) || len(activity.BestEfforts) > 0 {
+			continue
+		}
+		found, ok := measured[activity.ID]
+		if !ok {
+			streams, err := source.Streams(activity.ID)
+			if err != nil {
+				return nil, err
+			}
+			if streams == nil {
+				continue
+			}
+			found = measure(streams)
+			if err := source.SaveStreamEfforts(activity.ID, found); err != nil {
+				return nil, err
+			}
+		}
+		for _, effort := range found {
+			efforts = append(efforts, Effort{ActivityID: activity.ID, Distance: effort.Distance,
+				ElapsedTime: effort.ElapsedTime, Source: SourceStreams})
+		}
+	}
+	return efforts, nil
+}
+
+// measure finds the fastest stretch of the streams at each record distance
+func measure(streams *models.Streams) []models.BestEffort {
+	var efforts []models.BestEffort
+	for _, def := range Definitions {
+		if !def.IsTime() {
+			continue
+		}
+		if seconds, ok := streams.BestEffort(def.Distance); ok {
+			efforts = append(efforts, models.BestEffort{Name: def.Label, Distance: def.Distance,
+				MovingTime: seconds, ElapsedTime: seconds})
+		}
+	}
+	return efforts
+}
+
+// Compute finds the current record of every kind. Time records come from
+// runs covering the distance, using their average pace, and from matching
+// efforts, whichever is faster. Ties go to the earlier activity.
+func Compute(activities []models.Activity, efforts []Effort) map[Kind]models.PersonalRecord {
+	best := map[Kind]models.PersonalRecord{}
+	byID := make(map[int64]models.Activity, len(activities))
+
+	offer := func(def Definition, activity models.Activity, value float64, source string) {
+		start, err := activity.StartTime()
+		if err != nil {
+			return
+		}
+		candidate := models.PersonalRecord{
+			Kind:         string(def.Kind),
+			Value:        value,
+			ActivityID:   activity.ID,
+			ActivityName: activity.Name,
+			SetAt:        start,
+			Source:       source,
+		}
+		if current, ok := best[def.Kind]; !ok || better(def, candidate, current) {
+			best[def.Kind] = candidate
+		}
+	}
+
+	for _, activity := range activities {
+		byID[activity.ID] = activity
+		running := hasType(activity, RunTypes)
+
+		for _, def := range Definitions {
+			switch {
+			case def.IsTime():
+				if running && activity.Distance >= def.Distance && activity.MovingTime > 0 {
+					offer(def, activity, float64(activity.MovingTime)*def.Distance/activity.Distance, SourceActivity)
+				}
+			case def.Kind == LongestRun:
+				if running && activity.Distance > 0 {
+					offer(def, activity, activity.Distance, SourceActivity)
+				}
+			case def.Kind == BiggestClimb:
+				if activity.TotalElevGain > 0 {
+					offer(def, activity, activity.TotalElevGain, SourceActivity)
+				}
+			case def.Kind == LongestWorkout:
+				if hasType(activity, goals.WorkoutTypes) && activity.MovingTime > 0 {
+					offer(def, activity, float64(activity.MovingTime), SourceActivity)
+				}
+			}
+		}
+	}
+
+	for _, effort := range efforts {
+		activity, ok := byID[effort.ActivityID]
+		if !ok || effort.ElapsedTime <= 0 {
+			continue
+		}
+		source := effort.Source
+		if source == "" {
+			source = SourceBestEffort
+		}
+		for _, def := range Definitions {
+			if def.IsTime() && math.Abs(effort.Distance-def.Distance) <= def.Distance*bestEffortTolerance {
+				offer(def, activity, float64(effort.ElapsedTime), source)
+			}
+		}
+	}
+	return best
+}
+
+// better reports whether candidate beats current
+func better(def Definition, candidate, current models.PersonalRecord) bool {
+	if candidate.Value == current.Value {
+		return candidate.SetAt.Before(current.SetAt)
+	}
+	if def.IsTime() {
+		return candidate.Value < current.Value
+	}
+	return candidate.Value > current.Value
+}
+
+// Sorted returns records in the order of Definitions
+func Sorted(records map[Kind]models.PersonalRecord) []models.PersonalRecord {
+	sorted := make([]models.PersonalRecord, 0, len(records))
+	for _, def := range Definitions {
+		if record, ok := records[def.Kind]; ok {
+			sorted = append(sorted, record)
+		}
+	}
+	return sorted
+}
+
+// Store persists personal records between syncs
+type Store interface {
+	StreamSource
+	ActivitiesSince(t time.Time) ([]models.Activity, error)
+	PersonalRecords() ([]models.PersonalRecord, error)
+	ReplacePersonalRecords(records []models.PersonalRecord) error
+}
+
+// Update recomputes records from the stored history, including best efforts
+// and, for runs without them, efforts measured from their streams, saves
+// them and returns the ones that are new or improved since the last update.
+// The first update only establishes a baseline and reports nothing.
+func Update(store Store, now time.Time) ([]models.PersonalRecord, error) {
+	activities, err := store.ActivitiesSince(time.Time{})
+	if err != nil {
+		return nil, fmt.Errorf("load activities: %w", err)
+	}
+	stored, err := store.PersonalRecords()
+	if err != nil {
+		return nil, err
+	}
+
+	previous := make(map[Kind]models.PersonalRecord, len(stored))
+	for _, record := range stored {
+		previous[Kind(record.Kind)] = record
+	}
+
+	measured, err := StreamEfforts(activities, store)
+	if err != nil {
+		return nil, fmt.Errorf("measure efforts from streams: %w", err)
+	}
+	current := Sorted(Compute(activities, append(Efforts(activities), measured...)))
+	var improved []models.PersonalRecord
+	for i, record := range current {
+		old, ok := previous[Kind(record.Kind)]
+		if ok && old.ActivityID == record.ActivityID && old.Value == record.Value {
+			current[i].DetectedAt = old.DetectedAt
+			continue
+		}
+
+		if len(stored) == 0 {
+			// Baseline: the record has existed since it was set
+			current[i].DetectedAt = record.SetAt
+			continue
+		}
+		current[i].DetectedAt = now
+		def, _ := Lookup(Kind(record.Kind))
+		if !ok || better(def, record, old) {
+			improved = append(improved, current[i])
+		}
+	}
+
+	if err := store.ReplacePersonalRecords(current); err != nil {
+		return nil, err
+	}
+	return improved, nil
+}
+
+// hasType reports whether the activity is one of types
+func hasType(activity models.Activity, types []string) bool {
+	for _, t := range types {
+		if activity.Type == t {
+			return true
+		}
+	}
+	return false
+}
+
+// formatClock renders seconds as h:mm:ss or m:ss
+func formatClock(seconds int) string {
+	if seconds >= 3600 {
+		return fmt.Sprintf("%d:%02d:%02d", seconds/3600, seconds/60%60, seconds%60)
+	}
+	return fmt.Sprintf("%d:%02d", seconds/60, seconds%60)
+}
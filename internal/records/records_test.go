@@ -0,0 +1,195 @@
+package records
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+
+	"strava-custom-goals/internal/models"
+	"strava-custom-goals/internal/store"
+)
+
+func activity(id int64, activityType string, meters float64, seconds int, start string) models.Activity {
+	return models.Activity{ID: id, Name: "Activity", Type: activityType, Distance: meters, MovingTime: seconds, StartDate: start}
+}
+
+func TestComputeFromWholeActivities(t *testing.T) {
+	activities := []models.Activity{
+		activity(1, "Run", 5000, 1500, "2025-09-01T07:00:00Z"),  // 5:00/km
+		activity(2, "Run", 10000, 2800, "2025-09-08T07:00:00Z"), // 4:40/km
+		activity(3, "Ride", 40000, 4000, "2025-09-09T07:00:00Z"),
+		activity(4, "WeightTraining", 0, 5400, "2025-09-10T07:00:00Z"),
+	}
+	activities[2].TotalElevGain = 650
+
+	records := Compute(activities, nil)
+
+	expected := map[Kind]struct {
+		value float64
+		id    int64
+	}{
+		Fastest1K:      {280, 2},
+		Fastest5K:      {1400, 2},
+		Fastest10K:     {2800, 2},
+		LongestRun:     {10000, 2},
+		BiggestClimb:   {650, 3},
+		LongestWorkout: {5400, 4},
+	}
+	for kind, want := range expected {
+		record, ok := records[kind]
+		if !ok {
+			t.Errorf("%s: expected a record", kind)
+			continue
+		}
+		if record.Value != want.value || record.ActivityID != want.id {
+			t.Errorf("%s: expected %.0f from activity %d, got %.0f from %d", kind, want.value, want.id, record.Value, record.ActivityID)
+		}
+	}
+	if _, ok := records[FastestHalf]; ok {
+		t.Error("Expected no half marathon record without a run that long")
+	}
+}
+
+func TestComputePrefersFasterBestEffort(t *testing.T) {
+	activities := []models.Activity{activity(1, "Run", 10000, 3000, "2025-09-01T07:00:00Z")}
+	efforts := []Effort{
+		{ActivityID: 1, Distance: 5000, ElapsedTime: 1380},
+		{ActivityID: 1, Distance: 1609.3, ElapsedTime: 400}, // a mile counts for nothing
+		{ActivityID: 99, Distance: 1000, ElapsedTime: 200},  // unknown activity
+	}
+
+	records := Compute(activities, efforts)
+
+	if r := records[Fastest5K]; r.Value != 1380 || r.Source != SourceBestEffort {
+		t.Errorf("Expected a 1380 s best effort 5K, got %+v", r)
+	}
+	if r := records[Fastest1K]; r.Value != 300 || r.Source != SourceActivity {
+		t.Errorf("Expected a 300 s 1K from average pace, got %+v", r)
+	}
+}
+
+func TestEffortsFromStoredDetails(t *testing.T) {
+	activities := []models.Activity{activity(1, "Run", 21100, 6300, "2025-09-01T07:00:00Z"), activity(2, "Run", 5000, 1500, "2025-09-03T07:00:00Z")}
+	activities[0].BestEfforts = []models.BestEffort{{Name: "5k", Distance: 5000, ElapsedTime: 1410}}
+
+	efforts := Efforts(activities)
+	if len(efforts) != 1 || efforts[0].ActivityID != 1 || efforts[0].ElapsedTime != 1410 {
+		t.Fatalf("Expected the half marathon's 5K effort, got %+v", efforts)
+	}
+	if r := Compute(activities, efforts)[Fastest5K]; r.Value != 1410 || r.ActivityID != 1 {
+		t.Errorf("Expected the 1410 s effort to beat the 1500 s 5K, got %+v", r)
+	}
+}
+
+func TestStreamEffortsMeasureRunsWithoutBestEfforts(t *testing.T) {
+	db, err := store.Open(filepath.Join(t.TempDir(), "activities.db"))
+	if err != nil {
+		t.Fatalf("Open failed: %v", err)
+	}
+	defer db.Close()
+
+	activities := []models.Activity{
+		activity(1, "Run", 2000, 600, "2025-09-01T07:00:00Z"),
+		activity(2, "Run", 2000, 600, "2025-09-02T07:00:00Z"),
+		activity(3, "Ride", 2000, 300, "2025-09-03T07:00:00Z"),
+	}
+	activities[1].BestEfforts = []models.BestEffort{{Name: "1k", Distance: 1000, ElapsedTime: 290}}
+	db.UpsertActivities(activities)
+	// A slow first kilometre and a 240 s second one
+	for _, a := range activities {
+		db.SaveStreams(models.Streams{ActivityID: a.ID, Time: []int{0, 180, 360, 600}, Distance: []float64{0, 500, 1000, 2000}}, time.Now())
+	}
+
+	efforts, err := StreamEfforts(activities, db)
+	if err != nil {
+		t.Fatalf("StreamEfforts returned error: %v", err)
+	}
+	if len(efforts) != 1 || efforts[0].ActivityID != 1 || efforts[0].ElapsedTime != 240 || efforts[0].Source != SourceStreams {
+		t.Fatalf("Expected only the run without best efforts to be measured, got %+v", efforts)
+	}
+	if r := Compute(activities, efforts)[Fastest1K]; r.Value != 240 || r.ActivityID != 1 || r.Source != SourceStreams {
+		t.Errorf("Expected a 240 s 1K from the streams, got %+v", r)
+	}
+
+	// The measurement is kept, and dropped once the streams change
+	if measured, _ := db.StreamEfforts(); len(measured) != 1 || len(measured[1]) != 1 {
+		t.Errorf("Expected the efforts of activity 1 to be kept, got %+v", measured)
+	}
+	if again, _ := StreamEfforts(activities, db); len(again) != 1 || again[0].ElapsedTime != 240 {
+		t.Errorf("Expected the kept efforts to be reused, got %+v", again)
+	}
+	db.SaveStreams(models.Streams{ActivityID: 1, Time: []int{0, 200, 400}, Distance: []float64{0, 1000, 2000}}, time.Now())
+	if measured, _ := db.StreamEfforts(); len(measured) != 0 {
+		t.Errorf("Expected new streams to drop the kept efforts, got %+v", measured)
+	}
+	if remeasured, _ := StreamEfforts(activities, db); len(remeasured) != 1 || remeasured[0].ElapsedTime != 200 {
+		t.Errorf("Expected the new streams to be measured, got %+v", remeasured)
+	}
+}
+
+func TestDefinitionFormat(t *testing.T) {
+	tests := []struct {
+		kind     Kind
+		value    float64
+		expected string
+	}{
+		{Fastest5K, 1351, "22:31"},
+		{FastestMarathon, 12600, "3:30:00"},
+		{LongestRun, 21097.5, "21.10 km"},
+		{BiggestClimb, 812.4, "812 m"},
+		{LongestWorkout, 5400, "1h 30m 0s"},
+	}
+	for _, test := range tests {
+		def, _ := Lookup(test.kind)
+		if got := def.Format(test.value); got != test.expected {
+			t.Errorf("%s: expected %q, got %q", test.kind, test.expected, got)
+		}
+	}
+}
+
+func TestUpdateFlagsNewRecords(t *testing.T) {
+	db, err := store.Open(filepath.Join(t.TempDir(), "activities.db"))
+	if err != nil {
+		t.Fatalf("Open failed: %v", err)
+	}
+	defer db.Close()
+
+	db.UpsertActivities([]models.Activity{activity(1, "Run", 5000, 1500, "2025-09-01T07:00:00Z")})
+	first := time.Date(2025, 9, 1, 12, 0, 0, 0, time.UTC)
+	improved, err := Update(db, first)
+	if err != nil {
+		t.Fatalf("Update failed: %v", err)
+	}
+	if len(improved) != 0 {
+		t.Errorf("Expected the first update to only set a baseline, got %d records", len(improved))
+	}
+
+	// A faster, longer run beats the 1K, 5K and longest-run records and sets a 10K
+	db.UpsertActivities([]models.Activity{activity(2, "Run", 10000, 2800, "2025-09-08T07:00:00Z")})
+	second := first.AddDate(0, 0, 7)
+	improved, err = Update(db, second)
+	if err != nil {
+		t.Fatalf("Update failed: %v", err)
+	}
+	kinds := map[string]bool{}
+	for _, record := range improved {
+		kinds[record.Kind] = true
+	}
+	for _, kind := range []Kind{Fastest1K, Fastest5K, Fastest10K, LongestRun} {
+		if !kinds[string(kind)] {
+			t.Errorf("Expected %s to be flagged", kind)
+		}
+	}
+
+	// Nothing changes on the next sync, and detection times are kept
+	improved, _ = Update(db, second.AddDate(0, 0, 1))
+	if len(improved) != 0 {
+		t.Errorf("Expected no new records, got %d", len(improved))
+	}
+	stored, _ := db.PersonalRecords()
+	for _, record := range stored {
+		if !record.DetectedAt.Equal(second) {
+			t.Errorf("%s: expected detection time %v to be kept, got %v", record.Kind, second, record.DetectedAt)
+		}
+	}
+}
@@ -0,0 +1,52 @@
+package report
+
+import "strava-custom-goals/internal/goals"
+
+// GoalHistory is one goal's results over consecutive periods
+type GoalHistory struct {
+	Name    string           `json:"name"`
+	Metric  string           `json:"metric"`
+	Unit    string           `json:"unit"`
+	Target  float64          `json:"target"`
+	Periods []PeriodProgress `json:"periods"`
+}
+
+// PeriodProgress is a goal's result within one past or current period
+type PeriodProgress struct {
+	Period     PeriodInfo `json:"period"`
+	Actual     float64    `json:"actual"`
+	Percent    float64    `json:"percent"`
+	Achieved   bool       `json:"achieved"`
+	Activities int        `json:"activities"`
+}
+
+// NewHistory groups results from goals.Engine.History by goal, keeping the
+// order in which goals first appear
+func NewHistory(results []goals.Result) []GoalHistory {
+	history := []GoalHistory{}
+	index := map[string]int{}
+
+	for _, result := range results {
+		progress := NewGoalProgress(result)
+		i, ok := index[progress.Name]
+		if !ok {
+			i = len(history)
+			index[progress.Name] = i
+			history = append(history, GoalHistory{
+				Name:    progress.Name,
+				Metric:  progress.Metric,
+				Unit:    progress.Unit,
+				Target:  progress.Target,
+				Periods: []PeriodProgress{},
+			})
+		}
+		history[i].Periods = append(history[i].Periods, PeriodProgress{
+			Period:     progress.Period,
+			Actual:     progress.Actual,
+			Percent:    progress.Percent,
+			Achieved:   progress.Achieved,
+			Activities: progress.Activities,
+		})
+	}
+	return history
+}
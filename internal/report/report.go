@@ -0,0 +1,364 @@
+// Package report builds the format-independent model of a progress report:
+// goal results, activities with calculated fields and summary totals.
+package report
+
+import (
+	"encoding/json"
+	"io"
+	"time"
+
+	"strava-custom-goals/internal/goals"
+	"strava-custom-goals/internal/models"
+)
+
+// SchemaVersion is bumped whenever a field is renamed, removed or changes
+// meaning. Adding fields does not change the version.
+const SchemaVersion = 1
+
+// Report is a complete progress report
+type Report struct {
+	SchemaVersion int               `json:"schema_version"`
+	GeneratedAt   time.Time         `json:"generated_at"`
+	AsOf          time.Time         `json:"as_of"`
+	Profile       string            `json:"profile"`
+	Goals         []GoalProgress    `json:"goals"`
+	Message       string            `json:"message"`
+	Streaks       []StreakInfo      `json:"streaks"`
+	TrainingLoad  *TrainingLoadInfo `json:"training_load,omitempty"`
+	Activities    []Activity        `json:"activities"`
+	Summary       Summary           `json:"summary"`
+}
+
+// GoalProgress is one goal's result within its current period
+type GoalProgress struct {
+	Name          string            `json:"name"`
+	Metric        string            `json:"metric"`
+	Unit          string            `json:"unit"`
+	ActivityTypes []string          `json:"activity_types"`
+	Period        PeriodInfo        `json:"period"`
+	Target        float64           `json:"target"`
+	Max           float64           `json:"max,omitempty"` // upper end of a target range
+	Actual        float64           `json:"actual"`
+	Percent       float64           `json:"percent"`
+	Remaining     float64           `json:"remaining"`
+	Achieved      bool              `json:"achieved"`
+	Over          bool              `json:"over,omitempty"` // actual exceeds max
+	Activities    int               `json:"activities"`
+	Notes         string            `json:"notes,omitempty"`
+	Projection    *ProjectionInfo   `json:"projection,omitempty"`
+	Zones         *ZoneDistribution `json:"zones,omitempty"` // zone goals with recorded zone time
+}
+
+// ZoneDistribution is the time a zone goal's activities spent in each zone
+type ZoneDistribution struct {
+	Type  string     `json:"type"`  // heartrate or power
+	Range string     `json:"range"` // the zones the goal counts, e.g. "HR zone 2"
+	Zones []ZoneInfo `json:"zones"`
+}
+
+// ZoneInfo is the time spent in one zone
+type ZoneInfo struct {
+	Zone    int     `json:"zone"`
+	Minutes float64 `json:"minutes"`
+	Percent float64 `json:"percent"` // of the time across all zones
+	InGoal  bool    `json:"in_goal"` // whether the goal counts this zone
+}
+
+// ProjectionInfo forecasts a goal at the end of its current period
+type ProjectionInfo struct {
+	Status         string   `json:"status"` // achieved, ahead, on_track, behind or over
+	Projected      float64  `json:"projected"`
+	PaceTotal      float64  `json:"pace_total"`
+	PatternTotal   *float64 `json:"pattern_total"` // null without enough history
+	ElapsedPercent float64  `json:"elapsed_percent"`
+	DaysRemaining  int      `json:"days_remaining"`
+	RequiredPerDay float64  `json:"required_per_day"`
+}
+
+// PeriodInfo describes a goal period with inclusive calendar dates
+type PeriodInfo struct {
+	Kind  string `json:"kind"`
+	Start string `json:"start"` // YYYY-MM-DD
+	End   string `json:"end"`   // YYYY-MM-DD, inclusive
+}
+
+// StreakInfo is a goal's or the active-days streak of consecutive periods
+type StreakInfo struct {
+	Name         string `json:"name"`
+	Period       string `json:"period"`
+	Current      int    `json:"current"`
+	Longest      int    `json:"longest"`
+	LongestStart string `json:"longest_start,omitempty"` // YYYY-MM-DD
+	LongestEnd   string `json:"longest_end,omitempty"`   // YYYY-MM-DD, inclusive
+	AtRisk       bool   `json:"at_risk"`
+	Message      string `json:"message"`
+}
+
+// TrainingLoadInfo is the athlete's training load on the report date
+type TrainingLoadInfo struct {
+	Date      string  `json:"date"`                 // YYYY-MM-DD
+	Fitness   float64 `json:"fitness"`              // chronic training load (CTL)
+	Fatigue   float64 `json:"fatigue"`              // acute training load (ATL)
+	Form      float64 `json:"form"`                 // training stress balance (TSB)
+	FormZone  string  `json:"form_zone"`            // transition, fresh, neutral, optimal or overreaching
+	WeekLoad  float64 `json:"week_load"`            // TRIMP over the last 7 days
+	Estimated int     `json:"estimated_activities"` // activities in the fitness window without heart rate
+}
+
+// Activity is an activity with its calculated fields
+type Activity struct {
+	ID                 int64   `json:"id"`
+	Name               string  `json:"name"`
+	Type               string  `json:"type"`
+	StartDate          string  `json:"start_date"`
+	StartDateLocal     string  `json:"start_date_local"`
+	Distance           float64 `json:"distance_m"`
+	MovingTime         int     `json:"moving_time_s"`
+	ElapsedTime        int     `json:"elapsed_time_s"`
+	TotalElevationGain float64 `json:"total_elevation_gain_m"`
+	AverageSpeed       float64 `json:"average_speed_mps"`
+	MaxSpeed           float64 `json:"max_speed_mps"`
+	HasHeartrate       bool    `json:"has_heartrate"`
+	AverageHeartrate   float64 `json:"average_heartrate,omitempty"`
+	Kudos              int     `json:"kudos"`
+	Calories           float64 `json:"calories,omitempty"`    // once details are fetched
+	DeviceName         string  `json:"device_name,omitempty"` // once details are fetched
+	Gear               string  `json:"gear,omitempty"`        // gear name, once details are fetched
+	DistanceKm         float64 `json:"distance_km"`
+	MovingTimeHours    float64 `json:"moving_time_hours"`
+	PaceMinPerKm       string  `json:"pace_min_per_km,omitempty"`
+}
+
+// Summary totals a set of activities
+type Summary struct {
+	TotalActivities   int            `json:"total_activities"`
+	Runs              int            `json:"runs"`
+	Rides             int            `json:"rides"`
+	TotalDistanceKm   float64        `json:"total_distance_km"`
+	TotalMovingTime   int            `json:"total_moving_time_s"`
+	AverageDistanceKm float64        `json:"average_distance_km"`
+	ByType            map[string]int `json:"by_type"`
+}
+
+// Options controls which sections a report includes
+type Options struct {
+	Profile        string
+	AsOf           time.Time
+	MaxActivities  int // 0 means all
+	OmitActivities bool
+	Streaks        []goals.Streak
+	Projections    []goals.Projection // one per result, in the same order
+	TrainingLoad   []goals.LoadDay    // daily load up to AsOf
+}
+
+// Build assembles a report from goal results and activities (newest first)
+func Build(results []goals.Result, activities []models.Activity, opts Options) *Report {
+	r := &Report{
+		SchemaVersion: SchemaVersion,
+		GeneratedAt:   time.Now().UTC(),
+		AsOf:          opts.AsOf,
+		Profile:       opts.Profile,
+		Goals:         make([]GoalProgress, 0, len(results)),
+		Message:       goals.MotivationalMessage(results),
+		Streaks:       make([]StreakInfo, 0, len(opts.Streaks)),
+		Activities:    []Activity{},
+		Summary:       Summarize(activities),
+		TrainingLoad:  NewTrainingLoadInfo(opts.TrainingLoad),
+	}
+
+	for i, result := range results {
+		progress := NewGoalProgress(result)
+		if i < len(opts.Projections) {
+			progress.Projection = NewProjectionInfo(opts.Projections[i])
+		}
+		r.Goals = append(r.Goals, progress)
+	}
+
+	for _, streak := range opts.Streaks {
+		r.Streaks = append(r.Streaks, NewStreakInfo(streak))
+	}
+
+	if !opts.OmitActivities {
+		listed := activities
+		if opts.MaxActivities > 0 && len(listed) > opts.MaxActivities {
+			listed = listed[:opts.MaxActivities]
+		}
+		for _, activity := range listed {
+			r.Activities = append(r.Activities, NewActivity(activity))
+		}
+	}
+
+	return r
+}
+
+// NewGoalProgress converts a goal result
+func NewGoalProgress(result goals.Result) GoalProgress {
+	types := result.Goal.ActivityTypes
+	if types == nil {
+		types = []string{}
+	}
+	return GoalProgress{
+		Name:          result.Goal.Name,
+		Metric:        string(result.Goal.Metric),
+		Unit:          result.Goal.Metric.Unit(),
+		ActivityTypes: types,
+		Period: PeriodInfo{
+			Kind:  string(result.Goal.Period.Kind),
+			Start: result.PeriodStart.Format("2006-01-02"),
+			End:   result.PeriodEnd.AddDate(0, 0, -1).Format("2006-01-02"),
+		},
+		Target:     result.Goal.Target,
+		Max:        result.Goal.Max,
+		Actual:     result.Actual,
+		Percent:    result.Percent(),
+		Remaining:  result.Remaining(),
+		Achieved:   result.Achieved(),
+		Over:       result.Over(),
+		Activities: result.Activities,
+		Notes:      result.Goal.Notes,
+		Zones:      NewZoneDistribution(result.Goal.Zones, result.Zones),
+	}
+}
+
+// NewZoneDistribution converts a period's time in zones, or returns nil when
+// no zone time was recorded
+func NewZoneDistribution(zones goals.ZoneRange, spent goals.ZoneTime) *ZoneDistribution {
+	total := spent.Total()
+	if total == 0 {
+		return nil
+	}
+	distribution := &ZoneDistribution{Type: string(zones.Kind), Range: zones.String()}
+	for i, seconds := range spent {
+		distribution.Zones = append(distribution.Zones, ZoneInfo{
+			Zone:    i + 1,
+			Minutes: float64(seconds) / 60,
+			Percent: float64(seconds) / float64(total) * 100,
+			InGoal:  zones.Contains(i + 1),
+		})
+	}
+	return distribution
+}
+
+// NewProjectionInfo converts a projection
+func NewProjectionInfo(p goals.Projection) *ProjectionInfo {
+	info := &ProjectionInfo{
+		Status:         string(p.Status),
+		Projected:      p.Projected,
+		PaceTotal:      p.PaceTotal,
+		ElapsedPercent: p.Elapsed * 100,
+		DaysRemaining:  p.DaysRemaining,
+		RequiredPerDay: p.RequiredPerDay,
+	}
+	if p.HasPattern {
+		pattern := p.PatternTotal
+		info.PatternTotal = &pattern
+	}
+	return info
+}
+
+// NewStreakInfo converts a streak
+func NewStreakInfo(streak goals.Streak) StreakInfo {
+	info := StreakInfo{
+		Name:    streak.Name,
+		Period:  string(streak.Period),
+		Current: streak.Current,
+		Longest: streak.Longest,
+		AtRisk:  streak.AtRisk,
+		Message: streak.Message(),
+	}
+	if streak.Longest > 0 {
+		info.LongestStart = streak.LongestStart.Format("2006-01-02")
+		info.LongestEnd = streak.LongestEnd.AddDate(0, 0, -1).Format("2006-01-02")
+	}
+	return info
+}
+
+// NewTrainingLoadInfo summarises the last day of a training load series, or
+// returns nil when there is none
+func NewTrainingLoadInfo(days []goals.LoadDay) *TrainingLoadInfo {
+	if len(days) == 0 {
+		return nil
+	}
+	last := days[len(days)-1]
+	info := &TrainingLoadInfo{
+		Date:     last.Date.Format("2006-01-02"),
+		Fitness:  last.Fitness,
+		Fatigue:  last.Fatigue,
+		Form:     last.Form,
+		FormZone: string(last.Zone()),
+	}
+	for i, day := range days {
+		age := len(days) - 1 - i
+		if age < 7 {
+			info.WeekLoad += day.Load
+		}
+		if age < goals.FitnessDays {
+			info.Estimated += day.Estimated
+		}
+	}
+	return info
+}
+
+// NewActivity converts an activity, computing calculated fields if needed
+func NewActivity(a models.Activity) Activity {
+	a.EnhanceWithCalculatedFields()
+	var gear string
+	if a.Gear != nil {
+		gear = a.Gear.Name
+	}
+	return Activity{
+		ID:                 a.ID,
+		Name:               a.Name,
+		Type:               a.Type,
+		StartDate:          a.StartDate,
+		StartDateLocal:     a.StartDateLocal,
+		Distance:           a.Distance,
+		MovingTime:         a.MovingTime,
+		ElapsedTime:        a.ElapsedTime,
+		TotalElevationGain: a.TotalElevGain,
+		AverageSpeed:       a.AverageSpeed,
+		MaxSpeed:           a.MaxSpeed,
+		HasHeartrate:       a.HasHeartrate,
+		AverageHeartrate:   a.AverageHeartrate,
+		Kudos:              a.Kudos,
+		Calories:           a.Calories,
+		DeviceName:         a.DeviceName,
+		Gear:               gear,
+		DistanceKm:         a.DistanceKm,
+		MovingTimeHours:    a.MovingTimeHours,
+		PaceMinPerKm:       a.PaceMinPerKm,
+	}
+}
+
+// Summarize totals activities by distance, time and type
+func Summarize(activities []models.Activity) Summary {
+	summary := Summary{
+		TotalActivities: len(activities),
+		ByType:          map[string]int{},
+	}
+
+	for _, activity := range activities {
+		summary.TotalDistanceKm += activity.Distance / 1000
+		summary.TotalMovingTime += activity.MovingTime
+		summary.ByType[activity.Type]++
+
+		switch activity.Type {
+		case "Run":
+			summary.Runs++
+		case "Ride":
+			summary.Rides++
+		}
+	}
+
+	if len(activities) > 0 {
+		summary.AverageDistanceKm = summary.TotalDistanceKm / float64(len(activities))
+	}
+	return summary
+}
+
+// WriteJSON writes the report as indented JSON
+func WriteJSON(w io.Writer, r *Report) error {
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(r)
+}
@@ -0,0 +1,172 @@
+package report
+
+import (
+	"bytes"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"strava-custom-goals/internal/goals"
+	"strava-custom-goals/internal/models"
+)
+
+func testActivities() []models.Activity {
+	return []models.Activity{
+		{ID: 3, Name: "Evening Run", Type: "Run", Distance: 10000, MovingTime: 3000,
+			StartDate: "2025-01-08T18:00:00Z", StartDateLocal: "2025-01-08T19:00:00Z"},
+		{ID: 2, Name: "Commute", Type: "Ride", Distance: 20000, MovingTime: 2400,
+			StartDate: "2025-01-07T07:00:00Z", StartDateLocal: "2025-01-07T08:00:00Z"},
+		{ID: 1, Name: "Morning Run", Type: "Run", Distance: 5000, MovingTime: 1500,
+			StartDate: "2025-01-06T06:00:00Z", StartDateLocal: "2025-01-06T07:00:00Z"},
+	}
+}
+
+func testResult() goals.Result {
+	return goals.Result{
+		Goal: goals.Goal{
+			Name:          "Weekly Running",
+			Metric:        goals.MetricDistance,
+			ActivityTypes: []string{"Run"},
+			Period:        goals.Every(goals.PeriodWeek),
+			Target:        20,
+		},
+		Actual:      15,
+		Activities:  2,
+		PeriodStart: time.Date(2025, 1, 6, 0, 0, 0, 0, time.UTC),
+		PeriodEnd:   time.Date(2025, 1, 13, 0, 0, 0, 0, time.UTC),
+	}
+}
+
+func TestBuild(t *testing.T) {
+	asOf := time.Date(2025, 1, 9, 12, 0, 0, 0, time.UTC)
+	r := Build([]goals.Result{testResult()}, testActivities(), Options{Profile: "default", AsOf: asOf, MaxActivities: 2})
+
+	if r.SchemaVersion != SchemaVersion {
+		t.Errorf("Expected schema version %d, got %d", SchemaVersion, r.SchemaVersion)
+	}
+	if len(r.Activities) != 2 {
+		t.Fatalf("Expected 2 activities after --max, got %d", len(r.Activities))
+	}
+	if r.Activities[0].DistanceKm != 10 || r.Activities[0].PaceMinPerKm != "5:00" {
+		t.Errorf("Expected calculated fields 10 km at 5:00, got %.2f km at %s", r.Activities[0].DistanceKm, r.Activities[0].PaceMinPerKm)
+	}
+
+	goal := r.Goals[0]
+	if goal.Percent != 75 || goal.Remaining != 5 || goal.Achieved {
+		t.Errorf("Expected 75%% with 5 remaining, got %.1f%% with %.1f (achieved=%v)", goal.Percent, goal.Remaining, goal.Achieved)
+	}
+	if goal.Period.Start != "2025-01-06" || goal.Period.End != "2025-01-12" {
+		t.Errorf("Expected inclusive period 2025-01-06..2025-01-12, got %s..%s", goal.Period.Start, goal.Period.End)
+	}
+
+	// The summary always covers every activity, not just the listed ones
+	if r.Summary.TotalActivities != 3 || r.Summary.Runs != 2 || r.Summary.Rides != 1 {
+		t.Errorf("Expected 3 activities (2 runs, 1 ride), got %+v", r.Summary)
+	}
+	if r.Summary.TotalDistanceKm != 35 {
+		t.Errorf("Expected 35 km total, got %.2f", r.Summary.TotalDistanceKm)
+	}
+}
+
+func TestBuildOmitActivities(t *testing.T) {
+	r := Build(nil, testActivities(), Options{OmitActivities: true})
+
+	if r.Activities == nil || len(r.Activities) != 0 {
+		t.Errorf("Expected an empty activities list, got %v", r.Activities)
+	}
+	if r.Goals == nil {
+		t.Error("Expected an empty goals list, got nil")
+	}
+}
+
+func TestNewTrainingLoadInfo(t *testing.T) {
+	if NewTrainingLoadInfo(nil) != nil {
+		t.Error("Expected no training load without history")
+	}
+
+	start := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	var days []goals.LoadDay
+	for i := 0; i < 10; i++ {
+		days = append(days, goals.LoadDay{Date: start.AddDate(0, 0, i), Load: 50, Estimated: 1, Fitness: 30, Fatigue: 45, Form: -12})
+	}
+
+	info := NewTrainingLoadInfo(days)
+	if info.Date != "2025-01-10" || info.FormZone != "optimal" {
+		t.Errorf("Expected the last day in the optimal zone, got %+v", info)
+	}
+	if info.WeekLoad != 350 || info.Estimated != 10 {
+		t.Errorf("Expected a week load of 350 and 10 estimated activities, got %+v", info)
+	}
+}
+
+func TestNewZoneDistribution(t *testing.T) {
+	zones := goals.ZoneRange{Kind: goals.ZoneKindHeartRate, From: 4}
+	if NewZoneDistribution(zones, goals.ZoneTime{0, 0, 0}) != nil {
+		t.Error("Expected no distribution without recorded zone time")
+	}
+
+	distribution := NewZoneDistribution(zones, goals.ZoneTime{600, 2400, 1800, 900, 300})
+	if distribution.Type != "heartrate" || distribution.Range != "HR zones 4+" || len(distribution.Zones) != 5 {
+		t.Fatalf("Unexpected distribution %+v", distribution)
+	}
+	if z2 := distribution.Zones[1]; z2.Zone != 2 || z2.Minutes != 40 || z2.Percent != 40 || z2.InGoal {
+		t.Errorf("Expected 40 minutes, 40%% in zone 2, outside the goal, got %+v", z2)
+	}
+	if z5 := distribution.Zones[4]; z5.Percent != 5 || !z5.InGoal {
+		t.Errorf("Expected 5%% in zone 5, inside the goal, got %+v", z5)
+	}
+}
+
+func TestWriteJSONSchema(t *testing.T) {
+	r := Build([]goals.Result{testResult()}, testActivities(), Options{Profile: "default"})
+
+	var buf bytes.Buffer
+	if err := WriteJSON(&buf, r); err != nil {
+		t.Fatalf("WriteJSON failed: %v", err)
+	}
+
+	var decoded map[string]interface{}
+	if err := json.Unmarshal(buf.Bytes(), &decoded); err != nil {
+		t.Fatalf("Output is not valid JSON: %v", err)
+	}
+
+	for _, key := range []string{"schema_version", "generated_at", "as_of", "profile", "goals", "activities", "summary"} {
+		if _, ok := decoded[key]; !ok {
+			t.Errorf("Expected top-level key %q", key)
+		}
+	}
+
+	goal := decoded["goals"].([]interface{})[0].(map[string]interface{})
+	for _, key := range []string{"target", "actual", "percent", "remaining", "achieved", "unit", "period"} {
+		if _, ok := goal[key]; !ok {
+			t.Errorf("Expected goal key %q", key)
+		}
+	}
+
+	activity := decoded["activities"].([]interface{})[0].(map[string]interface{})
+	for _, key := range []string{"distance_km", "moving_time_hours", "pace_min_per_km"} {
+		if _, ok := activity[key]; !ok {
+			t.Errorf("Expected activity key %q", key)
+		}
+	}
+}
+
+func TestNewHistoryGroupsByGoal(t *testing.T) {
+	first := testResult()
+	second := testResult()
+	second.Actual = 25
+	second.PeriodStart = second.PeriodStart.AddDate(0, 0, 7)
+	second.PeriodEnd = second.PeriodEnd.AddDate(0, 0, 7)
+	other := testResult()
+	other.Goal.Name = "Weekly Sessions"
+
+	history := NewHistory([]goals.Result{first, other, second})
+
+	if len(history) != 2 || history[0].Name != "Weekly Running" {
+		t.Fatalf("Expected 2 goals in first-seen order, got %+v", history)
+	}
+	periods := history[0].Periods
+	if len(periods) != 2 || periods[1].Period.Start != "2025-01-13" || !periods[1].Achieved {
+		t.Errorf("Expected the second week to be achieved, got %+v", periods)
+	}
+}
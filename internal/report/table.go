@@ -0,0 +1,122 @@
+package report
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+
+	"strava-custom-goals/internal/goals"
+	"strava-custom-goals/internal/models"
+)
+
+// ActivityColumns are the headers of activity tables: every Strava field
+// followed by the calculated ones
+var ActivityColumns = []string{
+	"id", "name", "type", "start_date", "start_date_local", "distance", "moving_time",
+	"elapsed_time", "total_elevation_gain", "average_speed", "max_speed", "has_heartrate",
+	"average_heartrate", "kudos_count", "distance_km", "moving_time_hours", "pace_min_per_km",
+}
+
+// GoalColumns are the headers of goal result tables
+var GoalColumns = []string{
+	"goal", "metric", "unit", "period", "period_start", "period_end",
+	"target", "actual", "percent", "remaining", "achieved", "activities",
+}
+
+// ActivityRows converts activities to table rows matching ActivityColumns
+func ActivityRows(activities []models.Activity) [][]string {
+	rows := make([][]string, 0, len(activities))
+	for _, a := range activities {
+		a.EnhanceWithCalculatedFields()
+		rows = append(rows, []string{
+			strconv.FormatInt(a.ID, 10),
+			a.Name,
+			a.Type,
+			a.StartDate,
+			a.StartDateLocal,
+			formatFloat(a.Distance),
+			strconv.Itoa(a.MovingTime),
+			strconv.Itoa(a.ElapsedTime),
+			formatFloat(a.TotalElevGain),
+			formatFloat(a.AverageSpeed),
+			formatFloat(a.MaxSpeed),
+			strconv.FormatBool(a.HasHeartrate),
+			formatFloat(a.AverageHeartrate),
+			strconv.Itoa(a.Kudos),
+			fmt.Sprintf("%.2f", a.DistanceKm),
+			fmt.Sprintf("%.2f", a.MovingTimeHours),
+			a.PaceMinPerKm,
+		})
+	}
+	return rows
+}
+
+// GoalRows converts goal results to table rows matching GoalColumns
+func GoalRows(results []goals.Result) [][]string {
+	rows := make([][]string, 0, len(results))
+	for _, r := range results {
+		progress := NewGoalProgress(r)
+		rows = append(rows, []string{
+			progress.Name,
+			progress.Metric,
+			progress.Unit,
+			progress.Period.Kind,
+			progress.Period.Start,
+			progress.Period.End,
+			formatFloat(progress.Target),
+			fmt.Sprintf("%.2f", progress.Actual),
+			fmt.Sprintf("%.1f", progress.Percent),
+			fmt.Sprintf("%.2f", progress.Remaining),
+			strconv.FormatBool(progress.Achieved),
+			strconv.Itoa(progress.Activities),
+		})
+	}
+	return rows
+}
+
+// WriteCSV writes a header row followed by rows
+func WriteCSV(w io.Writer, header []string, rows [][]string) error {
+	writer := csv.NewWriter(w)
+	if err := writer.Write(header); err != nil {
+		return fmt.Errorf("write csv header: %w", err)
+	}
+	if err := writer.WriteAll(rows); err != nil {
+		return fmt.Errorf("write csv rows: %w", err)
+	}
+	return nil
+}
+
+// WriteMarkdownTable writes rows as a GitHub-flavoured Markdown table
+func WriteMarkdownTable(w io.Writer, header []string, rows [][]string) error {
+	var b strings.Builder
+	writeMarkdownRow(&b, header)
+	separators := make([]string, len(header))
+	for i := range separators {
+		separators[i] = "---"
+	}
+	writeMarkdownRow(&b, separators)
+	for _, row := range rows {
+		writeMarkdownRow(&b, row)
+	}
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+// writeMarkdownRow writes one table row, escaping cell content
+func writeMarkdownRow(b *strings.Builder, cells []string) {
+	b.WriteString("|")
+	for _, cell := range cells {
+		cell = strings.ReplaceAll(cell, "|", `\|`)
+		cell = strings.ReplaceAll(cell, "<", "&lt;")
+		cell = strings.ReplaceAll(cell, "\n", " ")
+		b.WriteString(" " + cell + " |")
+	}
+	b.WriteString("\n")
+}
+
+// formatFloat formats a raw value without trailing zeros
+func formatFloat(v float64) string {
+	return strconv.FormatFloat(v, 'f', -1, 64)
+}
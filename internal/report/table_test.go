@@ -0,0 +1,69 @@
+package report
+
+import (
+	"bytes"
+	"encoding/csv"
+	"strings"
+	"testing"
+
+	"strava-custom-goals/internal/goals"
+)
+
+func TestActivityRowsMatchColumns(t *testing.T) {
+	rows := ActivityRows(testActivities())
+
+	if len(rows) != 3 {
+		t.Fatalf("Expected 3 rows, got %d", len(rows))
+	}
+	row := rows[0]
+	if len(row) != len(ActivityColumns) {
+		t.Fatalf("Expected %d cells, got %d", len(ActivityColumns), len(row))
+	}
+
+	cells := map[string]string{}
+	for i, column := range ActivityColumns {
+		cells[column] = row[i]
+	}
+	if cells["distance"] != "10000" || cells["distance_km"] != "10.00" || cells["pace_min_per_km"] != "5:00" {
+		t.Errorf("Unexpected distance cells: %v", cells)
+	}
+	if cells["moving_time_hours"] != "0.83" {
+		t.Errorf("Expected 0.83 hours, got %s", cells["moving_time_hours"])
+	}
+}
+
+func TestWriteCSVRoundTrip(t *testing.T) {
+	var buf bytes.Buffer
+	if err := WriteCSV(&buf, GoalColumns, GoalRows([]goals.Result{testResult()})); err != nil {
+		t.Fatalf("WriteCSV failed: %v", err)
+	}
+
+	records, err := csv.NewReader(&buf).ReadAll()
+	if err != nil {
+		t.Fatalf("Output is not valid CSV: %v", err)
+	}
+	if len(records) != 2 {
+		t.Fatalf("Expected header and one row, got %d records", len(records))
+	}
+	expected := []string{"Weekly Running", "distance", "km", "week", "2025-01-06", "2025-01-12", "20", "15.00", "75.0", "5.00", "false", "2"}
+	for i, want := range expected {
+		if records[1][i] != want {
+			t.Errorf("Column %s: expected %q, got %q", GoalColumns[i], want, records[1][i])
+		}
+	}
+}
+
+func TestWriteMarkdownTableEscapesCells(t *testing.T) {
+	var buf bytes.Buffer
+	if err := WriteMarkdownTable(&buf, []string{"name", "type"}, [][]string{{"Hills | Repeats", "Run"}}); err != nil {
+		t.Fatalf("WriteMarkdownTable failed: %v", err)
+	}
+
+	expected := "| name | type |\n| --- | --- |\n| Hills \\| Repeats | Run |\n"
+	if buf.String() != expected {
+		t.Errorf("Expected:\n%s\ngot:\n%s", expected, buf.String())
+	}
+	if strings.Count(buf.String(), "\n") != 3 {
+		t.Errorf("Expected 3 lines, got %q", buf.String())
+	}
+}
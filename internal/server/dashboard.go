@@ -0,0 +1,183 @@
+package server
+
+import (
+	"fmt"
+	"html/template"
+	"io"
+	"math"
+	"time"
+
+	"strava-custom-goals/internal/display"
+	"strava-custom-goals/internal/goals"
+	"strava-custom-goals/internal/models"
+	"strava-custom-goals/internal/report"
+)
+
+// Chart dimensions in SVG user units
+const (
+	chartHeight   = 120.0
+	chartBarWidth = 28.0
+	chartBarGap   = 8.0
+	chartMaxScale = 200.0 // percent; taller bars are clipped
+)
+
+// chart is a bar chart of one goal's percentage per period
+type chart struct {
+	Name    string
+	Width   float64
+	Height  float64
+	TargetY float64 // y of the 100% line
+	Bars    []chartBar
+}
+
+// chartBar is one period in a chart
+type chartBar struct {
+	X, Y, Width, Height float64
+	Label               string
+	Title               string
+	Achieved            bool
+}
+
+// newChart lays out a goal's history as bars scaled to the best period
+func newChart(history report.GoalHistory) chart {
+	scale := 100.0
+	for _, period := range history.Periods {
+		scale = math.Max(scale, period.Percent)
+	}
+	scale = math.Min(scale*1.1, chartMaxScale)
+
+	c := chart{
+		Name:    history.Name,
+		Width:   float64(len(history.Periods))*(chartBarWidth+chartBarGap) + chartBarGap,
+		Height:  chartHeight,
+		TargetY: chartHeight - 100/scale*chartHeight,
+	}
+	for i, period := range history.Periods {
+		height := math.Min(period.Percent, scale) / scale * chartHeight
+		label := period.Period.Start
+		if start, err := time.Parse("2006-01-02", period.Period.Start); err == nil {
+			label = start.Format("Jan 2")
+		}
+		c.Bars = append(c.Bars, chartBar{
+			X:      chartBarGap + float64(i)*(chartBarWidth+chartBarGap),
+			Y:      chartHeight - height,
+			Width:  chartBarWidth,
+			Height: height,
+			Label:  label,
+			Title: fmt.Sprintf("%s – %s: %s (%.0f%%)", period.Period.Start, period.Period.End,
+				display.FormatMetricValue(goals.Metric(history.Metric), period.Actual), period.Percent),
+			Achieved: period.Achieved,
+		})
+	}
+	return c
+}
+
+// renderDashboard writes the dashboard page for a snapshot
+func renderDashboard(w io.Writer, snapshot *Snapshot, refresh time.Duration) error {
+	charts := make([]chart, 0, len(snapshot.History))
+	for _, history := range snapshot.History {
+		if len(history.Periods) > 0 {
+			charts = append(charts, newChart(history))
+		}
+	}
+
+	return dashboardTemplate.Execute(w, struct {
+		*Snapshot
+		Charts         []chart
+		RefreshSeconds int
+	}{snapshot, charts, int(refresh.Seconds())})
+}
+
+// dashboardFuncs are the helpers available to the dashboard template
+var dashboardFuncs = template.FuncMap{
+	"metric": func(name string, value float64) string {
+		return display.FormatMetricValue(goals.Metric(name), value)
+	},
+	"width": func(percent float64) string {
+		return fmt.Sprintf("%.0f%%", math.Max(0, math.Min(percent, 100)))
+	},
+	"target": func(goal report.GoalProgress) string {
+		return display.FormatTarget(goals.Metric(goal.Metric), goal.Target, goal.Max)
+	},
+	"minutes":    display.FormatMinutes,
+	"projection": display.ProjectionLabel,
+	"form":       display.FormLabel,
+	"duration":   models.FormatDuration,
+	"date":       models.FormatDate,
+	"add":        func(a, b float64) float64 { return a + b },
+	"half":       func(v float64) float64 { return v / 2 },
+}
+
+var dashboardTemplate = template.Must(template.New("dashboard").Funcs(dashboardFuncs).Parse(`<!DOCTYPE html>
+<html lang="en">
+<head>
+<meta charset="utf-8">
+{{if .RefreshSeconds}}<meta http-equiv="refresh" content="{{.RefreshSeconds}}">
+{{end}}<title>Strava Goals Dashboard</title>
+<style>
+body { font-family: system-ui, sans-serif; max-width: 64rem; margin: 2rem auto; padding: 0 1rem; color: #222; }
+h1 { color: #fc4c02; }
+.goals { display: grid; grid-template-columns: repeat(auto-fill, minmax(18rem, 1fr)); gap: 1rem; }
+.card { border: 1px solid #ddd; border-radius: 8px; padding: 1rem; }
+.bar { background: #eee; border-radius: 4px; height: 1rem; overflow: hidden; margin-top: 0.5rem; }
+.fill { background: #fc4c02; height: 100%; }
+.achieved .fill { background: #2e9e44; }
+.zones { margin-top: 0.5rem; font-size: 0.85rem; }
+.zone { display: grid; grid-template-columns: 2rem 1fr 6.5rem; gap: 0.5rem; align-items: center; }
+.zone .bar { height: 0.5rem; margin-top: 0; }
+.zone .fill { background: #bbb; }
+.zone.counted .fill { background: #fc4c02; }
+svg .period { fill: #fc4c02; }
+svg .period.achieved { fill: #2e9e44; }
+svg .target { stroke: #555; stroke-dasharray: 4 3; }
+svg text { font-size: 9px; fill: #555; }
+table { border-collapse: collapse; width: 100%; }
+th, td { text-align: left; padding: 0.3rem 0.5rem; border-bottom: 1px solid #ddd; }
+.muted { color: #777; }
+.streaks { list-style: none; padding: 0; }
+.at-risk { color: #c0392b; }
+</style>
+</head>
+<body>
+<h1>Strava Goals Dashboard</h1>
+<p class="muted">As of {{.Report.AsOf.Format "Jan 2, 2006 15:04"}} · loaded {{.LoadedAt.Format "15:04:05"}} · <a href="/api/report">JSON</a></p>
+
+<h2>Current Progress</h2>
+<div class="goals">
+{{range $goal := .Report.Goals}}<div class="card{{if .Achieved}} achieved{{end}}">
+<strong>{{.Name}}</strong> <span class="muted">{{.Period.Start}} – {{.Period.End}}</span><br>
+{{metric .Metric .Actual}} / {{target $goal}} ({{printf "%.1f" .Percent}}%)
+<div class="bar"><div class="fill" style="width: {{width .Percent}}"></div></div>
+{{with .Zones}}<div class="zones"><span class="muted">Time in zones, {{.Range}} counted</span>
+{{range .Zones}}<div class="zone{{if .InGoal}} counted{{end}}"><span>Z{{.Zone}}</span><div class="bar"><div class="fill" style="width: {{width .Percent}}"></div></div><span class="muted">{{printf "%.0f" .Percent}}% · {{minutes .Minutes}}</span></div>
+{{end}}</div>
+{{end}}{{with .Projection}}{{if not $goal.Achieved}}<p class="muted">Projected {{metric $goal.Metric .Projected}} by period end, {{projection .Status}}{{if .RequiredPerDay}} · need {{metric $goal.Metric .RequiredPerDay}}/day for {{.DaysRemaining}} days{{end}}</p>
+{{end}}{{end}}</div>
+{{else}}<p class="muted">No goals configured.</p>
+{{end}}</div>
+<p>{{.Report.Message}}</p>
+{{if .Report.Streaks}}<ul class="streaks">
+{{range .Report.Streaks}}<li{{if .AtRisk}} class="at-risk"{{end}}>{{.Message}}</li>
+{{end}}</ul>
+{{end}}{{with .Report.TrainingLoad}}
+<h2>Training Load</h2>
+<p>Fitness {{printf "%.1f" .Fitness}} · Fatigue {{printf "%.1f" .Fatigue}} · Form {{printf "%+.1f" .Form}}, {{form .FormZone}} · last 7 days {{metric "load" .WeekLoad}}</p>
+{{if .Estimated}}<p class="muted">Activities without heart rate, load estimated: {{.Estimated}}</p>
+{{end}}{{end}}{{if .Charts}}
+<h2>History</h2>
+{{range $chart := .Charts}}<h3>{{.Name}}</h3>
+<svg width="{{.Width}}" height="{{add .Height 14}}" role="img" aria-label="{{.Name}} history">
+{{range .Bars}}<rect class="period{{if .Achieved}} achieved{{end}}" x="{{.X}}" y="{{.Y}}" width="{{.Width}}" height="{{.Height}}"><title>{{.Title}}</title></rect>
+<text x="{{add .X (half .Width)}}" y="{{add $chart.Height 11}}" text-anchor="middle">{{.Label}}</text>
+{{end}}<line class="target" x1="0" x2="{{.Width}}" y1="{{.TargetY}}" y2="{{.TargetY}}"></line>
+</svg>
+{{end}}{{end}}
+<h2>Activities</h2>
+{{if .Report.Activities}}<table>
+<tr><th>Date</th><th>Name</th><th>Type</th><th>Distance</th><th>Moving Time</th><th>Pace</th><th>Elevation</th></tr>
+{{range .Report.Activities}}<tr><td>{{date .StartDateLocal}}</td><td>{{.Name}}</td><td>{{.Type}}</td><td>{{printf "%.2f" .DistanceKm}} km</td><td>{{duration .MovingTime}}</td><td>{{.PaceMinPerKm}}</td><td>{{printf "%.0f" .TotalElevationGain}} m</td></tr>
+{{end}}</table>
+{{else}}<p class="muted">No activities in this window.</p>
+{{end}}</body>
+</html>
+`))
@@ -0,0 +1,183 @@
+// Package server serves a local web dashboard and JSON API for goal progress.
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"log"
+	"net/http"
+	"sync"
+	"time"
+
+	"strava-custom-goals/internal/report"
+)
+
+// Snapshot is the data behind the dashboard at one point in time
+type Snapshot struct {
+	Report   *report.Report       `json:"report"`
+	History  []report.GoalHistory `json:"history"`
+	LoadedAt time.Time            `json:"loaded_at"`
+}
+
+// Loader builds a fresh snapshot, typically from the local activity store
+type Loader func() (*Snapshot, error)
+
+// Server caches the latest snapshot and serves it over HTTP
+type Server struct {
+	load     Loader
+	interval time.Duration
+
+	mu       sync.RWMutex
+	snapshot *Snapshot
+	err      error
+}
+
+// New creates a server that reloads its data every interval
+func New(load Loader, interval time.Duration) *Server {
+	return &Server{load: load, interval: interval}
+}
+
+// Refresh reloads the snapshot. On failure the previous snapshot keeps being
+// served and the error is reported by the API.
+func (s *Server) Refresh() error {
+	snapshot, err := s.load()
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.err = err
+	if err == nil {
+		s.snapshot = snapshot
+	}
+	return err
+}
+
+// Run refreshes the snapshot every interval until ctx is cancelled
+func (s *Server) Run(ctx context.Context) {
+	if s.interval <= 0 {
+		return
+	}
+
+	ticker := time.NewTicker(s.interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if err := s.Refresh(); err != nil {
+				log.Printf("⚠️ Dashboard refresh failed: %v", err)
+			}
+		}
+	}
+}
+
+// current returns the latest snapshot and refresh error
+func (s *Server) current() (*Snapshot, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.snapshot, s.err
+}
+
+// Handler returns the dashboard and API routes
+func (s *Server) Handler() http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/", s.handleDashboard)
+	mux.HandleFunc("/api/report", s.handleReport)
+	mux.HandleFunc("/api/history", s.handleHistory)
+	mux.HandleFunc("/api/status", s.handleStatus)
+	return mux
+}
+
+// ListenAndServe serves on addr until ctx is cancelled
+func (s *Server) ListenAndServe(ctx context.Context, addr string) error {
+	httpServer := &http.Server{Addr: addr, Handler: s.Handler(), ReadHeaderTimeout: 10 * time.Second}
+
+	go s.Run(ctx)
+	go func() {
+		<-ctx.Done()
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		httpServer.Shutdown(shutdownCtx)
+	}()
+
+	if err := httpServer.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+		return err
+	}
+	return nil
+}
+
+// handleDashboard renders the HTML dashboard
+func (s *Server) handleDashboard(w http.ResponseWriter, r *http.Request) {
+	if r.URL.Path != "/" {
+		http.NotFound(w, r)
+		return
+	}
+	snapshot, err := s.current()
+	if snapshot == nil {
+		http.Error(w, "no data loaded yet: "+errorText(err), http.StatusServiceUnavailable)
+		return
+	}
+
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	if err := renderDashboard(w, snapshot, s.interval); err != nil {
+		log.Printf("⚠️ Failed to render dashboard: %v", err)
+	}
+}
+
+// handleReport serves the current report in the versioned JSON schema
+func (s *Server) handleReport(w http.ResponseWriter, r *http.Request) {
+	snapshot, err := s.current()
+	if snapshot == nil {
+		writeJSONError(w, err)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	report.WriteJSON(w, snapshot.Report)
+}
+
+// handleHistory serves each goal's results over past periods
+func (s *Server) handleHistory(w http.ResponseWriter, r *http.Request) {
+	snapshot, err := s.current()
+	if snapshot == nil {
+		writeJSONError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, snapshot.History)
+}
+
+// handleStatus reports when data was last loaded and the last refresh error
+func (s *Server) handleStatus(w http.ResponseWriter, r *http.Request) {
+	snapshot, err := s.current()
+	status := struct {
+		LoadedAt        *time.Time `json:"loaded_at"`
+		RefreshInterval string     `json:"refresh_interval"`
+		Error           string     `json:"error,omitempty"`
+	}{RefreshInterval: s.interval.String(), Error: errorText(err)}
+	if snapshot != nil {
+		status.LoadedAt = &snapshot.LoadedAt
+	}
+	writeJSON(w, http.StatusOK, status)
+}
+
+// writeJSON writes v as a JSON response
+func writeJSON(w http.ResponseWriter, code int, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(code)
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	encoder.Encode(v)
+}
+
+// writeJSONError reports that no snapshot is available yet
+func writeJSONError(w http.ResponseWriter, err error) {
+	writeJSON(w, http.StatusServiceUnavailable, map[string]string{"error": "no data loaded yet: " + errorText(err)})
+}
+
+// errorText returns err's message, or an empty string
+func errorText(err error) string {
+	if err == nil {
+		return ""
+	}
+	return err.Error()
+}
@@ -0,0 +1,120 @@
+package server
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"strava-custom-goals/internal/goals"
+	"strava-custom-goals/internal/models"
+	"strava-custom-goals/internal/report"
+)
+
+func testSnapshot() *Snapshot {
+	week := goals.Every(goals.PeriodWeek)
+	goal := goals.Goal{Name: "Running", Metric: goals.MetricDistance, Period: week, Target: 10}
+	start := time.Date(2025, 10, 6, 0, 0, 0, 0, time.UTC)
+
+	current := goals.Result{Goal: goal, Actual: 6, Activities: 1, PeriodStart: start, PeriodEnd: start.AddDate(0, 0, 7)}
+	previous := goals.Result{Goal: goal, Actual: 12, Activities: 2, PeriodStart: start.AddDate(0, 0, -7), PeriodEnd: start}
+	activities := []models.Activity{
+		{ID: 1, Name: "Lunch Run", Type: "Run", Distance: 6000, MovingTime: 1800,
+			StartDate: "2025-10-07T11:00:00Z", StartDateLocal: "2025-10-07T13:00:00Z"},
+	}
+
+	return &Snapshot{
+		Report:   report.Build([]goals.Result{current}, activities, report.Options{AsOf: start.AddDate(0, 0, 2)}),
+		History:  report.NewHistory([]goals.Result{previous, current}),
+		LoadedAt: start.AddDate(0, 0, 2),
+	}
+}
+
+func get(t *testing.T, s *Server, path string) *httptest.ResponseRecorder {
+	t.Helper()
+	recorder := httptest.NewRecorder()
+	s.Handler().ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, path, nil))
+	return recorder
+}
+
+func TestDashboard(t *testing.T) {
+	s := New(func() (*Snapshot, error) { return testSnapshot(), nil }, time.Minute)
+	if err := s.Refresh(); err != nil {
+		t.Fatalf("Refresh failed: %v", err)
+	}
+
+	response := get(t, s, "/")
+	if response.Code != http.StatusOK {
+		t.Fatalf("Expected 200, got %d", response.Code)
+	}
+	body := response.Body.String()
+	for _, want := range []string{"Running", "6.0 km / 10.0 km", "Lunch Run", "<svg", `content="60"`} {
+		if !strings.Contains(body, want) {
+			t.Errorf("Expected dashboard to contain %q", want)
+		}
+	}
+	if strings.Count(body, `<rect class="period`) != 2 {
+		t.Errorf("Expected 2 history bars, got %d", strings.Count(body, `<rect class="period`))
+	}
+
+	if response := get(t, s, "/missing"); response.Code != http.StatusNotFound {
+		t.Errorf("Expected 404 for unknown paths, got %d", response.Code)
+	}
+}
+
+func TestAPI(t *testing.T) {
+	s := New(func() (*Snapshot, error) { return testSnapshot(), nil }, time.Minute)
+	s.Refresh()
+
+	var rep report.Report
+	if err := json.NewDecoder(get(t, s, "/api/report").Body).Decode(&rep); err != nil {
+		t.Fatalf("Invalid report JSON: %v", err)
+	}
+	if rep.SchemaVersion != report.SchemaVersion || len(rep.Goals) != 1 || rep.Goals[0].Percent != 60 {
+		t.Errorf("Unexpected report: %+v", rep)
+	}
+
+	var history []report.GoalHistory
+	if err := json.NewDecoder(get(t, s, "/api/history").Body).Decode(&history); err != nil {
+		t.Fatalf("Invalid history JSON: %v", err)
+	}
+	if len(history) != 1 || len(history[0].Periods) != 2 || !history[0].Periods[0].Achieved {
+		t.Errorf("Unexpected history: %+v", history)
+	}
+}
+
+func TestRefreshFailureKeepsLastSnapshot(t *testing.T) {
+	var loadErr error = errors.New("database locked")
+	s := New(func() (*Snapshot, error) {
+		if loadErr != nil {
+			return nil, loadErr
+		}
+		return testSnapshot(), nil
+	}, time.Minute)
+
+	// Nothing loaded yet
+	s.Refresh()
+	if response := get(t, s, "/api/report"); response.Code != http.StatusServiceUnavailable {
+		t.Errorf("Expected 503 before the first load, got %d", response.Code)
+	}
+
+	loadErr = nil
+	if err := s.Refresh(); err != nil {
+		t.Fatalf("Refresh failed: %v", err)
+	}
+	loadErr = errors.New("database locked")
+	if err := s.Refresh(); err == nil {
+		t.Fatal("Expected the refresh to fail")
+	}
+
+	if response := get(t, s, "/api/report"); response.Code != http.StatusOK {
+		t.Errorf("Expected the previous snapshot to be served, got %d", response.Code)
+	}
+	status := get(t, s, "/api/status").Body.String()
+	if !strings.Contains(status, "database locked") {
+		t.Errorf("Expected the refresh error in the status, got %s", status)
+	}
+}
@@ -0,0 +1,104 @@
+package store
+
+import "fmt"
+
+// migrations are applied in order; the schema version is kept in PRAGMA
+// user_version. Never edit a released migration, append a new one instead.
+var migrations = []string{
+	// 1: activities keyed by Strava ID, plus sync bookkeeping
+	`CREATE TABLE activities (
+		id                   INTEGER PRIMARY KEY,
+		name                 TEXT    NOT NULL DEFAULT '',
+		type                 TEXT    NOT NULL DEFAULT '',
+		start_date           TEXT    NOT NULL,
+		start_date_local     TEXT    NOT NULL DEFAULT '',
+		distance             REAL    NOT NULL DEFAULT 0,
+		moving_time          INTEGER NOT NULL DEFAULT 0,
+		elapsed_time         INTEGER NOT NULL DEFAULT 0,
+		total_elevation_gain REAL    NOT NULL DEFAULT 0,
+		average_speed        REAL    NOT NULL DEFAULT 0,
+		max_speed            REAL    NOT NULL DEFAULT 0,
+		has_heartrate        INTEGER NOT NULL DEFAULT 0,
+		average_heartrate    REAL    NOT NULL DEFAULT 0,
+		kudos_count          INTEGER NOT NULL DEFAULT 0
+	);
+	CREATE INDEX idx_activities_start_date ON activities (start_date);
+	CREATE INDEX idx_activities_type_start_date ON activities (type, start_date);
+	CREATE TABLE sync_state (
+		id           INTEGER PRIMARY KEY CHECK (id = 1),
+		watermark    TEXT NOT NULL DEFAULT '',
+		last_sync    TEXT NOT NULL DEFAULT '',
+		last_recheck TEXT NOT NULL DEFAULT ''
+	);`,
+	// 2: personal records, one row per record kind
+	`CREATE TABLE personal_records (
+		kind          TEXT    PRIMARY KEY,
+		value         REAL    NOT NULL,
+		activity_id   INTEGER NOT NULL,
+		activity_name TEXT    NOT NULL DEFAULT '',
+		set_at        TEXT    NOT NULL,
+		detected_at   TEXT    NOT NULL,
+		source        TEXT    NOT NULL DEFAULT 'activity'
+	);`,
+	// 3: details from the single-activity endpoint; laps, splits and best
+	// efforts are JSON arrays
+	`CREATE TABLE activity_details (
+		activity_id  INTEGER PRIMARY KEY,
+		calories     REAL    NOT NULL DEFAULT 0,
+		device_name  TEXT    NOT NULL DEFAULT '',
+		gear_id      TEXT    NOT NULL DEFAULT '',
+		gear_name    TEXT    NOT NULL DEFAULT '',
+		laps         TEXT    NOT NULL DEFAULT '[]',
+		splits       TEXT    NOT NULL DEFAULT '[]',
+		best_efforts TEXT    NOT NULL DEFAULT '[]',
+		fetched_at   TEXT    NOT NULL
+	);`,
+	// 4: activity streams as gzip-compressed JSON, one series per key
+	`CREATE TABLE activity_streams (
+		activity_id INTEGER PRIMARY KEY,
+		data        BLOB    NOT NULL,
+		fetched_at  TEXT    NOT NULL
+	);`,
+	// 5: where each activity came from; only Strava ones are synced
+	`ALTER TABLE activities ADD COLUMN source TEXT NOT NULL DEFAULT 'strava';`,
+	// 6: record efforts measured from the streams as JSON; NULL until measured
+	`ALTER TABLE activity_streams ADD COLUMN efforts TEXT;`,
+}
+
+// migrate applies any migrations newer than the database's schema version
+func (s *Store) migrate() error {
+	var version int
+	if err := s.db.QueryRow("PRAGMA user_version").Scan(&version); err != nil {
+		return fmt.Errorf("read schema version: %w", err)
+	}
+	if version > len(migrations) {
+		return fmt.Errorf("database schema version %d is newer than this binary supports (%d)", version, len(migrations))
+	}
+
+	for i := version; i < len(migrations); i++ {
+		tx, err := s.db.Begin()
+		if err != nil {
+			return fmt.Errorf("begin migration %d: %w", i+1, err)
+		}
+		if _, err := tx.Exec(migrations[i]); err != nil {
+			tx.Rollback()
+			return fmt.Errorf("apply migration %d: %w", i+1, err)
+		}
+		// PRAGMA does not accept bound parameters
+		if _, err := tx.Exec(fmt.Sprintf("PRAGMA user_version = %d", i+1)); err != nil {
+			tx.Rollback()
+			return fmt.Errorf("record migration %d: %w", i+1, err)
+		}
+		if err := tx.Commit(); err != nil {
+			return fmt.Errorf("commit migration %d: %w", i+1, err)
+		}
+	}
+	return nil
+}
+
+// SchemaVersion returns the applied schema version
+func (s *Store) SchemaVersion() (int, error) {
+	var version int
+	err := s.db.QueryRow("PRAGMA user_version").Scan(&version)
+	return version, err
+}
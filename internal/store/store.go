@@ -0,0 +1,519 @@
+// Package store provides a durable SQLite-backed database of Strava activities
+// that can be queried for arbitrary date ranges without touching the network.
+package store
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	_ "modernc.org/sqlite" // pure-Go SQLite driver
+
+	"strava-custom-goals/internal/models"
+)
+
+// Store is the local activity database
+type Store struct {
+	db   *sql.DB
+	path string
+}
+
+// Query selects activities by start time and type. Zero values leave a
+// constraint open.
+type Query struct {
+	After  time.Time // started strictly after
+	Before time.Time // started strictly before
+	Types  []string  // activity types to include; empty means all
+	Limit  int       // maximum rows; 0 means unlimited
+
+	Imported bool // only activities imported from files rather than synced
+}
+
+// activityColumns lists the activity columns in scan order
+const activityColumns = `id, name, type, start_date, start_date_local, distance, moving_time,
+	elapsed_time, total_elevation_gain, average_speed, max_speed, has_heartrate,
+	average_heartrate, kudos_count, source`
+
+// detailColumns lists the activity detail columns in scan order, defaulted
+// for activities whose details have not been fetched
+const detailColumns = `COALESCE(calories, 0), COALESCE(device_name, ''), COALESCE(gear_id, ''),
+	COALESCE(gear_name, ''), COALESCE(laps, ''), COALESCE(splits, ''), COALESCE(best_efforts, ''),
+	COALESCE(fetched_at, '')`
+
+// Open opens (creating if needed) the database at path and applies pending migrations
+func Open(path string) (*Store, error) {
+	if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+		return nil, fmt.Errorf("create database directory: %w", err)
+	}
+
+	db, err := sql.Open("sqlite", path+"?_pragma=busy_timeout(5000)&_pragma=journal_mode(WAL)")
+	if err != nil {
+		return nil, fmt.Errorf("open database: %w", err)
+	}
+	// SQLite allows a single writer; one connection avoids SQLITE_BUSY within the process
+	db.SetMaxOpenConns(1)
+
+	s := &Store{db: db, path: path}
+	if err := s.migrate(); err != nil {
+		db.Close()
+		return nil, err
+	}
+	return s, nil
+}
+
+// Close closes the database
+func (s *Store) Close() error {
+	return s.db.Close()
+}
+
+// Path returns the database file location
+func (s *Store) Path() string {
+	return s.path
+}
+
+// Activities returns activities matching the query, newest first
+func (s *Store) Activities(q Query) ([]models.Activity, error) {
+	var (
+		conditions []string
+		args       []interface{}
+	)
+	if !q.After.IsZero() {
+		conditions = append(conditions, "start_date > ?")
+		args = append(args, formatTime(q.After))
+	}
+	if !q.Before.IsZero() {
+		conditions = append(conditions, "start_date < ?")
+		args = append(args, formatTime(q.Before))
+	}
+	if q.Imported {
+		conditions = append(conditions, "source != ?")
+		args = append(args, models.SourceStrava)
+	}
+	if len(q.Types) > 0 {
+		placeholders := strings.TrimSuffix(strings.Repeat("?,", len(q.Types)), ",")
+		conditions = append(conditions, "type IN ("+placeholders+")")
+		for _, t := range q.Types {
+			args = append(args, t)
+		}
+	}
+
+	query := "SELECT " + activityColumns + ", " + detailColumns +
+		" FROM activities LEFT JOIN activity_details ON activity_details.activity_id = activities.id"
+	if len(conditions) > 0 {
+		query += " WHERE " + strings.Join(conditions, " AND ")
+	}
+	query += " ORDER BY start_date DESC, id DESC"
+	if q.Limit > 0 {
+		query += fmt.Sprintf(" LIMIT %d", q.Limit)
+	}
+
+	rows, err := s.db.Query(query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("query activities: %w", err)
+	}
+	defer rows.Close()
+
+	var activities []models.Activity
+	for rows.Next() {
+		var (
+			a                                       models.Activity
+			gearID, gearName, laps, splits, efforts string
+			fetchedAt                               string
+		)
+		if err := rows.Scan(&a.ID, &a.Name, &a.Type, &a.StartDate, &a.StartDateLocal, &a.Distance,
+			&a.MovingTime, &a.ElapsedTime, &a.TotalElevGain, &a.AverageSpeed, &a.MaxSpeed,
+			&a.HasHeartrate, &a.AverageHeartrate, &a.Kudos, &a.Source,
+			&a.Calories, &a.DeviceName, &gearID, &gearName, &laps, &splits, &efforts, &fetchedAt); err != nil {
+			return nil, fmt.Errorf("scan activity: %w", err)
+		}
+
+		if fetchedAt != "" {
+			a.DetailsFetchedAt = parseTime(fetchedAt)
+			if gearID != "" {
+				a.Gear = &models.Gear{ID: gearID, Name: gearName}
+			}
+			for _, column := range []struct {
+				raw    string
+				target interface{}
+			}{{laps, &a.Laps}, {splits, &a.Splits}, {efforts, &a.BestEfforts}} {
+				if err := json.Unmarshal([]byte(column.raw), column.target); err != nil {
+					return nil, fmt.Errorf("decode details of activity %d: %w", a.ID, err)
+				}
+			}
+		}
+		activities = append(activities, a)
+	}
+	return activities, rows.Err()
+}
+
+// ActivitiesSince returns activities that started after t, newest first
+func (s *Store) ActivitiesSince(t time.Time) ([]models.Activity, error) {
+	return s.Activities(Query{After: t})
+}
+
+// Count returns the number of stored activities
+func (s *Store) Count() (int, error) {
+	var n int
+	if err := s.db.QueryRow("SELECT COUNT(*) FROM activities").Scan(&n); err != nil {
+		return 0, fmt.Errorf("count activities: %w", err)
+	}
+	return n, nil
+}
+
+// ActivitiesBetween returns activities that started strictly between after
+// and before, newest first
+func (s *Store) ActivitiesBetween(after, before time.Time) ([]models.Activity, error) {
+	return s.Activities(Query{After: after, Before: before})
+}
+
+// ImportedActivities returns the activities imported from files, newest first
+func (s *Store) ImportedActivities() ([]models.Activity, error) {
+	return s.Activities(Query{Imported: true})
+}
+
+// UpsertActivities inserts or replaces activities by ID. Activities without
+// a source are recorded as synchronised from Strava. When an edit changes an
+// activity's name, type, start or totals, its stored details are dropped so
+// the next sync fetches them again.
+func (s *Store) UpsertActivities(activities []models.Activity) (added, updated int, err error) {
+	tx, err := s.db.Begin()
+	if err != nil {
+		return 0, 0, fmt.Errorf("begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	if added, updated, err = upsertActivities(tx, activities); err != nil {
+		return 0, 0, err
+	}
+	if err := tx.Commit(); err != nil {
+		return 0, 0, fmt.Errorf("commit activities: %w", err)
+	}
+	return added, updated, nil
+}
+
+// SaveImport stores an imported activity together with its streams, so a
+// failure leaves neither behind
+func (s *Store) SaveImport(a models.Activity, streams models.Streams, fetchedAt time.Time) error {
+	tx, err := s.db.Begin()
+	if err != nil {
+		return fmt.Errorf("begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	if _, _, err := upsertActivities(tx, []models.Activity{a}); err != nil {
+		return err
+	}
+	if err := saveStreams(tx, streams, fetchedAt); err != nil {
+		return err
+	}
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("commit import: %w", err)
+	}
+	return nil
+}
+
+// upsertActivities implements UpsertActivities within tx
+func upsertActivities(tx *sql.Tx, activities []models.Activity) (added, updated int, err error) {
+	lookup, err := tx.Prepare(`SELECT name, type, start_date, distance, moving_time, elapsed_time,
+		total_elevation_gain FROM activities WHERE id = ?`)
+	if err != nil {
+		return 0, 0, fmt.Errorf("prepare lookup: %w", err)
+	}
+	defer lookup.Close()
+
+	dropDetails, err := tx.Prepare("DELETE FROM activity_details WHERE activity_id = ?")
+	if err != nil {
+		return 0, 0, fmt.Errorf("prepare details deletion: %w", err)
+	}
+	defer dropDetails.Close()
+
+	upsert, err := tx.Prepare(`INSERT INTO activities (` + activityColumns + `)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+		ON CONFLICT(id) DO UPDATE SET
+			name = excluded.name, type = excluded.type, start_date = excluded.start_date,
+			start_date_local = excluded.start_date_local, distance = excluded.distance,
+			moving_time = excluded.moving_time, elapsed_time = excluded.elapsed_time,
+			total_elevation_gain = excluded.total_elevation_gain, average_speed = excluded.average_speed,
+			max_speed = excluded.max_speed, has_heartrate = excluded.has_heartrate,
+			average_heartrate = excluded.average_heartrate, kudos_count = excluded.kudos_count,
+			source = excluded.source`)
+	if err != nil {
+		return 0, 0, fmt.Errorf("prepare upsert: %w", err)
+	}
+	defer upsert.Close()
+
+	for _, a := range activities {
+		start, err := a.StartTime()
+		if err != nil {
+			return 0, 0, fmt.Errorf("activity %d: invalid start date %q", a.ID, a.StartDate)
+		}
+
+		var stored models.Activity
+		switch err := lookup.QueryRow(a.ID).Scan(&stored.Name, &stored.Type, &stored.StartDate, &stored.Distance,
+			&stored.MovingTime, &stored.ElapsedTime, &stored.TotalElevGain); {
+		case errors.Is(err, sql.ErrNoRows):
+			added++
+		case err != nil:
+			return 0, 0, fmt.Errorf("look up activity %d: %w", a.ID, err)
+		default:
+			updated++
+			if stored.Name != a.Name || stored.Type != a.Type || stored.StartDate != formatTime(start) ||
+				stored.Distance != a.Distance || stored.MovingTime != a.MovingTime ||
+				stored.ElapsedTime != a.ElapsedTime || stored.TotalElevGain != a.TotalElevGain {
+				if _, err := dropDetails.Exec(a.ID); err != nil {
+					return 0, 0, fmt.Errorf("drop details of activity %d: %w", a.ID, err)
+				}
+			}
+		}
+
+		source := a.Source
+		if source == "" {
+			source = models.SourceStrava
+		}
+		if _, err := upsert.Exec(a.ID, a.Name, a.Type, formatTime(start), a.StartDateLocal, a.Distance,
+			a.MovingTime, a.ElapsedTime, a.TotalElevGain, a.AverageSpeed, a.MaxSpeed,
+			a.HasHeartrate, a.AverageHeartrate, a.Kudos, source); err != nil {
+			return 0, 0, fmt.Errorf("upsert activity %d: %w", a.ID, err)
+		}
+	}
+	return added, updated, nil
+}
+
+// DeleteActivitiesAfter removes Strava activities that started after t and
+// whose IDs are not in keep; used to drop activities deleted on Strava.
+// Imported activities are never removed.
+func (s *Store) DeleteActivitiesAfter(t time.Time, keep map[int64]bool) (int, error) {
+	rows, err := s.db.Query("SELECT id FROM activities WHERE start_date > ? AND source = ?",
+		formatTime(t), models.SourceStrava)
+	if err != nil {
+		return 0, fmt.Errorf("query activities: %w", err)
+	}
+	var stale []int64
+	for rows.Next() {
+		var id int64
+		if err := rows.Scan(&id); err != nil {
+			rows.Close()
+			return 0, fmt.Errorf("scan activity id: %w", err)
+		}
+		if !keep[id] {
+			stale = append(stale, id)
+		}
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return 0, err
+	}
+
+	if err := s.DeleteActivities(stale); err != nil {
+		return 0, err
+	}
+	return len(stale), nil
+}
+
+// DeleteActivities removes activities with their details and streams in one
+// transaction, so a failure leaves no orphaned rows
+func (s *Store) DeleteActivities(ids []int64) error {
+	tx, err := s.db.Begin()
+	if err != nil {
+		return fmt.Errorf("begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	for _, id := range ids {
+		if _, err := tx.Exec("DELETE FROM activities WHERE id = ?", id); err != nil {
+			return fmt.Errorf("delete activity %d: %w", id, err)
+		}
+		if _, err := tx.Exec("DELETE FROM activity_details WHERE activity_id = ?", id); err != nil {
+			return fmt.Errorf("delete details of activity %d: %w", id, err)
+		}
+		if _, err := tx.Exec("DELETE FROM activity_streams WHERE activity_id = ?", id); err != nil {
+			return fmt.Errorf("delete streams of activity %d: %w", id, err)
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("commit deletions: %w", err)
+	}
+	return nil
+}
+
+// ActivitiesWithoutDetails returns the IDs of up to limit stored Strava
+// activities whose details have not been fetched, newest first
+func (s *Store) ActivitiesWithoutDetails(limit int) ([]int64, error) {
+	rows, err := s.db.Query(`SELECT id FROM activities
+		WHERE source = ? AND id NOT IN (SELECT activity_id FROM activity_details)
+		ORDER BY start_date DESC, id DESC LIMIT ?`, models.SourceStrava, limit)
+	if err != nil {
+		return nil, fmt.Errorf("query activities without details: %w", err)
+	}
+	defer rows.Close()
+
+	var ids []int64
+	for rows.Next() {
+		var id int64
+		if err := rows.Scan(&id); err != nil {
+			return nil, fmt.Errorf("scan activity id: %w", err)
+		}
+		ids = append(ids, id)
+	}
+	return ids, rows.Err()
+}
+
+// SaveActivityDetails stores the detailed fields of an activity fetched on
+// its own, replacing any stored earlier
+func (s *Store) SaveActivityDetails(a models.Activity, fetchedAt time.Time) error {
+	var gearID, gearName string
+	if a.Gear != nil {
+		gearID, gearName = a.Gear.ID, a.Gear.Name
+	}
+
+	encoded := make([]string, 3)
+	for i, value := range []interface{}{a.Laps, a.Splits, a.BestEfforts} {
+		raw, err := json.Marshal(value)
+		if err != nil {
+			return fmt.Errorf("encode details of activity %d: %w", a.ID, err)
+		}
+		encoded[i] = string(raw)
+	}
+
+	_, err := s.db.Exec(`INSERT INTO activity_details
+		(activity_id, calories, device_name, gear_id, gear_name, laps, splits, best_efforts, fetched_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?)
+		ON CONFLICT(activity_id) DO UPDATE SET calories = excluded.calories,
+			device_name = excluded.device_name, gear_id = excluded.gear_id, gear_name = excluded.gear_name,
+			laps = excluded.laps, splits = excluded.splits, best_efforts = excluded.best_efforts,
+			fetched_at = excluded.fetched_at`,
+		a.ID, a.Calories, a.DeviceName, gearID, gearName, encoded[0], encoded[1], encoded[2], formatTime(fetchedAt))
+	if err != nil {
+		return fmt.Errorf("save details of activity %d: %w", a.ID, err)
+	}
+	return nil
+}
+
+// SyncState returns the stored sync bookkeeping
+func (s *Store) SyncState() (models.SyncState, error) {
+	var watermark, lastSync, lastRecheck string
+	err := s.db.QueryRow("SELECT watermark, last_sync, last_recheck FROM sync_state WHERE id = 1").
+		Scan(&watermark, &lastSync, &lastRecheck)
+	if errors.Is(err, sql.ErrNoRows) {
+		return models.SyncState{}, nil
+	}
+	if err != nil {
+		return models.SyncState{}, fmt.Errorf("read sync state: %w", err)
+	}
+
+	return models.SyncState{
+		Watermark:   parseTime(watermark),
+		LastSync:    parseTime(lastSync),
+		LastRecheck: parseTime(lastRecheck),
+	}, nil
+}
+
+// SaveSyncState stores the sync bookkeeping
+func (s *Store) SaveSyncState(state models.SyncState) error {
+	_, err := s.db.Exec(`INSERT INTO sync_state (id, watermark, last_sync, last_recheck) VALUES (1, ?, ?, ?)
+		ON CONFLICT(id) DO UPDATE SET watermark = excluded.watermark,
+			last_sync = excluded.last_sync, last_recheck = excluded.last_recheck`,
+		formatTime(state.Watermark), formatTime(state.LastSync), formatTime(state.LastRecheck))
+	if err != nil {
+		return fmt.Errorf("save sync state: %w", err)
+	}
+	return nil
+}
+
+// PersonalRecords returns the stored personal records ordered by kind
+func (s *Store) PersonalRecords() ([]models.PersonalRecord, error) {
+	rows, err := s.db.Query(`SELECT kind, value, activity_id, activity_name, set_at, detected_at, source
+		FROM personal_records ORDER BY kind`)
+	if err != nil {
+		return nil, fmt.Errorf("query personal records: %w", err)
+	}
+	defer rows.Close()
+
+	var records []models.PersonalRecord
+	for rows.Next() {
+		var (
+			record            models.PersonalRecord
+			setAt, detectedAt string
+		)
+		if err := rows.Scan(&record.Kind, &record.Value, &record.ActivityID, &record.ActivityName,
+			&setAt, &detectedAt, &record.Source); err != nil {
+			return nil, fmt.Errorf("scan personal record: %w", err)
+		}
+		record.SetAt = parseTime(setAt)
+		record.DetectedAt = parseTime(detectedAt)
+		records = append(records, record)
+	}
+	return records, rows.Err()
+}
+
+// ReplacePersonalRecords stores records in place of all existing ones
+func (s *Store) ReplacePersonalRecords(records []models.PersonalRecord) error {
+	tx, err := s.db.Begin()
+	if err != nil {
+		return fmt.Errorf("begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.Exec("DELETE FROM personal_records"); err != nil {
+		return fmt.Errorf("clear personal records: %w", err)
+	}
+	for _, record := range records {
+		_, err := tx.Exec(`INSERT INTO personal_records (kind, value, activity_id, activity_name, set_at, detected_at, source)
+			VALUES (?, ?, ?, ?, ?, ?, ?)`,
+			record.Kind, record.Value, record.ActivityID, record.ActivityName,
+			formatTime(record.SetAt), formatTime(record.DetectedAt), record.Source)
+		if err != nil {
+			return fmt.Errorf("save personal record %s: %w", record.Kind, err)
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("commit personal records: %w", err)
+	}
+	return nil
+}
+
+// ImportJSONCache seeds the database from the legacy activities.json cache
+// written by earlier versions. It returns the number of activities imported.
+func (s *Store) ImportJSONCache(file string) (int, error) {
+	raw, err := os.ReadFile(file)
+	if errors.Is(err, os.ErrNotExist) {
+		return 0, nil
+	}
+	if err != nil {
+		return 0, fmt.Errorf("read legacy cache: %w", err)
+	}
+
+	var legacy struct {
+		Activities []models.Activity `json:"activities"`
+	}
+	if err := json.Unmarshal(raw, &legacy); err != nil {
+		return 0, fmt.Errorf("decode legacy cache: %w", err)
+	}
+
+	added, _, err := s.UpsertActivities(legacy.Activities)
+	return added, err
+}
+
+// formatTime renders t as a sortable UTC RFC 3339 string; zero times become ""
+func formatTime(t time.Time) string {
+	if t.IsZero() {
+		return ""
+	}
+	return t.UTC().Format(time.RFC3339)
+}
+
+// parseTime is the inverse of formatTime
+func parseTime(s string) time.Time {
+	t, err := time.Parse(time.RFC3339, s)
+	if err != nil {
+		return time.Time{}
+	}
+	return t
+}
@@ -0,0 +1,267 @@
+package store
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"strava-custom-goals/internal/models"
+)
+
+func openTestStore(t *testing.T) *Store {
+	t.Helper()
+	s, err := Open(filepath.Join(t.TempDir(), "activities.db"))
+	if err != nil {
+		t.Fatalf("Open returned error: %v", err)
+	}
+	t.Cleanup(func() { s.Close() })
+	return s
+}
+
+func TestStoreQueriesByDateRangeAndType(t *testing.T) {
+	s := openTestStore(t)
+
+	if version, _ := s.SchemaVersion(); version != len(migrations) {
+		t.Errorf("Expected schema version %d, got %d", len(migrations), version)
+	}
+
+	base := time.Date(2023, 1, 2, 7, 0, 0, 0, time.UTC)
+	activities := []models.Activity{
+		{ID: 1, Type: "Run", Distance: 5000, StartDate: base.Format(time.RFC3339)},
+		{ID: 2, Type: "Ride", Distance: 30000, StartDate: base.AddDate(1, 0, 0).Format(time.RFC3339)},
+		{ID: 3, Type: "Run", Distance: 10000, StartDate: base.AddDate(2, 0, 0).Format(time.RFC3339)},
+		// Non-UTC offsets are normalised so range queries stay correct
+		{ID: 4, Type: "Run", Distance: 8000, StartDate: "2025-06-01T08:00:00+02:00"},
+	}
+	added, updated, err := s.UpsertActivities(activities)
+	if err != nil {
+		t.Fatalf("UpsertActivities returned error: %v", err)
+	}
+	if added != 4 || updated != 0 {
+		t.Errorf("Expected 4 added, 0 updated, got %d, %d", added, updated)
+	}
+
+	runs, err := s.Activities(Query{Types: []string{"Run"}})
+	if err != nil {
+		t.Fatalf("Activities returned error: %v", err)
+	}
+	if len(runs) != 3 || runs[0].ID != 4 || runs[2].ID != 1 {
+		t.Errorf("Expected runs 4, 3, 1 newest first, got %+v", runs)
+	}
+
+	window, err := s.Activities(Query{After: base.AddDate(0, 6, 0), Before: base.AddDate(2, 1, 0)})
+	if err != nil {
+		t.Fatalf("Activities returned error: %v", err)
+	}
+	if len(window) != 2 || window[0].ID != 3 || window[1].ID != 2 {
+		t.Errorf("Expected activities 3 and 2 in window, got %+v", window)
+	}
+
+	// Updating an existing ID replaces it rather than duplicating it
+	activities[0].Name = "Renamed"
+	if added, updated, _ = s.UpsertActivities(activities[:1]); added != 0 || updated != 1 {
+		t.Errorf("Expected 0 added, 1 updated, got %d, %d", added, updated)
+	}
+	if count, _ := s.Count(); count != 4 {
+		t.Errorf("Expected 4 stored activities, got %d", count)
+	}
+
+	deleted, err := s.DeleteActivitiesAfter(base.AddDate(0, 6, 0), map[int64]bool{3: true})
+	if err != nil {
+		t.Fatalf("DeleteActivitiesAfter returned error: %v", err)
+	}
+	if deleted != 2 {
+		t.Errorf("Expected 2 deleted activities, got %d", deleted)
+	}
+}
+
+func TestStorePersistsSyncStateAcrossReopen(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "activities.db")
+	s, err := Open(path)
+	if err != nil {
+		t.Fatalf("Open returned error: %v", err)
+	}
+
+	state := models.SyncState{
+		Watermark:   time.Date(2025, 10, 6, 7, 0, 0, 0, time.UTC),
+		LastSync:    time.Date(2025, 10, 6, 9, 0, 0, 0, time.UTC),
+		LastRecheck: time.Date(2025, 10, 5, 9, 0, 0, 0, time.UTC),
+	}
+	if err := s.SaveSyncState(state); err != nil {
+		t.Fatalf("SaveSyncState returned error: %v", err)
+	}
+	s.Close()
+
+	s, err = Open(path)
+	if err != nil {
+		t.Fatalf("Reopen returned error: %v", err)
+	}
+	defer s.Close()
+
+	loaded, err := s.SyncState()
+	if err != nil {
+		t.Fatalf("SyncState returned error: %v", err)
+	}
+	if loaded != state {
+		t.Errorf("Expected %+v, got %+v", state, loaded)
+	}
+}
+
+func TestImportJSONCache(t *testing.T) {
+	s := openTestStore(t)
+
+	legacy := filepath.Join(t.TempDir(), "activities.json")
+	data := `{"activities":[{"id":7,"type":"Run","start_date":"2025-10-01T07:00:00Z"}],"timestamp":"2025-10-01T08:00:00Z"}`
+	if err := os.WriteFile(legacy, []byte(data), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	imported, err := s.ImportJSONCache(legacy)
+	if err != nil {
+		t.Fatalf("ImportJSONCache returned error: %v", err)
+	}
+	if imported != 1 {
+		t.Errorf("Expected 1 imported activity, got %d", imported)
+	}
+
+	if imported, err := s.ImportJSONCache(filepath.Join(t.TempDir(), "missing.json")); err != nil || imported != 0 {
+		t.Errorf("Expected missing legacy cache to be ignored, got %d, %v", imported, err)
+	}
+}
+
+func TestPersonalRecordsReplaceAll(t *testing.T) {
+	s := openTestStore(t)
+	setAt := time.Date(2025, 10, 5, 7, 0, 0, 0, time.UTC)
+	detectedAt := time.Date(2025, 10, 5, 12, 0, 0, 0, time.UTC)
+
+	first := []models.PersonalRecord{
+		{Kind: "fastest_5k", Value: 1350, ActivityID: 7, ActivityName: "Parkrun", SetAt: setAt, DetectedAt: detectedAt, Source: "best_effort"},
+		{Kind: "longest_run", Value: 21100, ActivityID: 8, ActivityName: "Long Run", SetAt: setAt, DetectedAt: detectedAt, Source: "activity"},
+	}
+	if err := s.ReplacePersonalRecords(first); err != nil {
+		t.Fatalf("ReplacePersonalRecords failed: %v", err)
+	}
+	if err := s.ReplacePersonalRecords(first[:1]); err != nil {
+		t.Fatalf("ReplacePersonalRecords failed: %v", err)
+	}
+
+	records, err := s.PersonalRecords()
+	if err != nil {
+		t.Fatalf("PersonalRecords failed: %v", err)
+	}
+	if len(records) != 1 {
+		t.Fatalf("Expected 1 record after replacing, got %d", len(records))
+	}
+	if records[0] != first[0] {
+		t.Errorf("Expected %+v, got %+v", first[0], records[0])
+	}
+}
+
+func TestActivityDetailsAreJoinedAndSurviveUpserts(t *testing.T) {
+	s := openTestStore(t)
+	base := []models.Activity{
+		{ID: 1, Name: "Easy", Type: "Run", StartDate: "2025-10-01T07:00:00Z"},
+		{ID: 2, Name: "Tempo", Type: "Run", StartDate: "2025-10-03T07:00:00Z"},
+	}
+	if _, _, err := s.UpsertActivities(base); err != nil {
+		t.Fatalf("UpsertActivities failed: %v", err)
+	}
+
+	missing, err := s.ActivitiesWithoutDetails(10)
+	if err != nil || len(missing) != 2 || missing[0] != 2 {
+		t.Fatalf("Expected both activities without details, newest first, got %v, %v", missing, err)
+	}
+
+	detailed := base[1]
+	detailed.Calories = 540
+	detailed.DeviceName = "Garmin"
+	detailed.Gear = &models.Gear{ID: "g1", Name: "Pegasus"}
+	detailed.Splits = []models.Split{{Split: 1, Distance: 1000, MovingTime: 290}}
+	detailed.BestEfforts = []models.BestEffort{{Name: "1k", Distance: 1000, ElapsedTime: 285}}
+	fetchedAt := time.Date(2025, 10, 4, 9, 0, 0, 0, time.UTC)
+	if err := s.SaveActivityDetails(detailed, fetchedAt); err != nil {
+		t.Fatalf("SaveActivityDetails failed: %v", err)
+	}
+
+	// A later list sync must not wipe the details unless the activity changed
+	base[1].Kudos = 3
+	if _, _, err := s.UpsertActivities(base); err != nil {
+		t.Fatalf("UpsertActivities failed: %v", err)
+	}
+
+	activities, err := s.Activities(Query{})
+	if err != nil {
+		t.Fatalf("Activities failed: %v", err)
+	}
+	tempo, easy := activities[0], activities[1]
+	if tempo.Kudos != 3 || !tempo.DetailsFetchedAt.Equal(fetchedAt) || tempo.Calories != 540 ||
+		tempo.Gear == nil || tempo.Gear.Name != "Pegasus" || len(tempo.Splits) != 1 || len(tempo.BestEfforts) != 1 {
+		t.Errorf("Expected stored details on the tempo run, got %+v", tempo)
+	}
+	if easy.HasDetails() || easy.Splits != nil {
+		t.Errorf("Expected no details on the easy run, got %+v", easy)
+	}
+
+	missing, _ = s.ActivitiesWithoutDetails(10)
+	if len(missing) != 1 || missing[0] != 1 {
+		t.Errorf("Expected only activity 1 without details, got %v", missing)
+	}
+
+	// Renaming the activity on Strava has its details fetched again
+	base[1].Name = "Tempo (renamed)"
+	if _, _, err := s.UpsertActivities(base); err != nil {
+		t.Fatalf("UpsertActivities failed: %v", err)
+	}
+	if missing, _ = s.ActivitiesWithoutDetails(10); len(missing) != 2 {
+		t.Errorf("Expected the renamed activity to lack details again, got %v", missing)
+	}
+}
+
+func TestImportedActivitiesAreNeitherSyncedNorDeleted(t *testing.T) {
+	s := openTestStore(t)
+
+	base := time.Date(2025, 10, 6, 7, 0, 0, 0, time.UTC)
+	s.UpsertActivities([]models.Activity{
+		{ID: 1, Type: "Run", StartDate: base.Format(time.RFC3339)},
+		{ID: -base.Unix() - 3600, Type: "Run", Source: "gpx", StartDate: base.Add(time.Hour).Format(time.RFC3339)},
+	})
+
+	activities, err := s.ActivitiesBetween(base.Add(-time.Minute), base.Add(2*time.Hour))
+	if err != nil || len(activities) != 2 {
+		t.Fatalf("Expected 2 activities, got %d, %v", len(activities), err)
+	}
+	if activities[0].Source != "gpx" || activities[1].Source != models.SourceStrava {
+		t.Errorf("Expected sources gpx and strava, got %q and %q", activities[0].Source, activities[1].Source)
+	}
+
+	if ids, _ := s.ActivitiesWithoutDetails(10); len(ids) != 1 || ids[0] != 1 {
+		t.Errorf("Expected only the Strava activity to need details, got %v", ids)
+	}
+	if ids, _ := s.ActivitiesWithoutStreams(10); len(ids) != 1 || ids[0] != 1 {
+		t.Errorf("Expected only the Strava activity to need streams, got %v", ids)
+	}
+
+	// A re-check that no longer sees activity 1 drops it but keeps the import
+	deleted, err := s.DeleteActivitiesAfter(base.Add(-time.Hour), map[int64]bool{})
+	if err != nil || deleted != 1 {
+		t.Errorf("Expected 1 deleted, got %d, %v", deleted, err)
+	}
+	if n, _ := s.Count(); n != 1 {
+		t.Errorf("Expected the imported activity to remain, got %d activities", n)
+	}
+}
+
+func TestSaveImportStoresActivityWithStreams(t *testing.T) {
+	s := openTestStore(t)
+	imported := models.Activity{ID: -7, Name: "Watch run", Type: "Run", Source: "fit", StartDate: "2025-10-01T07:00:00Z"}
+	if err := s.SaveImport(imported, models.Streams{ActivityID: -7, Time: []int{0, 1, 2}}, time.Now()); err != nil {
+		t.Fatalf("SaveImport failed: %v", err)
+	}
+	if n, _ := s.Count(); n != 1 {
+		t.Errorf("Expected the imported activity to be stored, got %d activities", n)
+	}
+	if streams, err := s.Streams(-7); err != nil || streams == nil || streams.Len() != 3 {
+		t.Errorf("Expected its 3 samples to be stored, got %+v, %v", streams, err)
+	}
+}
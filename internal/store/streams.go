@@ -0,0 +1,159 @@
+package store
+
+import (
+	"bytes"
+	"compress/gzip"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"time"
+
+	"strava-custom-goals/internal/models"
+)
+
+// ActivitiesWithoutStreams returns the IDs of up to limit stored Strava
+// activities whose streams have not been fetched, newest first
+func (s *Store) ActivitiesWithoutStreams(limit int) ([]int64, error) {
+	rows, err := s.db.Query(`SELECT id FROM activities
+		WHERE source = ? AND id NOT IN (SELECT activity_id FROM activity_streams)
+		ORDER BY start_date DESC, id DESC LIMIT ?`, models.SourceStrava, limit)
+	if err != nil {
+		return nil, fmt.Errorf("query activities without streams: %w", err)
+	}
+	defer rows.Close()
+
+	var ids []int64
+	for rows.Next() {
+		var id int64
+		if err := rows.Scan(&id); err != nil {
+			return nil, fmt.Errorf("scan activity id: %w", err)
+		}
+		ids = append(ids, id)
+	}
+	return ids, rows.Err()
+}
+
+// SaveStreams stores an activity's streams, replacing any stored earlier
+// along with the efforts measured from them. Empty streams are stored too,
+// so activities without recorded data are not fetched again.
+func (s *Store) SaveStreams(streams models.Streams, fetchedAt time.Time) error {
+	return saveStreams(s.db, streams, fetchedAt)
+}
+
+// execer runs statements on the database or within a transaction
+type execer interface {
+	Exec(query string, args ...interface{}) (sql.Result, error)
+}
+
+// saveStreams implements SaveStreams on db
+func saveStreams(db execer, streams models.Streams, fetchedAt time.Time) error {
+	data, err := encodeStreams(streams)
+	if err != nil {
+		return fmt.Errorf("encode streams of activity %d: %w", streams.ActivityID, err)
+	}
+	_, err = db.Exec(`INSERT INTO activity_streams (activity_id, data, fetched_at) VALUES (?, ?, ?)
+		ON CONFLICT(activity_id) DO UPDATE SET data = excluded.data, fetched_at = excluded.fetched_at,
+			efforts = NULL`,
+		streams.ActivityID, data, formatTime(fetchedAt))
+	if err != nil {
+		return fmt.Errorf("save streams of activity %d: %w", streams.ActivityID, err)
+	}
+	return nil
+}
+
+// Streams returns the stored streams of an activity, or nil if they have not
+// been fetched
+func (s *Store) Streams(activityID int64) (*models.Streams, error) {
+	var data []byte
+	err := s.db.QueryRow("SELECT data FROM activity_streams WHERE activity_id = ?", activityID).Scan(&data)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("read streams of activity %d: %w", activityID, err)
+	}
+
+	streams, err := decodeStreams(data)
+	if err != nil {
+		return nil, fmt.Errorf("decode streams of activity %d: %w", activityID, err)
+	}
+	streams.ActivityID = activityID
+	return streams, nil
+}
+
+// StreamEfforts returns the efforts measured from stored streams by activity
+// ID. Streams not measured since they were saved are left out.
+func (s *Store) StreamEfforts() (map[int64][]models.BestEffort, error) {
+	rows, err := s.db.Query("SELECT activity_id, efforts FROM activity_streams WHERE efforts IS NOT NULL")
+	if err != nil {
+		return nil, fmt.Errorf("query stream efforts: %w", err)
+	}
+	defer rows.Close()
+
+	measured := map[int64][]models.BestEffort{}
+	for rows.Next() {
+		var (
+			id  int64
+			raw string
+		)
+		if err := rows.Scan(&id, &raw); err != nil {
+			return nil, fmt.Errorf("scan stream efforts: %w", err)
+		}
+		var efforts []models.BestEffort
+		if err := json.Unmarshal([]byte(raw), &efforts); err != nil {
+			return nil, fmt.Errorf("decode stream efforts of activity %d: %w", id, err)
+		}
+		measured[id] = efforts
+	}
+	return measured, rows.Err()
+}
+
+// SaveStreamEfforts stores the efforts measured from an activity's streams,
+// none included, so they are not measured again until the streams change
+func (s *Store) SaveStreamEfforts(activityID int64, efforts []models.BestEffort) error {
+	if efforts == nil {
+		efforts = []models.BestEffort{}
+	}
+	raw, err := json.Marshal(efforts)
+	if err != nil {
+		return fmt.Errorf("encode stream efforts of activity %d: %w", activityID, err)
+	}
+	if _, err := s.db.Exec("UPDATE activity_streams SET efforts = ? WHERE activity_id = ?", string(raw), activityID); err != nil {
+		return fmt.Errorf("save stream efforts of activity %d: %w", activityID, err)
+	}
+	return nil
+}
+
+// encodeStreams gzips the JSON form of the streams; a few hours of one-second
+// samples shrink to a small fraction of their raw size
+func encodeStreams(streams models.Streams) ([]byte, error) {
+	var buf bytes.Buffer
+	zw := gzip.NewWriter(&buf)
+	if err := json.NewEncoder(zw).Encode(streams); err != nil {
+		return nil, err
+	}
+	if err := zw.Close(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+func decodeStreams(data []byte) (*models.Streams, error) {
+	zr, err := gzip.NewReader(bytes.NewReader(data))
+	if err != nil {
+		return nil, err
+	}
+	defer zr.Close()
+
+	raw, err := io.ReadAll(zr)
+	if err != nil {
+		return nil, err
+	}
+	var streams models.Streams
+	if err := json.Unmarshal(raw, &streams); err != nil {
+		return nil, err
+	}
+	return &streams, nil
+}
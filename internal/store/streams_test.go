@@ -0,0 +1,55 @@
+package store
+
+import (
+	"testing"
+	"time"
+
+	"strava-custom-goals/internal/models"
+)
+
+func TestStreamsRoundTripAndDeleteWithActivity(t *testing.T) {
+	s := openTestStore(t)
+	if _, _, err := s.UpsertActivities([]models.Activity{
+		{ID: 1, Name: "Recorded", Type: "Run", StartDate: "2025-10-01T07:00:00Z"},
+		{ID: 2, Name: "Manual", Type: "Workout", StartDate: "2025-10-02T07:00:00Z"},
+	}); err != nil {
+		t.Fatalf("UpsertActivities failed: %v", err)
+	}
+	fetchedAt := time.Date(2025, 10, 3, 9, 0, 0, 0, time.UTC)
+	recorded := models.Streams{
+		ActivityID: 1,
+		Time:       []int{0, 1, 2},
+		Distance:   []float64{0, 3.1, 6.4},
+		Heartrate:  []int{120, 125, 131},
+		LatLng:     [][2]float64{{52.1, 4.3}, {52.1, 4.31}, {52.1, 4.32}},
+	}
+	if err := s.SaveStreams(recorded, fetchedAt); err != nil {
+		t.Fatalf("SaveStreams failed: %v", err)
+	}
+	if err := s.SaveStreams(models.Streams{ActivityID: 2}, fetchedAt); err != nil {
+		t.Fatalf("SaveStreams failed: %v", err)
+	}
+
+	got, err := s.Streams(1)
+	if err != nil || got == nil {
+		t.Fatalf("Expected stored streams, got %v, %v", got, err)
+	}
+	if got.ActivityID != 1 || got.Len() != 3 || got.Heartrate[2] != 131 || got.LatLng[1][1] != 4.31 {
+		t.Errorf("Expected streams to round-trip, got %+v", got)
+	}
+	if len(got.Watts) != 0 {
+		t.Errorf("Expected no watts stream, got %v", got.Watts)
+	}
+
+	// Empty streams count as fetched
+	if ids, _ := s.ActivitiesWithoutStreams(10); len(ids) != 0 {
+		t.Errorf("Expected no activities without streams, got %v", ids)
+	}
+
+	if _, err := s.DeleteActivitiesAfter(time.Date(2025, 9, 30, 0, 0, 0, 0, time.UTC), map[int64]bool{2: true}); err != nil {
+		t.Fatalf("DeleteActivitiesAfter failed: %v", err)
+	}
+	if got, err := s.Streams(1); err != nil || got != nil {
+		t.Errorf("Expected streams to be deleted with the activity, got %v, %v", got, err)
+	}
+}
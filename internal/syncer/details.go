@@ -0,0 +1,56 @@
+package syncer
+
+import (
+	"fmt"
+	"time"
+
+	"strava-custom-goals/internal/models"
+)
+
+// DetailFetcher fetches a single activity with its laps, splits and best efforts
+type DetailFetcher interface {
+	FetchActivityDetails(id int64) (*models.Activity, error)
+}
+
+// DetailStore keeps activity details next to the synchronised activities
+type DetailStore interface {
+	ActivitiesWithoutDetails(limit int) ([]int64, error)
+	SaveActivityDetails(activity models.Activity, fetchedAt time.Time) error
+}
+
+// FetchDetails fetches and stores the details of up to limit activities that
+// lack them, newest first. Each activity costs one API request, so details
+// are filled in a few at a time across syncs. An activity that fails is
+// skipped, so it cannot hold up older ones, and retried on the next run; the
+// count fetched is then returned with an error naming how many failed.
+func FetchDetails(store DetailStore, fetcher DetailFetcher, limit int, now time.Time) (int, error) {
+	if limit <= 0 {
+		return 0, nil
+	}
+	ids, err := store.ActivitiesWithoutDetails(limit)
+	if err != nil {
+		return 0, err
+	}
+
+	fetched, failed := 0, 0
+	var firstErr error
+	for _, id := range ids {
+		activity, err := fetcher.FetchActivityDetails(id)
+		if err != nil {
+			if failed++; firstErr == nil {
+				firstErr = fmt.Errorf("fetch activity %d: %w", id, err)
+			}
+			continue
+		}
+		// The stored row is keyed by the requested ID, whatever the response says
+		activity.ID = id
+		if err := store.SaveActivityDetails(*activity, now); err != nil {
+			return fetched, err
+		}
+		fetched++
+	}
+	if failed > 0 {
+		return fetched, fmt.Errorf("skipped %d of %d activities, first: %w", failed, len(ids), firstErr)
+	}
+	return fetched, nil
+}
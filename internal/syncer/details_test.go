@@ -0,0 +1,96 @@
+package syncer
+
+import (
+	"errors"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"strava-custom-goals/internal/models"
+	"strava-custom-goals/internal/store"
+)
+
+// fakeDetailFetcher returns a split per activity and fails for IDs in fail
+type fakeDetailFetcher struct {
+	requested []int64
+	fail      map[int64]bool
+}
+
+func (f *fakeDetailFetcher) FetchActivityDetails(id int64) (*models.Activity, error) {
+	f.requested = append(f.requested, id)
+	if f.fail[id] {
+		return nil, errors.New("quota exhausted")
+	}
+	return &models.Activity{ID: id, Splits: []models.Split{{Split: 1, Distance: 1000, MovingTime: 300}}}, nil
+}
+
+func TestFetchDetailsFillsNewestFirstWithinLimit(t *testing.T) {
+	db, err := store.Open(filepath.Join(t.TempDir(), "activities.db"))
+	if err != nil {
+		t.Fatalf("Open store: %v", err)
+	}
+	defer db.Close()
+
+	now := time.Date(2025, 10, 10, 12, 0, 0, 0, time.UTC)
+	db.UpsertActivities([]models.Activity{
+		activityAt(1, "Oldest", now.AddDate(0, 0, -3)),
+		activityAt(2, "Middle", now.AddDate(0, 0, -2)),
+		activityAt(3, "Newest", now.AddDate(0, 0, -1)),
+	})
+
+	fetcher := &fakeDetailFetcher{fail: map[int64]bool{1: true}}
+	fetched, err := FetchDetails(db, fetcher, 2, now)
+	if err != nil || fetched != 2 {
+		t.Fatalf("Expected 2 fetched without error, got %d, %v", fetched, err)
+	}
+	if len(fetcher.requested) != 2 || fetcher.requested[0] != 3 || fetcher.requested[1] != 2 {
+		t.Errorf("Expected activities 3 then 2 to be requested, got %v", fetcher.requested)
+	}
+
+	// The next run picks up where the last stopped, keeping progress on error
+	fetched, err = FetchDetails(db, fetcher, 2, now)
+	if err == nil || fetched != 0 {
+		t.Errorf("Expected the failing fetch to be reported, got %d, %v", fetched, err)
+	}
+
+	stored, _ := db.ActivitiesSince(time.Time{})
+	for _, activity := range stored {
+		if activity.HasDetails() != (activity.ID != 1) {
+			t.Errorf("Activity %d: unexpected details state %v", activity.ID, activity.HasDetails())
+		}
+	}
+}
+
+func TestFetchDetailsSkipsFailingActivities(t *testing.T) {
+	db, err := store.Open(filepath.Join(t.TempDir(), "activities.db"))
+	if err != nil {
+		t.Fatalf("Open store: %v", err)
+	}
+	defer db.Close()
+
+	now := time.Date(2025, 10, 10, 12, 0, 0, 0, time.UTC)
+	db.UpsertActivities([]models.Activity{
+		activityAt(1, "Oldest", now.AddDate(0, 0, -3)),
+		activityAt(2, "Middle", now.AddDate(0, 0, -2)),
+		activityAt(3, "Newest", now.AddDate(0, 0, -1)),
+	})
+
+	// The newest activity failing must not hold up the older ones
+	fetcher := &fakeDetailFetcher{fail: map[int64]bool{3: true}}
+	fetched, err := FetchDetails(db, fetcher, 3, now)
+	if err == nil || !strings.Contains(err.Error(), "skipped 1 of 3") || fetched != 2 {
+		t.Errorf("Expected 2 fetched and 1 skipped, got %d, %v", fetched, err)
+	}
+	if missing, _ := db.ActivitiesWithoutDetails(10); len(missing) != 1 || missing[0] != 3 {
+		t.Errorf("Expected only activity 3 to be retried, got %v", missing)
+	}
+
+	// An edit to a detailed activity has its details fetched again
+	edited := activityAt(1, "Oldest (cropped)", now.AddDate(0, 0, -3))
+	edited.Distance = 4200
+	db.UpsertActivities([]models.Activity{edited})
+	if missing, _ := db.ActivitiesWithoutDetails(10); len(missing) != 2 || missing[1] != 1 {
+		t.Errorf("Expected the edited activity to lack details again, got %v", missing)
+	}
+}
@@ -0,0 +1,52 @@
+package syncer
+
+import (
+	"fmt"
+	"time"
+
+	"strava-custom-goals/internal/models"
+)
+
+// StreamFetcher fetches the sampled data of a single activity
+type StreamFetcher interface {
+	FetchActivityStreams(id int64) (*models.Streams, error)
+}
+
+// StreamStore keeps activity streams next to the synchronised activities
+type StreamStore interface {
+	ActivitiesWithoutStreams(limit int) ([]int64, error)
+	SaveStreams(streams models.Streams, fetchedAt time.Time) error
+}
+
+// FetchStreams fetches and stores the streams of up to limit activities that
+// lack them, newest first, the same way FetchDetails fills in details
+func FetchStreams(store StreamStore, fetcher StreamFetcher, limit int, now time.Time) (int, error) {
+	if limit <= 0 {
+		return 0, nil
+	}
+	ids, err := store.ActivitiesWithoutStreams(limit)
+	if err != nil {
+		return 0, err
+	}
+
+	fetched, failed := 0, 0
+	var firstErr error
+	for _, id := range ids {
+		streams, err := fetcher.FetchActivityStreams(id)
+		if err != nil {
+			if failed++; firstErr == nil {
+				firstErr = fmt.Errorf("fetch streams of activity %d: %w", id, err)
+			}
+			continue
+		}
+		streams.ActivityID = id
+		if err := store.SaveStreams(*streams, now); err != nil {
+			return fetched, err
+		}
+		fetched++
+	}
+	if failed > 0 {
+		return fetched, fmt.Errorf("skipped %d of %d activities, first: %w", failed, len(ids), firstErr)
+	}
+	return fetched, nil
+}
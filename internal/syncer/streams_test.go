@@ -0,0 +1,64 @@
+package syncer
+
+import (
+	"errors"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"strava-custom-goals/internal/models"
+	"strava-custom-goals/internal/store"
+)
+
+// fakeStreamFetcher returns a short heart rate stream and fails for IDs in fail
+type fakeStreamFetcher struct {
+	requested []int64
+	fail      map[int64]bool
+}
+
+func (f *fakeStreamFetcher) FetchActivityStreams(id int64) (*models.Streams, error) {
+	f.requested = append(f.requested, id)
+	if f.fail[id] {
+		return nil, errors.New("quota exhausted")
+	}
+	return &models.Streams{Time: []int{0, 1}, Heartrate: []int{130, 132}}, nil
+}
+
+func TestFetchStreamsFillsNewestFirstWithinLimit(t *testing.T) {
+	db, err := store.Open(filepath.Join(t.TempDir(), "activities.db"))
+	if err != nil {
+		t.Fatalf("Open store: %v", err)
+	}
+	defer db.Close()
+
+	now := time.Date(2025, 10, 10, 12, 0, 0, 0, time.UTC)
+	db.UpsertActivities([]models.Activity{
+		activityAt(1, "Oldest", now.AddDate(0, 0, -3)),
+		activityAt(2, "Middle", now.AddDate(0, 0, -2)),
+		activityAt(3, "Newest", now.AddDate(0, 0, -1)),
+	})
+
+	fetcher := &fakeStreamFetcher{fail: map[int64]bool{1: true}}
+	fetched, err := FetchStreams(db, fetcher, 2, now)
+	if err != nil || fetched != 2 {
+		t.Fatalf("Expected 2 fetched without error, got %d, %v", fetched, err)
+	}
+	if len(fetcher.requested) != 2 || fetcher.requested[0] != 3 || fetcher.requested[1] != 2 {
+		t.Errorf("Expected activities 3 then 2 to be requested, got %v", fetcher.requested)
+	}
+
+	fetched, err = FetchStreams(db, fetcher, 2, now)
+	if err == nil || fetched != 0 {
+		t.Errorf("Expected the failing fetch to be reported, got %d, %v", fetched, err)
+	}
+
+	for id, want := range map[int64]bool{1: false, 2: true, 3: true} {
+		streams, err := db.Streams(id)
+		if err != nil || (streams != nil) != want {
+			t.Errorf("Activity %d: expected streams stored %v, got %v, %v", id, want, streams, err)
+		}
+		if streams != nil && streams.ActivityID != id {
+			t.Errorf("Activity %d: streams stored under %d", id, streams.ActivityID)
+		}
+	}
+}
@@ -0,0 +1,122 @@
+// Package syncer keeps the local activity store up to date with Strava using
+// incremental fetches above a watermark plus periodic re-checks of recent history.
+package syncer
+
+import (
+	"fmt"
+	"time"
+
+	"strava-custom-goals/internal/models"
+)
+
+// Fetcher lists activities that started strictly between after and before.
+// Zero times leave that side of the window open.
+type Fetcher interface {
+	FetchActivities(after, before time.Time) ([]models.Activity, error)
+}
+
+// Store is the local activity storage the engine synchronises into
+type Store interface {
+	SyncState() (models.SyncState, error)
+	SaveSyncState(state models.SyncState) error
+	UpsertActivities(activities []models.Activity) (added, updated int, err error)
+	DeleteActivitiesAfter(t time.Time, keep map[int64]bool) (int, error)
+}
+
+// Engine performs incremental syncs
+type Engine struct {
+	Store   Store
+	Fetcher Fetcher
+
+	// RecheckWindow is how far back from now (or the watermark, if older)
+	// activities are re-fetched to pick up edits and deletions
+	RecheckWindow time.Duration
+	// RecheckInterval is how often the trailing window is re-fetched
+	RecheckInterval time.Duration
+
+	now func() time.Time
+}
+
+// Result summarises what a sync changed
+type Result struct {
+	Added     int
+	Updated   int
+	Deleted   int
+	Fetched   int
+	Full      bool // first sync, whole history fetched
+	Rechecked bool // trailing window was re-fetched
+	State     models.SyncState
+}
+
+// NewEngine creates a sync engine with the given re-check policy
+func NewEngine(store Store, fetcher Fetcher, recheckWindow, recheckInterval time.Duration) *Engine {
+	return &Engine{
+		Store:           store,
+		Fetcher:         fetcher,
+		RecheckWindow:   recheckWindow,
+		RecheckInterval: recheckInterval,
+		now:             time.Now,
+	}
+}
+
+// Sync fetches new activities above the stored watermark and, when due,
+// re-fetches the trailing window to reconcile edits and deletions. A full
+// sync reconciles the whole history.
+func (e *Engine) Sync() (*Result, error) {
+	state, err := e.Store.SyncState()
+	if err != nil {
+		return nil, fmt.Errorf("load sync state: %w", err)
+	}
+
+	now := e.now()
+	result := &Result{Full: state.Watermark.IsZero()}
+
+	after := state.Watermark
+	if !result.Full && now.Sub(state.LastRecheck) >= e.RecheckInterval {
+		result.Rechecked = true
+		if windowStart := now.Add(-e.RecheckWindow); windowStart.Before(after) {
+			after = windowStart
+		}
+	}
+
+	activities, err := e.Fetcher.FetchActivities(after, time.Time{})
+	if err != nil {
+		return nil, fmt.Errorf("fetch activities: %w", err)
+	}
+	result.Fetched = len(activities)
+
+	result.Added, result.Updated, err = e.Store.UpsertActivities(activities)
+	if err != nil {
+		return nil, fmt.Errorf("store activities: %w", err)
+	}
+
+	// A full listing reconciles deletions across the whole history
+	if result.Full || result.Rechecked {
+		keep := make(map[int64]bool, len(activities))
+		for _, activity := range activities {
+			keep[activity.ID] = true
+		}
+		result.Deleted, err = e.Store.DeleteActivitiesAfter(after, keep)
+		if err != nil {
+			return nil, fmt.Errorf("delete removed activities: %w", err)
+		}
+	}
+
+	// A full sync also counts as a re-check of everything
+	if result.Full || result.Rechecked {
+		state.LastRecheck = now
+	}
+	for _, activity := range activities {
+		if start, err := activity.StartTime(); err == nil && start.After(state.Watermark) {
+			state.Watermark = start
+		}
+	}
+	state.LastSync = now
+
+	if err := e.Store.SaveSyncState(state); err != nil {
+		return nil, fmt.Errorf("save sync state: %w", err)
+	}
+	result.State = state
+
+	return result, nil
+}
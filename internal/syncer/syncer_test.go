@@ -0,0 +1,143 @@
+package syncer
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+
+	"strava-custom-goals/internal/models"
+	"strava-custom-goals/internal/store"
+)
+
+// fakeFetcher serves a mutable list of "remote" activities and records queries
+type fakeFetcher struct {
+	remote []models.Activity
+	afters []time.Time
+}
+
+func (f *fakeFetcher) FetchActivities(after, before time.Time) ([]models.Activity, error) {
+	f.afters = append(f.afters, after)
+	var result []models.Activity
+	for _, activity := range f.remote {
+		start, _ := activity.StartTime()
+		if !after.IsZero() && !start.After(after) {
+			continue
+		}
+		result = append(result, activity)
+	}
+	return result, nil
+}
+
+func activityAt(id int64, name string, start time.Time) models.Activity {
+	return models.Activity{ID: id, Name: name, Type: "Run", StartDate: start.UTC().Format(time.RFC3339)}
+}
+
+func TestSyncIsIncrementalAndReconcilesTrailingWindow(t *testing.T) {
+	now := time.Date(2025, 10, 10, 12, 0, 0, 0, time.UTC)
+	fetcher := &fakeFetcher{remote: []models.Activity{
+		activityAt(1, "Old run", now.AddDate(0, 0, -40)),
+		activityAt(2, "Tempo", now.AddDate(0, 0, -3)),
+		activityAt(3, "Easy", now.AddDate(0, 0, -1)),
+	}}
+	db, err := store.Open(filepath.Join(t.TempDir(), "activities.db"))
+	if err != nil {
+		t.Fatalf("Open store: %v", err)
+	}
+	defer db.Close()
+
+	engine := NewEngine(db, fetcher, 14*24*time.Hour, 24*time.Hour)
+	engine.now = func() time.Time { return now }
+
+	// First sync pulls the whole history
+	result, err := engine.Sync()
+	if err != nil {
+		t.Fatalf("Initial sync failed: %v", err)
+	}
+	if !result.Full || result.Added != 3 {
+		t.Errorf("Expected full sync adding 3, got %+v", result)
+	}
+	if !fetcher.afters[0].IsZero() {
+		t.Errorf("Expected unbounded first fetch, got after=%v", fetcher.afters[0])
+	}
+	if !result.State.Watermark.Equal(now.AddDate(0, 0, -1)) {
+		t.Errorf("Expected watermark at newest activity, got %v", result.State.Watermark)
+	}
+
+	// A later sync within the re-check interval only asks for newer activities
+	now = now.Add(2 * time.Hour)
+	fetcher.remote = append(fetcher.remote, activityAt(4, "Lunch run", now.Add(-time.Hour)))
+	result, err = engine.Sync()
+	if err != nil {
+		t.Fatalf("Incremental sync failed: %v", err)
+	}
+	if result.Rechecked || result.Added != 1 || result.Fetched != 1 {
+		t.Errorf("Expected incremental sync adding 1, got %+v", result)
+	}
+	if want := time.Date(2025, 10, 9, 12, 0, 0, 0, time.UTC); !fetcher.afters[1].Equal(want) {
+		t.Errorf("Expected fetch after watermark %v, got %v", want, fetcher.afters[1])
+	}
+
+	// Once the interval has passed the trailing window is re-fetched: the
+	// tempo run was renamed and the easy run deleted on Strava
+	now = now.Add(24 * time.Hour)
+	fetcher.remote[1].Name = "Tempo (edited)"
+	fetcher.remote = append(fetcher.remote[:2], fetcher.remote[3:]...)
+	result, err = engine.Sync()
+	if err != nil {
+		t.Fatalf("Re-check sync failed: %v", err)
+	}
+	if !result.Rechecked || result.Deleted != 1 || result.Updated != 2 {
+		t.Errorf("Expected re-check updating 2 and deleting 1, got %+v", result)
+	}
+
+	stored, err := db.ActivitiesSince(time.Time{})
+	if err != nil {
+		t.Fatalf("ActivitiesSince failed: %v", err)
+	}
+	if len(stored) != 3 {
+		t.Fatalf("Expected 3 stored activities, got %d", len(stored))
+	}
+	for _, activity := range stored {
+		if activity.ID == 3 {
+			t.Error("Deleted activity is still stored")
+		}
+		if activity.ID == 2 && activity.Name != "Tempo (edited)" {
+			t.Errorf("Expected edited name, got %q", activity.Name)
+		}
+	}
+	if stored[0].ID != 4 {
+		t.Errorf("Expected newest activity first, got ID %d", stored[0].ID)
+	}
+}
+
+func TestFullSyncDeletesActivitiesRemovedOnStrava(t *testing.T) {
+	now := time.Date(2025, 10, 10, 12, 0, 0, 0, time.UTC)
+	db, err := store.Open(filepath.Join(t.TempDir(), "activities.db"))
+	if err != nil {
+		t.Fatalf("Open store: %v", err)
+	}
+	defer db.Close()
+
+	// A year-old run deleted on Strava since it was stored, and an import
+	db.UpsertActivities([]models.Activity{
+		activityAt(1, "Deleted run", now.AddDate(-1, 0, 0)),
+		activityAt(2, "Tempo", now.AddDate(0, 0, -3)),
+		{ID: -100, Name: "Imported", Type: "Run", Source: "gpx", StartDate: now.AddDate(0, -6, 0).Format(time.RFC3339)},
+	})
+
+	fetcher := &fakeFetcher{remote: []models.Activity{activityAt(2, "Tempo", now.AddDate(0, 0, -3))}}
+	engine := NewEngine(db, fetcher, 14*24*time.Hour, 24*time.Hour)
+	engine.now = func() time.Time { return now }
+
+	result, err := engine.Sync()
+	if err != nil {
+		t.Fatalf("Full sync failed: %v", err)
+	}
+	if !result.Full || result.Deleted != 1 {
+		t.Errorf("Expected a full sync deleting 1, got %+v", result)
+	}
+	stored, _ := db.ActivitiesSince(time.Time{})
+	if len(stored) != 2 || stored[0].ID != 2 || stored[1].ID != -100 {
+		t.Errorf("Expected the tempo run and the import to remain, got %+v", stored)
+	}
+}
@@ -10,62 +10,131 @@
 // Setup:
 // 1. Create a Strava API application at https://www.strava.com/settings/api
 // 2. Copy .env.example to .env and fill in your credentials
-// 3. Run: go run main.go
+// 3. Run: go run . auth login (once), then: go run .
 package main
 
 import (
 	"flag"
+	"fmt"
 	"log"
+	"os"
+	"strings"
+	"time"
 
 	"strava-custom-goals/config"
-	"strava-custom-goals/internal/client"
 	"strava-custom-goals/internal/display"
 	"strava-custom-goals/internal/goals"
+	"strava-custom-goals/internal/report"
+	"strava-custom-goals/internal/store"
 )
 
 func main() {
+	profile, args := extractProfileFlag(os.Args[1:])
+
+	if len(args) > 0 {
+		switch args[0] {
+		case "auth":
+			runAuth(args[1:], profile)
+			return
+		case "sync":
+			runSync(args[1:], profile)
+			return
+		case "profiles":
+			runProfiles(args[1:], profile)
+			return
+		case "export":
+			runExport(args[1:], profile)
+			return
+		case "serve":
+			runServe(args[1:], profile)
+			return
+		case "records":
+			runRecords(args[1:], profile)
+			return
+		case "import":
+			runImport(args[1:], profile)
+			return
+		}
+	}
+
+	runReport(args, profile)
+}
+
+// runReport syncs activities and prints goal progress, the default command
+func runReport(args []string, profile string) {
 	// Parse command line flags
 	var (
 		showHelp    = flag.Bool("help", false, "Show help message")
-		maxResults  = flag.Int("max", 30, "Maximum number of activities to fetch")
+		maxResults  = flag.Int("max", 30, "Maximum number of activities to display")
+		days        = flag.Int("days", 28, "Number of days of history to analyze")
+		offline     = flag.Bool("offline", false, "Skip syncing and use only locally stored activities")
+		asOf        = flag.String("as-of", "", "Evaluate goals as of a past date (YYYY-MM-DD, end of day) or RFC 3339 time")
 		showSummary = flag.Bool("summary", true, "Show activity summary")
 		showDetails = flag.Bool("details", true, "Show detailed activities")
+		output      = flag.String("output", "text", "Output format: "+strings.Join(display.Formats, ", "))
 	)
-	flag.Parse()
+	flag.CommandLine.Parse(args)
+
+	renderer, err := display.NewRenderer(*output, display.Options{Summary: *showSummary})
+	if err != nil {
+		log.Fatalf("❌ Invalid --output: %v", err)
+	}
 
 	if *showHelp {
 		flag.Usage()
+		fmt.Fprintln(flag.CommandLine.Output(), "\nCommands:\n"+
+			"  auth login|status|logout   Connect your Strava account\n"+
+			"  sync [--full]              Incrementally sync activities into the local store\n"+
+			"  profiles list|use|create   Manage athlete profiles\n"+
+			"  export [activities|goals]  Export activities and goal history as CSV or Markdown\n"+
+			"  serve                      Run a local web dashboard and JSON API\n"+
+			"  records                    List personal records from the stored history\n"+
+			"  import FILE|DIR|ZIP...     Import GPX, TCX and FIT files or a Strava bulk export\n"+
+			"\nGlobal flags:\n"+
+			"  --profile NAME             Use a named athlete profile")
 		return
 	}
 
 	log.Println("🚀 Strava Custom Goals Tracker Starting...")
+	if profile != config.DefaultProfile {
+		log.Printf("👤 Profile: %s", profile)
+	}
 
 	// Load configuration from environment variables
-	cfg := config.LoadConfig()
-
-	// Initialize Strava client
-	stravaClient := client.NewStravaClient(cfg.ClientID, cfg.ClientSecret, cfg.RefreshToken)
-
-	// Get access token
-	log.Println("📡 Authenticating with Strava API...")
-	accessToken, err := stravaClient.GetAccessToken()
-	if err != nil {
-		log.Fatalf("❌ Authentication failed: %v", err)
+	cfg := config.LoadConfig(profile)
+
+	clock := goals.Clock(goals.SystemClock{})
+	if *asOf != "" {
+		at, err := parseAsOf(*asOf, cfg.Location)
+		if err != nil {
+			log.Fatalf("❌ Invalid --as-of: %v", err)
+		}
+		clock = goals.FixedClock(at)
+		log.Printf("⏪ Replaying progress as of %s", at.Format("Jan 2, 2006 15:04 MST"))
+	}
+	now := clock.Now()
+
+	db := openStore(cfg)
+	defer db.Close()
+
+	// Bring the local store up to date; fall back to stored data when offline
+	if !*offline {
+		result, stravaClient, err := syncActivities(cfg, db, config.DetailsPerSync, config.StreamsPerSync)
+		if err != nil {
+			log.Printf("⚠️ Sync failed, using stored activities: %v", err)
+		} else {
+			logSyncResult(result, stravaClient)
+		}
 	}
-	log.Println("✅ Successfully authenticated")
 
-	// Fetch recent activities
-	log.Println("📊 Fetching recent activities...")
-	activities, err := stravaClient.GetActivities(accessToken)
+	activities, err := db.Activities(store.Query{After: now.AddDate(0, 0, -*days), Before: now})
 	if err != nil {
-		log.Fatalf("❌ Failed to fetch activities: %v", err)
+		log.Fatalf("❌ Failed to read local activities: %v", err)
 	}
-	log.Printf("✅ Retrieved %d activities", len(activities))
+	log.Printf("✅ Loaded %d activities from the last %d days", len(activities), *days)
 
-	// Process and display activities
 	if len(activities) == 0 {
 		log.Println("ℹ️ No activities found")
-		return
 	}
 
 	// Enhance activities with calculated fields
@@ -73,34 +142,65 @@ func main() {
 		activities[i].EnhanceWithCalculatedFields()
 	}
 
-	// Calculate weekly goals progress
-	log.Println("🎯 Calculating weekly goals progress...")
-	weeklyGoals := goals.WeeklyGoals{
-		RunningGoalKm:    cfg.WeeklyRunningGoalKm,
-		WorkoutGoalHours: cfg.WeeklyWorkoutGoalHours,
+	// Calculate goals progress
+	log.Printf("🎯 Calculating progress for %d goals...", len(cfg.Goals))
+	engine := goals.NewEngine(cfg.Goals)
+	engine.Calendar = goals.Calendar{Location: cfg.Location, WeekStart: cfg.WeekStart}
+	engine.HeartRate = cfg.HeartRate
+	engine.Zones = cfg.Zones
+	engine.Streams = db
+	engine.Clock = clock
+
+	// Monthly, yearly and custom periods can reach further back than --days
+	results, err := engine.EvaluateFrom(db)
+	if err != nil {
+		log.Fatalf("❌ Failed to evaluate goals: %v", err)
 	}
-	weeklyProgress := goals.CalculateWeeklyProgress(activities, weeklyGoals)
 
-	// Display weekly goals progress
-	display.DisplayWeeklyGoalsProgress(weeklyProgress)
+	projections, err := engine.ProjectionsFrom(db, results)
+	if err != nil {
+		log.Fatalf("❌ Failed to project goals: %v", err)
+	}
 
-	// Display detailed activities (if requested)
-	if *showDetails {
-		display.DisplayActivities(activities[:min(len(activities), *maxResults)])
+	// Streaks look at the whole stored history
+	streaks, err := engine.StreaksFrom(db)
+	if err != nil {
+		log.Fatalf("❌ Failed to compute streaks: %v", err)
 	}
 
-	// Display summary (if requested)
-	if *showSummary {
-		display.DisplaySummary(activities)
+	load, err := engine.TrainingLoadFrom(db)
+	if err != nil {
+		log.Fatalf("❌ Failed to compute training load: %v", err)
+	}
+
+	rep := report.Build(results, activities, report.Options{
+		Profile:        profile,
+		AsOf:           now,
+		MaxActivities:  *maxResults,
+		OmitActivities: !*showDetails,
+		Streaks:        streaks,
+		Projections:    projections,
+		TrainingLoad:   load,
+	})
+	if err := renderer.Render(os.Stdout, rep); err != nil {
+		log.Fatalf("❌ Failed to write report: %v", err)
 	}
 
 	log.Printf("🎯 Analysis complete: processed %d activities", len(activities))
 }
 
-// min returns the minimum of two integers
-func min(a, b int) int {
-	if a < b {
-		return a
+// parseAsOf parses an --as-of value. A bare date means the end of that day in
+// the athlete's timezone (or the machine's, when none is configured).
+func parseAsOf(value string, location *time.Location) (time.Time, error) {
+	if t, err := time.Parse(time.RFC3339, value); err == nil {
+		return t, nil
+	}
+	if location == nil {
+		location = time.Local
+	}
+	day, err := time.ParseInLocation("2006-01-02", value, location)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("%q is neither YYYY-MM-DD nor an RFC 3339 time", value)
 	}
-	return b
+	return day.AddDate(0, 0, 1).Add(-time.Second), nil
 }
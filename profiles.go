@@ -0,0 +1,82 @@
+package main
+
+import (
+	"fmt"
+	"log"
+	"os"
+	"strings"
+
+	"strava-custom-goals/config"
+)
+
+// extractProfileFlag removes a global --profile NAME (or --profile=NAME) from
+// args, wherever it appears, and returns the selected profile
+func extractProfileFlag(args []string) (string, []string) {
+	profile := config.CurrentProfile()
+	rest := make([]string, 0, len(args))
+
+	for i := 0; i < len(args); i++ {
+		arg := args[i]
+		switch {
+		case arg == "--profile" || arg == "-profile":
+			if i+1 >= len(args) {
+				log.Fatal("❌ --profile requires a profile name")
+			}
+			profile = args[i+1]
+			i++
+		case strings.HasPrefix(arg, "--profile="), strings.HasPrefix(arg, "-profile="):
+			profile = arg[strings.Index(arg, "=")+1:]
+		default:
+			rest = append(rest, arg)
+		}
+	}
+	return profile, rest
+}
+
+// runProfiles implements the "profiles" command: list, use and create
+func runProfiles(args []string, active string) {
+	usage := "Usage: strava-custom-goals profiles <list|use NAME|create NAME>"
+	if len(args) == 0 {
+		fmt.Fprintln(os.Stderr, usage)
+		os.Exit(2)
+	}
+
+	switch args[0] {
+	case "list":
+		profiles, err := config.ListProfiles()
+		if err != nil {
+			log.Fatalf("❌ Failed to list profiles: %v", err)
+		}
+		fmt.Println("👥 Profiles:")
+		for _, profile := range profiles {
+			marker := "  "
+			if profile == active {
+				marker = "▶ "
+			}
+			fmt.Printf("   %s%s  (%s)\n", marker, profile, config.ProfileDir(profile))
+		}
+	case "use":
+		if len(args) != 2 {
+			fmt.Fprintln(os.Stderr, usage)
+			os.Exit(2)
+		}
+		if err := config.SetCurrentProfile(args[1]); err != nil {
+			log.Fatalf("❌ %v", err)
+		}
+		log.Printf("✅ Switched to profile %q", args[1])
+	case "create":
+		if len(args) != 2 {
+			fmt.Fprintln(os.Stderr, usage)
+			os.Exit(2)
+		}
+		envFile, err := config.CreateProfile(args[1])
+		if err != nil {
+			log.Fatalf("❌ %v", err)
+		}
+		log.Printf("✅ Created profile %q", args[1])
+		log.Printf("📝 Add its Strava credentials to %s, then run: auth login --profile %s", envFile, args[1])
+	default:
+		fmt.Fprintln(os.Stderr, usage)
+		os.Exit(2)
+	}
+}
@@ -0,0 +1,50 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"log"
+	"time"
+
+	"strava-custom-goals/config"
+	"strava-custom-goals/internal/models"
+	"strava-custom-goals/internal/records"
+)
+
+// runRecords implements the "records" command: list personal records from
+// the local store
+func runRecords(args []string, profile string) {
+	fs := flag.NewFlagSet("records", flag.ExitOnError)
+	fs.Parse(args)
+
+	cfg := config.LoadConfig(profile)
+	db := openStore(cfg)
+	defer db.Close()
+
+	// Recompute so records reflect activities stored by any means
+	updateRecords(db)
+	stored, err := db.PersonalRecords()
+	if err != nil {
+		log.Fatalf("❌ Failed to read personal records: %v", err)
+	}
+
+	byKind := make(map[string]models.PersonalRecord, len(stored))
+	for _, record := range stored {
+		byKind[record.Kind] = record
+	}
+
+	fmt.Println("\n🏆 === PERSONAL RECORDS ===")
+	for _, def := range records.Definitions {
+		record, ok := byKind[string(def.Kind)]
+		if !ok {
+			fmt.Printf("   %-22s —\n", def.Label)
+			continue
+		}
+		marker := ""
+		if time.Since(record.DetectedAt) < 7*24*time.Hour {
+			marker = " 🆕"
+		}
+		fmt.Printf("   %-22s %-10s %s, %s%s\n", def.Label, def.Format(record.Value),
+			record.ActivityName, record.SetAt.Local().Format("Jan 2, 2006"), marker)
+	}
+}
@@ -0,0 +1,140 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"strava-custom-goals/config"
+	"strava-custom-goals/internal/goals"
+	"strava-custom-goals/internal/report"
+	"strava-custom-goals/internal/server"
+	"strava-custom-goals/internal/store"
+)
+
+// runServe implements the "serve" command: a local web dashboard over the
+// activity store
+func runServe(args []string, profile string) {
+	fs := flag.NewFlagSet("serve", flag.ExitOnError)
+	addr := fs.String("addr", config.DashboardAddr, "Address to listen on")
+	refresh := fs.Duration("refresh", config.DashboardRefresh, "How often to reload data from the store (0 disables)")
+	syncEvery := fs.Duration("sync", 0, "Also sync with Strava this often, e.g. 1h (0 serves stored activities only)")
+	days := fs.Int("days", 28, "Number of days of activities to list")
+	weeks := fs.Int("weeks", 12, "Number of weeks of goal history to chart")
+	maxResults := fs.Int("max", 30, "Maximum number of activities to list")
+	fs.Parse(args)
+
+	if *syncEvery != 0 && *syncEvery < config.DashboardMinSync {
+		log.Fatalf("❌ --sync must be 0 or at least %s", config.DashboardMinSync)
+	}
+
+	cfg := config.LoadConfig(profile)
+	db := openStore(cfg)
+	defer db.Close()
+
+	engine := goals.NewEngine(cfg.Goals)
+	engine.Calendar = goals.Calendar{Location: cfg.Location, WeekStart: cfg.WeekStart}
+	engine.HeartRate = cfg.HeartRate
+	engine.Zones = cfg.Zones
+	engine.Streams = db
+
+	srv := server.New(func() (*server.Snapshot, error) {
+		return loadSnapshot(db, engine, profile, *days, *weeks, *maxResults)
+	}, *refresh)
+	if err := srv.Refresh(); err != nil {
+		log.Fatalf("❌ Failed to load dashboard data: %v", err)
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	if *syncEvery > 0 {
+		go syncPeriodically(ctx, cfg, db, srv, *syncEvery)
+		log.Printf("🔄 Syncing with Strava every %s", *syncEvery)
+	}
+
+	log.Printf("🌐 Dashboard running at http://%s (refresh every %s)", *addr, *refresh)
+	if err := srv.ListenAndServe(ctx, *addr); err != nil {
+		log.Fatalf("❌ Dashboard server failed: %v", err)
+	}
+	log.Println("👋 Dashboard stopped")
+}
+
+// syncPeriodically syncs the store every interval until ctx is done,
+// reloading the dashboard after each sync. One client is kept throughout so
+// its rate limiter sees every request.
+func syncPeriodically(ctx context.Context, cfg *config.Config, db *store.Store, srv *server.Server, interval time.Duration) {
+	stravaClient := newStravaClient(cfg)
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		result, err := syncWith(stravaClient, db, config.DetailsPerSync, config.StreamsPerSync)
+		if err != nil {
+			log.Printf("⚠️ Sync failed, serving stored activities: %v", err)
+		} else {
+			logSyncResult(result, stravaClient)
+			if err := srv.Refresh(); err != nil {
+				log.Printf("⚠️ Failed to reload dashboard data: %v", err)
+			}
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// loadSnapshot builds the dashboard data from the store as of now
+func loadSnapshot(db *store.Store, engine *goals.Engine, profile string, days, weeks, maxResults int) (*server.Snapshot, error) {
+	now := engine.Clock.Now()
+
+	activities, err := db.Activities(store.Query{After: now.AddDate(0, 0, -days), Before: now})
+	if err != nil {
+		return nil, fmt.Errorf("read activities: %w", err)
+	}
+
+	results, err := engine.EvaluateFrom(db)
+	if err != nil {
+		return nil, fmt.Errorf("evaluate goals: %w", err)
+	}
+
+	projections, err := engine.ProjectionsFrom(db, results)
+	if err != nil {
+		return nil, fmt.Errorf("project goals: %w", err)
+	}
+
+	streaks, err := engine.StreaksFrom(db)
+	if err != nil {
+		return nil, fmt.Errorf("compute streaks: %w", err)
+	}
+
+	load, err := engine.TrainingLoadFrom(db)
+	if err != nil {
+		return nil, fmt.Errorf("compute training load: %w", err)
+	}
+
+	// History covers whole periods from the one containing the first week
+	from := now.AddDate(0, 0, -7*(weeks-1))
+	past, err := db.ActivitiesSince(engine.Since(from))
+	if err != nil {
+		return nil, fmt.Errorf("read activity history: %w", err)
+	}
+	history := engine.History(past, from, now, now)
+
+	rep := report.Build(results, activities, report.Options{
+		Profile:       profile,
+		AsOf:          now,
+		MaxActivities: maxResults,
+		Streaks:       streaks,
+		Projections:   projections,
+		TrainingLoad:  load,
+	})
+	return &server.Snapshot{Report: rep, History: report.NewHistory(history), LoadedAt: time.Now()}, nil
+}
@@ -0,0 +1,180 @@
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"time"
+
+	"strava-custom-goals/config"
+	"strava-custom-goals/internal/auth"
+	"strava-custom-goals/internal/client"
+	"strava-custom-goals/internal/importer"
+	"strava-custom-goals/internal/models"
+	"strava-custom-goals/internal/records"
+	"strava-custom-goals/internal/store"
+	"strava-custom-goals/internal/syncer"
+)
+
+// clientFetcher adapts an authenticated StravaClient to syncer.Fetcher
+type clientFetcher struct {
+	client      *client.StravaClient
+	accessToken string
+}
+
+// FetchActivities implements syncer.Fetcher
+func (f *clientFetcher) FetchActivities(after, before time.Time) ([]models.Activity, error) {
+	return f.client.GetActivities(f.accessToken, client.ActivityQuery{After: after, Before: before})
+}
+
+// FetchActivityDetails implements syncer.DetailFetcher
+func (f *clientFetcher) FetchActivityDetails(id int64) (*models.Activity, error) {
+	return f.client.GetActivity(f.accessToken, id)
+}
+
+// FetchActivityStreams implements syncer.StreamFetcher
+func (f *clientFetcher) FetchActivityStreams(id int64) (*models.Streams, error) {
+	return f.client.GetActivityStreams(f.accessToken, id)
+}
+
+// runSync implements the "sync" command
+func runSync(args []string, profile string) {
+	fs := flag.NewFlagSet("sync", flag.ExitOnError)
+	full := fs.Bool("full", false, "Discard the sync watermark and re-fetch the whole history")
+	details := fs.Int("details", config.DetailsPerSync, "Maximum activities to fetch laps, splits and best efforts for")
+	streams := fs.Int("streams", config.StreamsPerSync, "Maximum activities to fetch heart rate, pace, power and GPS streams for")
+	fs.Parse(args)
+
+	cfg := config.LoadConfig(profile)
+	db := openStore(cfg)
+	defer db.Close()
+
+	if *full {
+		if err := db.SaveSyncState(models.SyncState{}); err != nil {
+			log.Fatalf("❌ Failed to reset sync state: %v", err)
+		}
+	}
+
+	result, stravaClient, err := syncActivities(cfg, db, *details, *streams)
+	if err != nil {
+		log.Fatalf("❌ Sync failed: %v", err)
+	}
+	logSyncResult(result, stravaClient)
+}
+
+// openStore opens the local activity database, seeding it from the legacy
+// JSON cache the first time
+func openStore(cfg *config.Config) *store.Store {
+	db, err := store.Open(cfg.DatabaseFile)
+	if err != nil {
+		log.Fatalf("❌ Failed to open activity database: %v", err)
+	}
+
+	// Only the default profile predates the database
+	if count, err := db.Count(); err == nil && count == 0 && cfg.Profile == config.DefaultProfile {
+		homeDir, _ := os.UserHomeDir()
+		legacy := filepath.Join(homeDir, ".strava-goals-cache", "activities.json")
+		if imported, err := db.ImportJSONCache(legacy); err != nil {
+			log.Printf("⚠️ Could not import legacy cache %s: %v", legacy, err)
+		} else if imported > 0 {
+			log.Printf("📦 Imported %d activities from legacy cache %s", imported, legacy)
+		}
+	}
+	return db
+}
+
+// syncActivities authenticates, runs one incremental sync into store and
+// fetches the details and streams of up to detailsLimit and streamsLimit
+// activities that lack them
+func syncActivities(cfg *config.Config, db *store.Store, detailsLimit, streamsLimit int) (*syncer.Result, *client.StravaClient, error) {
+	stravaClient := newStravaClient(cfg)
+	result, err := syncWith(stravaClient, db, detailsLimit, streamsLimit)
+	return result, stravaClient, err
+}
+
+// newStravaClient creates an API client for the profile. Its rate limiter
+// tracks the quota for as long as the client is kept.
+func newStravaClient(cfg *config.Config) *client.StravaClient {
+	stravaClient := client.NewStravaClient(cfg.ClientID, cfg.ClientSecret, cfg.RefreshToken)
+	stravaClient.SetTokenStore(auth.NewFileTokenStore(cfg.TokenFile))
+	return stravaClient
+}
+
+// syncWith runs syncActivities with an existing client, so long-running
+// commands keep one rate limiter across syncs
+func syncWith(stravaClient *client.StravaClient, db *store.Store, detailsLimit, streamsLimit int) (*syncer.Result, error) {
+	log.Println("📡 Authenticating with Strava API...")
+	accessToken, err := stravaClient.GetAccessToken()
+	if err != nil {
+		return nil, fmt.Errorf("authentication: %w", err)
+	}
+
+	log.Println("🔄 Syncing activities...")
+	fetcher := &clientFetcher{client: stravaClient, accessToken: accessToken}
+	engine := syncer.NewEngine(db, fetcher, config.SyncRecheckWindow, config.SyncRecheckInterval)
+	result, err := engine.Sync()
+	if err != nil {
+		var rateErr *client.RateLimitError
+		if errors.As(err, &rateErr) {
+			return nil, fmt.Errorf("strava API quota reached (%s), try again after %s",
+				stravaClient.RateLimitUsage(), rateErr.ResetAt.Local().Format("15:04"))
+		}
+		return nil, err
+	}
+
+	// Files imported before their Strava copy was synced would count twice
+	if result.Added > 0 {
+		if replaced, err := importer.RemoveReplacedImports(db); err != nil {
+			log.Printf("⚠️ Failed to remove imported activities now synced from Strava: %v", err)
+		} else if replaced > 0 {
+			log.Printf("🔁 Replaced %d imported activities with their Strava copies", replaced)
+		}
+	}
+
+	// Details are best effort: the rest are fetched on later syncs
+	if fetched, err := syncer.FetchDetails(db, fetcher, detailsLimit, time.Now()); err != nil {
+		log.Printf("⚠️ Fetched details of %d activities, the rest will follow on later syncs: %v", fetched, err)
+	} else if fetched > 0 {
+		log.Printf("🔍 Fetched laps, splits and best efforts of %d activities", fetched)
+	}
+	if fetched, err := syncer.FetchStreams(db, fetcher, streamsLimit, time.Now()); err != nil {
+		log.Printf("⚠️ Fetched streams of %d activities, the rest will follow on later syncs: %v", fetched, err)
+	} else if fetched > 0 {
+		log.Printf("📈 Fetched heart rate, pace and power streams of %d activities", fetched)
+	}
+
+	updateRecords(db)
+	return result, nil
+}
+
+// updateRecords recomputes personal records after a sync and announces new ones
+func updateRecords(db *store.Store) {
+	improved, err := records.Update(db, time.Now())
+	if err != nil {
+		log.Printf("⚠️ Could not update personal records: %v", err)
+		return
+	}
+	for _, record := range improved {
+		def, _ := records.Lookup(records.Kind(record.Kind))
+		log.Printf("🏆 New personal record! %s: %s (%s, %s)", def.Label, def.Format(record.Value),
+			record.ActivityName, record.SetAt.Local().Format("Jan 2, 2006"))
+	}
+}
+
+// logSyncResult reports what a sync changed
+func logSyncResult(result *syncer.Result, stravaClient *client.StravaClient) {
+	kind := "Incremental"
+	if result.Full {
+		kind = "Full"
+	} else if result.Rechecked {
+		kind = "Incremental + re-check"
+	}
+	log.Printf("✅ %s sync: %d new, %d updated, %d deleted", kind, result.Added, result.Updated, result.Deleted)
+	if !result.State.Watermark.IsZero() {
+		log.Printf("🕒 Newest activity: %s", result.State.Watermark.Local().Format("Jan 2, 2006 15:04"))
+	}
+	log.Printf("📶 API usage: %s", stravaClient.RateLimitUsage())
+}